@@ -0,0 +1,236 @@
+package envy
+
+import (
+	"io"
+	"sort"
+
+	"github.com/spf13/pflag"
+	"gopkg.in/yaml.v3"
+)
+
+// FlagPolicy is everything envy is told about one flag, as stored in an
+// annotations manifest by SaveAnnotations.
+type FlagPolicy struct {
+	// Custom variables, see SetEnvNames.
+	EnvNames []string `yaml:"env,omitempty"`
+
+	// Old variables mapped to the ones replacing them, see DeprecateEnvName.
+	Deprecated map[string]string `yaml:"deprecated,omitempty"`
+
+	Disable    bool   `yaml:"disable,omitempty"`
+	Required   bool   `yaml:"required,omitempty"`
+	Secret     bool   `yaml:"secret,omitempty"`
+	Hashed     bool   `yaml:"hashed,omitempty"`
+	FileSuffix bool   `yaml:"file_suffix,omitempty"`
+	SecretPath string `yaml:"secret_path,omitempty"`
+	Reloadable bool   `yaml:"reloadable,omitempty"`
+
+	// The group the flag is documented under, which also becomes part of its
+	// variable like it does for flags registered by Module.
+	Module string `yaml:"module,omitempty"`
+
+	// How a slice flag combines values, env-then-flags or flags-then-env, see
+	// MergeSlice.
+	Merge string `yaml:"merge,omitempty"`
+
+	// Documentation, see Doc.
+	Example string `yaml:"example,omitempty"`
+	Since   string `yaml:"since,omitempty"`
+	Link    string `yaml:"link,omitempty"`
+}
+
+// LoadAnnotations applies a manifest to the default pflag.CommandLine, see
+// LoadAnnotationsOnFlagSet.
+func LoadAnnotations(r io.Reader) error {
+	return LoadAnnotationsOnFlagSet(r, pflag.CommandLine)
+}
+
+// LoadAnnotationsOnFlagSet applies a YAML manifest mapping flag names to their
+// FlagPolicy, so a large CLI can keep its environment policy in one reviewed
+// file instead of calls scattered through the code:
+//
+//	url:
+//	  env: [MYAPP_URL, URL]
+//	  required: true
+//	token:
+//	  secret: true
+//	  file_suffix: true
+//	once:
+//	  disable: true
+//	redis-addr:
+//	  module: redis
+//	tags:
+//	  merge: env-then-flags
+//
+// Variables in the manifest replace any set by SetEnvNames, everything else
+// is added to what the code set. Unknown settings are an error, and a
+// ParseErrors names every flag in the manifest that doesn't exist or can't
+// take its settings. It must be called before the call to envy.Parse().
+func LoadAnnotationsOnFlagSet(r io.Reader, fs *pflag.FlagSet) error {
+	dec := yaml.NewDecoder(r)
+	dec.KnownFields(true)
+	manifest := map[string]FlagPolicy{}
+	if err := dec.Decode(&manifest); err != nil && err != io.EOF {
+		return err
+	}
+
+	names := make([]string, 0, len(manifest))
+	for name := range manifest {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var errs ParseErrors
+	for _, name := range names {
+		f := fs.Lookup(name)
+		if f == nil {
+			errs = append(errs, &SetError{Flag: name, Err: ErrFlagNotExists})
+			continue
+		}
+		if err := manifest[name].apply(f); err != nil {
+			errs = append(errs, &SetError{Flag: name, Err: err})
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// apply annotates the flag with the policy.
+func (p FlagPolicy) apply(f *pflag.Flag) error {
+	if len(p.EnvNames) > 0 {
+		names := make([]string, len(p.EnvNames))
+		for i, envName := range p.EnvNames {
+			names[i] = envKey(envName)
+		}
+		annotate(f, AnnotationCustom, names...)
+		delete(f.Annotations, AnnotationDeprecated)
+	}
+	olds := make([]string, 0, len(p.Deprecated))
+	for old := range p.Deprecated {
+		olds = append(olds, old)
+	}
+	sort.Strings(olds)
+	for _, old := range olds {
+		deprecateEnvName(f, old, p.Deprecated[old])
+	}
+
+	flags := []struct {
+		on  bool
+		key string
+	}{
+		{p.Disable, AnnotationDisable},
+		{p.Required, AnnotationRequired},
+		{p.Secret, AnnotationSecret},
+		{p.Hashed, AnnotationHash},
+		{p.FileSuffix, AnnotationFileSuffix},
+		{p.Reloadable, AnnotationReloadable},
+	}
+	for _, flag := range flags {
+		if flag.on {
+			annotate(f, flag.key, "true")
+		}
+	}
+	if p.SecretPath != "" {
+		annotate(f, AnnotationSecretPath, p.SecretPath)
+		annotate(f, AnnotationSecret, "true")
+	}
+
+	docs := []struct {
+		val string
+		key string
+	}{
+		{p.Example, AnnotationDocExample},
+		{p.Since, AnnotationDocSince},
+		{p.Link, AnnotationDocLink},
+		{p.Module, AnnotationModule},
+	}
+	for _, doc := range docs {
+		if doc.val != "" {
+			annotate(f, doc.key, doc.val)
+		}
+	}
+
+	if p.Merge == "" {
+		return nil
+	}
+	for mode, name := range mergeNames {
+		if name == p.Merge {
+			return setMerge(f, mode)
+		}
+	}
+	return ErrUnknownMerge
+}
+
+// SaveAnnotations writes the manifest for the default pflag.CommandLine, see
+// SaveAnnotationsFlagSet.
+func SaveAnnotations(w io.Writer) error {
+	return SaveAnnotationsFlagSet(w, pflag.CommandLine)
+}
+
+// SaveAnnotationsFlagSet writes the FlagPolicy of every flag in the given
+// FlagSet that has one as a YAML manifest for LoadAnnotations, which is a
+// starting point for moving policy set in code into a file.
+func SaveAnnotationsFlagSet(w io.Writer, fs *pflag.FlagSet) error {
+	manifest := map[string]FlagPolicy{}
+	visitAll(fs, func(f *pflag.Flag) {
+		if p := policyOf(f); !p.empty() {
+			manifest[f.Name] = p
+		}
+	})
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(manifest); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// policyOf reads the policy back from the flag's annotations.
+func policyOf(f *pflag.Flag) FlagPolicy {
+	_, disable := f.Annotations[AnnotationDisable]
+	_, required := f.Annotations[AnnotationRequired]
+	_, fileSuffix := f.Annotations[AnnotationFileSuffix]
+	p := FlagPolicy{
+		Disable:    disable,
+		Required:   required,
+		Secret:     isSecret(f),
+		Hashed:     isHashed(f),
+		FileSuffix: fileSuffix,
+		SecretPath: docOf(f, AnnotationSecretPath),
+		Reloadable: isReloadable(f),
+		Module:     docOf(f, AnnotationModule),
+		Merge:      mergeNames[mergeOf(f)],
+		Example:    docOf(f, AnnotationDocExample),
+		Since:      docOf(f, AnnotationDocSince),
+		Link:       docOf(f, AnnotationDocLink),
+	}
+
+	// Deprecated variables are listed separately rather than as variables.
+	pairs := f.Annotations[AnnotationDeprecated]
+	if len(pairs) > 0 {
+		p.Deprecated = map[string]string{}
+	}
+	for i := 0; i+1 < len(pairs); i += 2 {
+		p.Deprecated[pairs[i]] = pairs[i+1]
+	}
+	for _, name := range f.Annotations[AnnotationCustom] {
+		if _, ok := p.Deprecated[name]; !ok {
+			p.EnvNames = append(p.EnvNames, name)
+		}
+	}
+
+	// A secret path implies Secret, so it isn't repeated.
+	if p.SecretPath != "" {
+		p.Secret = false
+	}
+	return p
+}
+
+// empty reports whether the policy doesn't change anything.
+func (p FlagPolicy) empty() bool {
+	return len(p.EnvNames) == 0 && len(p.Deprecated) == 0 && !p.Disable && !p.Required && !p.Secret && !p.Hashed &&
+		!p.FileSuffix && p.SecretPath == "" && !p.Reloadable && p.Module == "" && p.Merge == "" && p.Example == "" &&
+		p.Since == "" && p.Link == ""
+}
@@ -0,0 +1,153 @@
+package envy_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/fernferret/envy"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+)
+
+const manifest = `kube-config:
+  env: [KUBECONFIG]
+once:
+  disable: true
+password:
+  secret_path: secret/data/app#password
+timeout:
+  env: [MYAPP_TIMEOUT]
+  deprecated:
+    OLD_TIMEOUT: MYAPP_TIMEOUT
+  required: true
+  example: 10m
+  since: v1.3
+token:
+  secret: true
+  file_suffix: true
+  hashed: true
+`
+
+func newAnnotatedFlagSet() *pflag.FlagSet {
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	fs.String("url", "http://localhost", "set the url")
+	fs.String("kube-config", "", "kube config")
+	fs.String("password", "", "password")
+	fs.String("token", "", "token")
+	fs.Duration("timeout", 0, "timeout")
+	fs.Bool("once", false, "only once")
+	return fs
+}
+
+func TestLoadAnnotations(t *testing.T) {
+	fs := newAnnotatedFlagSet()
+	assert.NoError(t, envy.LoadAnnotationsOnFlagSet(strings.NewReader(manifest), fs))
+
+	env := envy.MapLookuper{
+		"KUBECONFIG":               "/etc/kube",
+		"OLD_TIMEOUT":              "1m",
+		"MYAPP_ONCE":               "true",
+		"MYAPP_TOKEN_FILE":         writeFile(t, "token", "hunter2"),
+		"secret/data/app#password": "s3cret",
+	}
+	var deprecated []string
+	e := envy.New(envy.WithFlagSet(fs), envy.WithPrefix("MYAPP"), envy.WithLookuper(env),
+		envy.WithDeprecationHandler(func(flagName, oldName, newName string) {
+			deprecated = append(deprecated, oldName)
+		}))
+	assert.NoError(t, e.ParseE())
+
+	assert.Equal(t, "/etc/kube", fs.Lookup("kube-config").Value.String())
+	assert.Equal(t, "1m0s", fs.Lookup("timeout").Value.String())
+	assert.Equal(t, []string{"OLD_TIMEOUT"}, deprecated)
+	assert.Equal(t, "false", fs.Lookup("once").Value.String())
+	assert.Equal(t, "hunter2", fs.Lookup("token").Value.String())
+	assert.Equal(t, "s3cret", fs.Lookup("password").Value.String())
+	assert.NoError(t, envy.FinalizeFlagSet(fs))
+
+	schema := envy.SchemaFlagSet(fs)
+	assert.True(t, schema[2].Secret)
+	assert.Equal(t, "10m", schema[3].Example)
+	assert.Equal(t, "v1.3", schema[3].Since)
+}
+
+func TestSaveAnnotations(t *testing.T) {
+	fs := newAnnotatedFlagSet()
+	assert.NoError(t, envy.LoadAnnotationsOnFlagSet(strings.NewReader(manifest), fs))
+	buf := &bytes.Buffer{}
+	assert.NoError(t, envy.SaveAnnotationsFlagSet(buf, fs))
+
+	// Saving what was loaded gives the same manifest back, in block style.
+	roundTrip := newAnnotatedFlagSet()
+	assert.NoError(t, envy.LoadAnnotationsOnFlagSet(bytes.NewReader(buf.Bytes()), roundTrip))
+	again := &bytes.Buffer{}
+	assert.NoError(t, envy.SaveAnnotationsFlagSet(again, roundTrip))
+	assert.Equal(t, buf.String(), again.String())
+	assert.Contains(t, buf.String(), "kube-config:\n  env:\n    - KUBECONFIG\n")
+	assert.NotContains(t, buf.String(), "url")
+
+	// Policy set in code is saved too.
+	fs = newAnnotatedFlagSet()
+	envy.SecretOnFlagSet("token", fs)
+	envy.DeprecateEnvNameOnFlagSet("timeout", "OLD_TIMEOUT", "MYAPP_TIMEOUT", fs)
+	buf.Reset()
+	assert.NoError(t, envy.SaveAnnotationsFlagSet(buf, fs))
+	assert.Equal(t, `timeout:
+  env:
+    - MYAPP_TIMEOUT
+  deprecated:
+    OLD_TIMEOUT: MYAPP_TIMEOUT
+token:
+  secret: true
+`, buf.String())
+}
+
+func TestLoadAnnotationsErrors(t *testing.T) {
+	fs := newAnnotatedFlagSet()
+	err := envy.LoadAnnotationsOnFlagSet(strings.NewReader("url:\n  group: web\n"), fs)
+	assert.ErrorContains(t, err, "field group not found")
+
+	err = envy.LoadAnnotationsOnFlagSet(strings.NewReader("missing:\n  secret: true\nother:\n  secret: true\nurl:\n  secret: true\n"), fs)
+	assert.ErrorIs(t, err, envy.ErrFlagNotExists)
+	assert.EqualError(t, err, "--missing: flag does not exist\n--other: flag does not exist")
+
+	assert.NoError(t, envy.LoadAnnotationsOnFlagSet(strings.NewReader(""), fs))
+}
+
+func TestAnnotationsModuleMergeReloadable(t *testing.T) {
+	const manifest = `addr:
+  module: redis
+level:
+  reloadable: true
+tags:
+  merge: env-then-flags
+`
+	newFlagSet := func() *pflag.FlagSet {
+		fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+		fs.String("addr", "", "redis address")
+		fs.String("level", "info", "log level")
+		fs.StringSlice("tags", nil, "tags")
+		return fs
+	}
+	fs := newFlagSet()
+	assert.NoError(t, envy.LoadAnnotationsOnFlagSet(strings.NewReader(manifest), fs))
+
+	env := envy.MapLookuper{"MYAPP_REDIS_ADDR": "localhost:6379", "MYAPP_TAGS": "a"}
+	assert.NoError(t, envy.New(envy.WithFlagSet(fs), envy.WithPrefix("MYAPP"), envy.WithLookuper(env)).ParseE())
+	assert.NoError(t, fs.Parse([]string{"--tags", "b"}))
+	assert.Equal(t, "localhost:6379", fs.Lookup("addr").Value.String())
+	assert.Equal(t, "[a,b]", fs.Lookup("tags").Value.String())
+
+	schema := envy.SchemaFlagSet(fs)
+	assert.Equal(t, "redis", schema[0].Module)
+	assert.True(t, schema[1].Reloadable)
+
+	// Saving gives the same manifest back.
+	buf := &bytes.Buffer{}
+	assert.NoError(t, envy.SaveAnnotationsFlagSet(buf, fs))
+	assert.Equal(t, manifest, buf.String())
+
+	err := envy.LoadAnnotationsOnFlagSet(strings.NewReader("level:\n  merge: env-then-flags\ntags:\n  merge: sideways\n"), newFlagSet())
+	assert.EqualError(t, err, "--level: "+envy.ErrNotSlice.Error()+"\n--tags: "+envy.ErrUnknownMerge.Error())
+}
@@ -0,0 +1,36 @@
+package envy
+
+import "github.com/spf13/pflag"
+
+// SetAtomic makes Parse all or nothing: if any flag rejects its value, every
+// flag is put back to how it was before Parse, including the ones that were
+// set fine, and all the failures are reported. By default Parse sets every
+// flag it can, so a panic or error can leave the FlagSet half configured. It
+// must be called before the call to envy.Parse().
+func SetAtomic(on bool) {
+	std.atomic = on
+}
+
+// WithAtomic works like SetAtomic for this Envy only.
+func WithAtomic(on bool) Option {
+	return func(e *Envy) {
+		e.atomic = on
+	}
+}
+
+// checkpoint returns a function putting everything bind can change about the
+// flag back.
+func checkpoint(f *pflag.Flag) func() {
+	val, def, usage := f.Value, f.DefValue, f.Usage
+	annotations := make(map[string][]string, len(f.Annotations))
+	for k, v := range f.Annotations {
+		annotations[k] = append([]string(nil), v...)
+	}
+	restore := snapshot(f)
+	return func() {
+		f.Value = val
+		restore()
+		f.DefValue, f.Usage = def, usage
+		f.Annotations = annotations
+	}
+}
@@ -0,0 +1,56 @@
+package envy_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/fernferret/envy"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAtomic(t *testing.T) {
+	tests := []struct {
+		name   string
+		atomic bool
+		url    string
+		tags   []string
+	}{
+		{"partial", false, "http://example.com", []string{"a", "b"}},
+		{"atomic", true, "http://localhost", []string{"x"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+			url := fs.String("url", "http://localhost", "set the url")
+			tags := fs.StringSlice("tags", []string{"x"}, "tags")
+			fs.Int("workers", 4, "number of workers")
+			fs.Bool("verbose", false, "be verbose")
+			env := envy.MapLookuper{"FOO_URL": "http://example.com", "FOO_TAGS": "a,b", "FOO_WORKERS": "many", "FOO_VERBOSE": "sure"}
+			e := envy.New(envy.WithFlagSet(fs), envy.WithPrefix("FOO"), envy.WithLookuper(env), envy.WithAtomic(tt.atomic))
+
+			var errs envy.ParseErrors
+			if !errors.As(e.ParseE(), &errs) {
+				t.Fatal("expected ParseErrors")
+			}
+			assert.Len(t, errs, 2)
+			assert.Equal(t, tt.url, *url)
+			assert.Equal(t, tt.tags, *tags)
+			if tt.atomic {
+				assert.Equal(t, envy.FromDefault, envy.SourcesFlagSet(fs)[1].Origin)
+				assert.Equal(t, "set the url", fs.Lookup("url").Usage)
+			}
+		})
+	}
+}
+
+func TestAtomicPanic(t *testing.T) {
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	url := fs.String("url", "http://localhost", "set the url")
+	fs.Int("workers", 4, "number of workers")
+	env := envy.MapLookuper{"FOO_URL": "http://example.com", "FOO_WORKERS": "many"}
+	e := envy.New(envy.WithFlagSet(fs), envy.WithPrefix("FOO"), envy.WithLookuper(env), envy.WithAtomic(true))
+
+	assert.Panics(t, e.Parse)
+	assert.Equal(t, "http://localhost", *url)
+}
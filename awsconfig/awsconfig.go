@@ -0,0 +1,137 @@
+// Package awsconfig reads envy flags from AWS Systems Manager Parameter Store
+// and Secrets Manager. To keep the AWS SDK out of envy's dependencies, it
+// talks to AWS through small interfaces that a few lines of SDK code satisfy:
+//
+//	type ssmClient struct{ *ssm.Client }
+//
+//	func (c ssmClient) GetParametersByPath(ctx context.Context, path string) (map[string]string, error) {
+//		params := map[string]string{}
+//		p := ssm.NewGetParametersByPathPaginator(c.Client, &ssm.GetParametersByPathInput{
+//			Path: &path, Recursive: aws.Bool(true), WithDecryption: aws.Bool(true),
+//		})
+//		for p.HasMorePages() {
+//			page, err := p.NextPage(ctx)
+//			if err != nil {
+//				return nil, err
+//			}
+//			for _, param := range page.Parameters {
+//				params[*param.Name] = *param.Value
+//			}
+//		}
+//		return params, nil
+//	}
+//
+// Then chain the Lookupers behind the environment:
+//
+//	params := awsconfig.SSM(ssmClient{ssm.NewFromConfig(cfg)}, "/myapp/prod/")
+//	envy.SetLookuper(envy.Chain{envy.EnvLookuper{}, params})
+//	envy.Parse("MYAPP")
+//	if err := params.Err(); err != nil {
+//		log.Fatal(err)
+//	}
+package awsconfig
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SSMClient is the part of Parameter Store the SSM Lookuper needs.
+type SSMClient interface {
+	// GetParametersByPath returns the decrypted value of every parameter
+	// under the path, recursively, keyed by full name.
+	GetParametersByPath(ctx context.Context, path string) (map[string]string, error)
+}
+
+// SecretsManagerClient is the part of Secrets Manager the SecretsManager
+// Lookuper needs.
+type SecretsManagerClient interface {
+	// GetSecretString returns the SecretString of the current version.
+	GetSecretString(ctx context.Context, secretID string) (string, error)
+}
+
+// Timeout bounds how long a Lookuper waits for AWS on its first Lookup.
+var Timeout = 10 * time.Second
+
+// Lookuper is an envy.Lookuper over values loaded from AWS on the first
+// Lookup.
+type Lookuper struct {
+	load func(ctx context.Context) (map[string]string, error)
+
+	once   sync.Once
+	values map[string]string
+	err    error
+}
+
+// SSM returns a Lookuper for every parameter under the path prefix. Names
+// are taken relative to the prefix, uppercased, with slashes and dashes
+// turned into underscores, so under /myapp/prod/ the parameter
+// /myapp/prod/MYAPP/db-url provides MYAPP_DB_URL.
+func SSM(client SSMClient, prefix string) *Lookuper {
+	return &Lookuper{load: func(ctx context.Context) (map[string]string, error) {
+		params, err := client.GetParametersByPath(ctx, prefix)
+		if err != nil {
+			return nil, fmt.Errorf("ssm %s: %w", prefix, err)
+		}
+		values := map[string]string{}
+		for name, val := range params {
+			values[paramKey(prefix, name)] = val
+		}
+		return values, nil
+	}}
+}
+
+// SecretsManager returns a Lookuper for the fields of a secret holding a JSON
+// object, like {"MYAPP_DB_PASSWORD": "hunter2"}. Fields that aren't strings
+// are returned as JSON.
+func SecretsManager(client SecretsManagerClient, secretID string) *Lookuper {
+	return &Lookuper{load: func(ctx context.Context) (map[string]string, error) {
+		secret, err := client.GetSecretString(ctx, secretID)
+		if err != nil {
+			return nil, fmt.Errorf("secretsmanager %s: %w", secretID, err)
+		}
+		fields := map[string]json.RawMessage{}
+		if err := json.Unmarshal([]byte(secret), &fields); err != nil {
+			return nil, fmt.Errorf("secretsmanager %s: %w", secretID, err)
+		}
+		values := map[string]string{}
+		for key, raw := range fields {
+			var s string
+			switch {
+			case string(raw) == "null":
+			case json.Unmarshal(raw, &s) == nil:
+				values[key] = s
+			default:
+				values[key] = string(raw)
+			}
+		}
+		return values, nil
+	}}
+}
+
+func (l *Lookuper) Lookup(key string) (string, bool) {
+	l.once.Do(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), Timeout)
+		defer cancel()
+		l.values, l.err = l.load(ctx)
+	})
+	val, ok := l.values[key]
+	return val, ok
+}
+
+// Err returns the error loading the values, if any. Since envy treats a
+// failed lookup as unset, check it after envy.Parse() to avoid silently
+// running with defaults.
+func (l *Lookuper) Err() error {
+	return l.err
+}
+
+// paramKey turns a parameter name into the variable it provides.
+func paramKey(prefix, name string) string {
+	key := strings.TrimPrefix(strings.TrimPrefix(name, prefix), "/")
+	return strings.ToUpper(strings.NewReplacer("/", "_", "-", "_").Replace(key))
+}
@@ -0,0 +1,97 @@
+package awsconfig_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/fernferret/envy"
+	"github.com/fernferret/envy/awsconfig"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeSSM struct {
+	params map[string]string
+	calls  int
+	err    error
+}
+
+func (f *fakeSSM) GetParametersByPath(ctx context.Context, path string) (map[string]string, error) {
+	f.calls++
+	return f.params, f.err
+}
+
+type fakeSecrets map[string]string
+
+func (f fakeSecrets) GetSecretString(ctx context.Context, secretID string) (string, error) {
+	secret, ok := f[secretID]
+	if !ok {
+		return "", errors.New("ResourceNotFoundException")
+	}
+	return secret, nil
+}
+
+func TestSSM(t *testing.T) {
+	client := &fakeSSM{params: map[string]string{
+		"/myapp/prod/MYAPP_URL":     "http://from-ssm",
+		"/myapp/prod/myapp/workers": "3",
+		"/myapp/prod/MYAPP/db-host": "db.internal",
+	}}
+	params := awsconfig.SSM(client, "/myapp/prod/")
+
+	os.Clearenv()
+	os.Setenv("MYAPP_WORKERS", "5")
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	url := fs.String("url", "", "set the url")
+	workers := fs.Int("workers", 1, "number of workers")
+	host := fs.String("db-host", "", "database host")
+	e := envy.New(envy.WithPrefix("MYAPP"), envy.WithFlagSet(fs), envy.WithLookuper(envy.Chain{envy.EnvLookuper{}, params}))
+	assert.NoError(t, e.ParseE())
+	assert.NoError(t, params.Err())
+
+	assert.Equal(t, "http://from-ssm", *url)
+	assert.Equal(t, 5, *workers)
+	assert.Equal(t, "db.internal", *host)
+	assert.Equal(t, 1, client.calls)
+}
+
+func TestSecretsManager(t *testing.T) {
+	client := fakeSecrets{
+		"myapp": `{"MYAPP_DB_PASSWORD": "hunter2", "MYAPP_WORKERS": 3, "MYAPP_TAGS": ["a"], "MYAPP_NONE": null}`,
+		"bad":   "hunter2",
+	}
+	secrets := awsconfig.SecretsManager(client, "myapp")
+	tests := []struct {
+		key  string
+		want string
+		ok   bool
+	}{
+		{"MYAPP_DB_PASSWORD", "hunter2", true},
+		{"MYAPP_WORKERS", "3", true},
+		{"MYAPP_TAGS", `["a"]`, true},
+		{"MYAPP_NONE", "", false},
+		{"MYAPP_MISSING", "", false},
+	}
+	for _, tt := range tests {
+		val, ok := secrets.Lookup(tt.key)
+		assert.Equal(t, tt.want, val, tt.key)
+		assert.Equal(t, tt.ok, ok, tt.key)
+	}
+	assert.NoError(t, secrets.Err())
+
+	for _, id := range []string{"bad", "missing"} {
+		secrets := awsconfig.SecretsManager(client, id)
+		_, ok := secrets.Lookup("MYAPP_DB_PASSWORD")
+		assert.False(t, ok)
+		assert.ErrorContains(t, secrets.Err(), "secretsmanager "+id+": ")
+	}
+}
+
+func TestSSMError(t *testing.T) {
+	params := awsconfig.SSM(&fakeSSM{err: errors.New("AccessDeniedException")}, "/myapp/")
+	_, ok := params.Lookup("MYAPP_URL")
+	assert.False(t, ok)
+	assert.EqualError(t, params.Err(), "ssm /myapp/: AccessDeniedException")
+}
@@ -0,0 +1,132 @@
+package envy
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/spf13/pflag"
+)
+
+// Drift is a single difference between a baseline and the current flags.
+type Drift struct {
+	Flag     string
+	Baseline string
+	Current  string
+
+	// Set when the flag only exists on one side.
+	Added   bool
+	Removed bool
+}
+
+func (d Drift) String() string {
+	switch {
+	case d.Added:
+		return fmt.Sprintf("--%s is new, set to %q", d.Flag, d.Current)
+	case d.Removed:
+		return fmt.Sprintf("--%s no longer exists, was %q", d.Flag, d.Baseline)
+	}
+	return fmt.Sprintf("--%s changed from %q to %q", d.Flag, d.Baseline, d.Current)
+}
+
+// SetSecretKey sets the key secrets are recorded with by WriteBaseline and
+// Fingerprint, as an HMAC-SHA256 of their value so a rotated secret shows up as
+// drift. Without a key they're only recorded as set or unset, since a plain
+// hash of a short secret is easily brute forced. Replicas compared with
+// CheckReplicas must share the key. It must be called before the call to
+// envy.Parse().
+func SetSecretKey(key []byte) {
+	std.secretKey = key
+}
+
+// WriteBaseline writes the current value of every flag in the default
+// pflag.CommandLine as JSON, see WriteBaselineFlagSet.
+func WriteBaseline(w io.Writer) error {
+	return WriteBaselineFlagSet(w, pflag.CommandLine)
+}
+
+// WriteBaselineFlagSet writes the current value of every flag in the given
+// FlagSet as a JSON object, for later use with CompareBaseline. Secrets are
+// only recorded as set or unset, see SetSecretKey.
+func WriteBaselineFlagSet(w io.Writer, fs *pflag.FlagSet) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(dump(fs, instanceFor(fs).secretKey))
+}
+
+// CompareBaseline compares the default pflag.CommandLine against a baseline,
+// see CompareBaselineFlagSet.
+func CompareBaseline(path string) ([]Drift, error) {
+	return CompareBaselineFlagSet(path, pflag.CommandLine)
+}
+
+// CompareBaselineFlagSet loads a baseline written by WriteBaseline and returns
+// how the given FlagSet differs from it, sorted by flag name. Each difference
+// is also logged to the output from SetOutput so unintended drift shows up in
+// the service logs. It must be called after pflag.Parse().
+func CompareBaselineFlagSet(path string, fs *pflag.FlagSet) ([]Drift, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	baseline := map[string]string{}
+	if err := json.Unmarshal(data, &baseline); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	drifts := diff(baseline, dump(fs, instanceFor(fs).secretKey))
+	for _, d := range drifts {
+		logf("%s", d)
+	}
+	return drifts, nil
+}
+
+// diff compares two sets of values by flag name, sorted by flag name.
+func diff(baseline, current map[string]string) []Drift {
+	var drifts []Drift
+	for name, val := range current {
+		base, ok := baseline[name]
+		switch {
+		case !ok:
+			drifts = append(drifts, Drift{Flag: name, Current: val, Added: true})
+		case base != val:
+			drifts = append(drifts, Drift{Flag: name, Baseline: base, Current: val})
+		}
+	}
+	for name, base := range baseline {
+		if _, ok := current[name]; !ok {
+			drifts = append(drifts, Drift{Flag: name, Baseline: base, Removed: true})
+		}
+	}
+	sort.Slice(drifts, func(i, j int) bool {
+		return drifts[i].Flag < drifts[j].Flag
+	})
+	return drifts
+}
+
+// dump returns the value of every flag, see dumpValue.
+func dump(fs *pflag.FlagSet, key []byte) map[string]string {
+	values := map[string]string{}
+	visitAll(fs, func(f *pflag.Flag) {
+		values[f.Name] = dumpValue(f, key)
+	})
+	return values
+}
+
+// dumpValue returns the value of the flag that is safe to write to disk.
+// Secrets are an HMAC under the key, or only set or unset without one.
+func dumpValue(f *pflag.Flag, key []byte) string {
+	if !isSecret(f) {
+		return displayValue(f)
+	}
+	val := f.Value.String()
+	switch {
+	case key != nil:
+		return hmacValue(key, val)
+	case val == "":
+		return "unset"
+	}
+	return "set"
+}
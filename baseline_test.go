@@ -0,0 +1,79 @@
+package envy_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/fernferret/envy"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompareBaseline(t *testing.T) {
+	envy.SetSecretKey([]byte("baseline-key"))
+	defer envy.SetSecretKey(nil)
+	path := filepath.Join(t.TempDir(), "baseline.json")
+
+	os.Clearenv()
+	os.Setenv("FOO_TOKEN", "hunter2")
+	pflag.CommandLine = pflag.NewFlagSet("test", pflag.PanicOnError)
+	pflag.String("url", "http://localhost", "set the url")
+	pflag.String("token", "", "api token")
+	pflag.Bool("once", false, "only once")
+	envy.Secret("token")
+	envy.Parse("FOO")
+
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.NoError(t, envy.WriteBaseline(f))
+	f.Close()
+
+	data, _ := os.ReadFile(path)
+	assert.NotContains(t, string(data), "hunter2")
+	assert.Contains(t, string(data), `"token": "hmac-sha256:`)
+
+	// Simulate the next deployment
+	os.Setenv("FOO_TOKEN", "hunter3")
+	os.Setenv("FOO_URL", "https://example.com")
+	pflag.CommandLine = pflag.NewFlagSet("test", pflag.PanicOnError)
+	pflag.String("url", "http://localhost", "set the url")
+	pflag.String("token", "", "api token")
+	pflag.Int("count", 1, "a count")
+	envy.Secret("token")
+	envy.Parse("FOO")
+
+	drifts, err := envy.CompareBaseline(path)
+	assert.NoError(t, err)
+	if assert.Len(t, drifts, 4) {
+		assert.Equal(t, envy.Drift{Flag: "count", Current: "1", Added: true}, drifts[0])
+		assert.Equal(t, envy.Drift{Flag: "once", Baseline: "false", Removed: true}, drifts[1])
+		assert.Equal(t, "token", drifts[2].Flag)
+		assert.Equal(t, `--url changed from "http://localhost" to "https://example.com"`, drifts[3].String())
+	}
+}
+
+func TestCompareBaselineMissing(t *testing.T) {
+	pflag.CommandLine = pflag.NewFlagSet("test", pflag.PanicOnError)
+
+	_, err := envy.CompareBaseline(filepath.Join(t.TempDir(), "missing.json"))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestWriteBaselineSecretsWithoutKey(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("FOO_TOKEN", "hunter2")
+	pflag.CommandLine = pflag.NewFlagSet("test", pflag.PanicOnError)
+	pflag.String("token", "", "api token")
+	pflag.String("password", "", "db password")
+	envy.Secret("token")
+	envy.Secret("password")
+	envy.Parse("FOO")
+
+	buf := &bytes.Buffer{}
+	assert.NoError(t, envy.WriteBaseline(buf))
+	assert.JSONEq(t, `{"token": "set", "password": "unset"}`, buf.String())
+}
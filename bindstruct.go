@@ -0,0 +1,226 @@
+package envy
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/spf13/pflag"
+)
+
+var (
+	ErrNotStructPointer = errors.New("expected a non-nil pointer to a struct")
+	ErrUnsupportedType  = errors.New("field type is not supported")
+)
+
+// Bind defines a flag on the default pflag.CommandLine for every exported
+// field of the struct p points to, see BindOnFlagSet.
+func Bind(p interface{}) error {
+	return BindOnFlagSet(p, pflag.CommandLine)
+}
+
+// BindOnFlagSet defines a flag on the given FlagSet for every exported field
+// of the struct p points to, so options are declared once instead of as a
+// struct and a matching list of flags:
+//
+//	type options struct {
+//		URL     string        `usage:"set the url" default:"http://127.0.0.1:8080"`
+//		Timeout time.Duration `flag:"timeout" env:"HTTP_TIMEOUT" usage:"request timeout"`
+//		Once    bool          `env:"-" usage:"perform the thing once and exit"`
+//	}
+//
+// The flag tag names the flag, which defaults to the field name in kebab case
+// and is skipped entirely with "-". The env tag works like SetEnvNames with a
+// comma separated list, or Disable with "-". The default tag is parsed like a
+// value from the command line, otherwise the field's current value is the
+// default. Fields can be strings, bools, numbers, durations, string and int
+// slices, anything implementing pflag.Value, or encoding.TextUnmarshaler like
+// netip.Addr. Like the functions it stands in for, it must be called before
+// the call to envy.Parse().
+//
+// Nested structs, or pointers to them, group their flags under the field's
+// name, so Server.Port becomes --server-port, read from APP_SERVER_PORT. The
+// prefix tag replaces the group's name, or removes it when empty, and
+// embedded structs aren't grouped at all:
+//
+//	type options struct {
+//		Server   serverOptions
+//		Upstream serverOptions `prefix:"backend"`
+//	}
+func BindOnFlagSet(p interface{}, fs *pflag.FlagSet) error {
+	v := reflect.ValueOf(p)
+	if v.Kind() != reflect.Pointer || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return ErrNotStructPointer
+	}
+	if errs := std.on("", fs).bindStruct(v.Elem(), ""); len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// bindStruct defines a flag for every exported field of the struct, with
+// each name starting with the group.
+func (e *Envy) bindStruct(v reflect.Value, group string) ParseErrors {
+	var errs ParseErrors
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, ok := field.Tag.Lookup("flag")
+		if name == "-" {
+			continue
+		}
+		// Exported fields of embedded structs are promoted even if the
+		// struct's type isn't exported.
+		if !field.IsExported() && !(field.Anonymous && field.Type.Kind() == reflect.Struct) {
+			continue
+		}
+		if !ok {
+			name = kebabCase(field.Name)
+		}
+		if nested, ok := nestedStruct(v.Field(i)); ok {
+			errs = append(errs, e.bindStruct(nested, groupOf(group, name, field))...)
+			continue
+		}
+		if err := e.bindField(v.Field(i).Addr().Interface(), group+name, field.Tag); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+// nestedStruct returns the struct a field holds, or points to, allocating it
+// if needed. Structs implementing pflag.Value or encoding.TextUnmarshaler are
+// flags of their own.
+func nestedStruct(v reflect.Value) (reflect.Value, bool) {
+	if !v.CanInterface() {
+		return v, true
+	}
+	if isValue(v.Addr().Interface()) {
+		return v, false
+	}
+	if v.Kind() == reflect.Pointer && v.Type().Elem().Kind() == reflect.Struct {
+		if isValue(v.Interface()) {
+			return v, false
+		}
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		v = v.Elem()
+	}
+	return v, v.Kind() == reflect.Struct
+}
+
+// isValue reports whether p can be used as a flag's value as is.
+func isValue(p interface{}) bool {
+	switch p.(type) {
+	case pflag.Value, encoding.TextUnmarshaler:
+		return true
+	}
+	return false
+}
+
+// groupOf returns the group for the flags of a nested struct.
+func groupOf(group, name string, field reflect.StructField) string {
+	pfx, ok := field.Tag.Lookup("prefix")
+	switch {
+	case ok:
+		name = pfx
+	case field.Anonymous:
+		name = ""
+	}
+	if name == "" {
+		return group
+	}
+	return group + name + "-"
+}
+
+// bindField defines the flag for a single field and applies its tags.
+func (e *Envy) bindField(p interface{}, name string, tag reflect.StructTag) *SetError {
+	usage := tag.Get("usage")
+	if def, ok := tag.Lookup("default"); ok {
+		// Parse the default through a scratch flag so the real one starts out
+		// unchanged, otherwise slices would append to their default.
+		scratch := pflag.NewFlagSet("", pflag.ContinueOnError)
+		scratch.SetOutput(io.Discard)
+		if defineField(scratch, p, name, usage) {
+			if err := scratch.Lookup(name).Value.Set(def); err != nil {
+				return &SetError{Flag: name, Err: fmt.Errorf("default %q: %w", def, err)}
+			}
+		}
+	}
+	if !defineField(e.fs, p, name, usage) {
+		return &SetError{Flag: name, Err: fmt.Errorf("%w: %s", ErrUnsupportedType, reflect.TypeOf(p).Elem())}
+	}
+
+	switch env, ok := tag.Lookup("env"); {
+	case !ok:
+	case env == "-":
+		e.Disable(name)
+	default:
+		e.SetEnvNames(name, strings.Split(env, ",")...)
+	}
+	return nil
+}
+
+// defineField defines a flag backed by the field p points to, using its
+// current value as the default. It reports false for unsupported types.
+func defineField(fs *pflag.FlagSet, p interface{}, name, usage string) bool {
+	switch p := p.(type) {
+	case pflag.Value:
+		fs.Var(p, name, usage)
+	case *string:
+		fs.StringVar(p, name, *p, usage)
+	case *bool:
+		fs.BoolVar(p, name, *p, usage)
+	case *int:
+		fs.IntVar(p, name, *p, usage)
+	case *int32:
+		fs.Int32Var(p, name, *p, usage)
+	case *int64:
+		fs.Int64Var(p, name, *p, usage)
+	case *uint:
+		fs.UintVar(p, name, *p, usage)
+	case *uint32:
+		fs.Uint32Var(p, name, *p, usage)
+	case *uint64:
+		fs.Uint64Var(p, name, *p, usage)
+	case *float32:
+		fs.Float32Var(p, name, *p, usage)
+	case *float64:
+		fs.Float64Var(p, name, *p, usage)
+	case *time.Duration:
+		fs.DurationVar(p, name, *p, usage)
+	case *[]string:
+		fs.StringSliceVar(p, name, *p, usage)
+	case *[]int:
+		fs.IntSliceVar(p, name, *p, usage)
+	case encoding.TextUnmarshaler:
+		fs.Var(&textValue{p: p}, name, usage)
+	default:
+		return false
+	}
+	return true
+}
+
+// kebabCase turns a Go field name into a flag name, keeping acronyms
+// together, so HTTPPort becomes http-port and CountFancy count-fancy.
+func kebabCase(name string) string {
+	runes := []rune(name)
+	b := &strings.Builder{}
+	for i, r := range runes {
+		if i > 0 && unicode.IsUpper(r) {
+			prevLower := !unicode.IsUpper(runes[i-1])
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if prevLower || nextLower {
+				b.WriteByte('-')
+			}
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return b.String()
+}
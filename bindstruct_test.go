@@ -0,0 +1,154 @@
+package envy_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fernferret/envy"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+)
+
+type bindOptions struct {
+	URL        string        `default:"http://localhost" usage:"set the url"`
+	HTTPPort   int           `usage:"port to listen on"`
+	Timeout    time.Duration `flag:"request-timeout" env:"HTTP_TIMEOUT,TIMEOUT" default:"5s" usage:"request timeout"`
+	Once       bool          `env:"-" usage:"only once"`
+	Tags       []string      `default:"a,b" usage:"tags"`
+	Ratio      float64       `usage:"ratio"`
+	Ignored    string        `flag:"-"`
+	CountFancy uint64        `default:"7" usage:"a fancy count"`
+
+	unexported string
+}
+
+func TestBind(t *testing.T) {
+	env := envy.MapLookuper{
+		"FOO_URL":         "http://example.com",
+		"FOO_HTTP_PORT":   "9090",
+		"TIMEOUT":         "1m",
+		"FOO_ONCE":        "true",
+		"FOO_RATIO":       "0.5",
+		"FOO_COUNT_FANCY": "8",
+	}
+	opts := bindOptions{HTTPPort: 8080}
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	assert.NoError(t, envy.BindOnFlagSet(&opts, fs))
+
+	var names []string
+	fs.VisitAll(func(f *pflag.Flag) {
+		names = append(names, f.Name)
+	})
+	assert.Equal(t, []string{"count-fancy", "http-port", "once", "ratio", "request-timeout", "tags", "url"}, names)
+	assert.Equal(t, "http://localhost", fs.Lookup("url").DefValue)
+	assert.Equal(t, "8080", fs.Lookup("http-port").DefValue)
+	assert.Equal(t, "5s", fs.Lookup("request-timeout").DefValue)
+	assert.Equal(t, "[a,b]", fs.Lookup("tags").DefValue)
+
+	assert.NoError(t, envy.New(envy.WithFlagSet(fs), envy.WithPrefix("FOO"), envy.WithLookuper(env)).ParseE())
+	assert.NoError(t, fs.Parse([]string{"--tags=d"}))
+	assert.Equal(t, "http://example.com", opts.URL)
+	assert.Equal(t, 9090, opts.HTTPPort)
+	assert.Equal(t, time.Minute, opts.Timeout)
+	assert.False(t, opts.Once)
+	assert.Equal(t, 0.5, opts.Ratio)
+	assert.Equal(t, []string{"d"}, opts.Tags)
+	assert.Equal(t, uint64(8), opts.CountFancy)
+	assert.Equal(t, "request timeout [TIMEOUT 1m0s]", fs.Lookup("request-timeout").Usage)
+}
+
+type upperValue string
+
+func (v *upperValue) Set(val string) error {
+	*v = upperValue(strings.ToUpper(val))
+	return nil
+}
+
+func (v *upperValue) String() string {
+	return string(*v)
+}
+
+func (v *upperValue) Type() string {
+	return "upper"
+}
+
+func TestBindValue(t *testing.T) {
+	var opts struct {
+		Region upperValue `default:"us-east-1" usage:"region"`
+	}
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	assert.NoError(t, envy.BindOnFlagSet(&opts, fs))
+	assert.Equal(t, "US-EAST-1", fs.Lookup("region").DefValue)
+	assert.NoError(t, fs.Parse([]string{"--region=eu-west-1"}))
+	assert.Equal(t, upperValue("EU-WEST-1"), opts.Region)
+}
+
+func TestBindErrors(t *testing.T) {
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	var s string
+	assert.ErrorIs(t, envy.BindOnFlagSet(s, fs), envy.ErrNotStructPointer)
+	assert.ErrorIs(t, envy.BindOnFlagSet(&s, fs), envy.ErrNotStructPointer)
+	assert.ErrorIs(t, envy.BindOnFlagSet((*bindOptions)(nil), fs), envy.ErrNotStructPointer)
+
+	var unsupported struct {
+		Labels map[string]string
+		Port   int `default:"http"`
+	}
+	err := envy.BindOnFlagSet(&unsupported, fs)
+	assert.ErrorIs(t, err, envy.ErrUnsupportedType)
+	assert.EqualError(t, err, "--labels: field type is not supported: map[string]string\n"+
+		`--port: default "http": strconv.ParseInt: parsing "http": invalid syntax`)
+}
+
+type serverOptions struct {
+	Host string `default:"localhost" usage:"host to listen on"`
+	Port int    `default:"8080" usage:"port to listen on"`
+	TLS  *tlsOptions
+}
+
+type tlsOptions struct {
+	CertFile string `usage:"certificate"`
+}
+
+type logOptions struct {
+	LogLevel string `default:"info" usage:"log level"`
+}
+
+func TestBindNested(t *testing.T) {
+	var opts struct {
+		logOptions
+		Server   serverOptions
+		Upstream serverOptions  `prefix:"backend"`
+		Admin    *serverOptions `prefix:""`
+	}
+	env := envy.MapLookuper{
+		"APP_SERVER_PORT":          "9090",
+		"APP_BACKEND_HOST":         "db",
+		"APP_SERVER_TLS_CERT_FILE": "/etc/tls.crt",
+		"APP_LOG_LEVEL":            "debug",
+		"APP_HOST":                 "admin",
+	}
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	assert.NoError(t, envy.BindOnFlagSet(&opts, fs))
+
+	var names []string
+	fs.VisitAll(func(f *pflag.Flag) {
+		names = append(names, f.Name)
+	})
+	assert.Equal(t, []string{
+		"backend-host", "backend-port", "backend-tls-cert-file",
+		"host", "log-level", "port",
+		"server-host", "server-port", "server-tls-cert-file",
+		"tls-cert-file",
+	}, names)
+
+	assert.NoError(t, envy.New(envy.WithFlagSet(fs), envy.WithPrefix("APP"), envy.WithLookuper(env)).ParseE())
+	assert.Equal(t, 9090, opts.Server.Port)
+	assert.Equal(t, "localhost", opts.Server.Host)
+	assert.Equal(t, "/etc/tls.crt", opts.Server.TLS.CertFile)
+	assert.Equal(t, "db", opts.Upstream.Host)
+	assert.Equal(t, 8080, opts.Upstream.Port)
+	assert.Equal(t, "admin", opts.Admin.Host)
+	assert.Equal(t, "debug", opts.LogLevel)
+}
@@ -0,0 +1,68 @@
+package envy
+
+import (
+	"strconv"
+	"strings"
+)
+
+// The extra values accepted by WithLenientBools.
+var (
+	LenientTrue  = []string{"yes", "y", "on"}
+	LenientFalse = []string{"no", "n", "off"}
+)
+
+// SetBoolValues accepts the given words, in any case and with surrounding
+// whitespace, as true or false for bool flags, on top of everything
+// strconv.ParseBool takes. By default only strconv.ParseBool's values are
+// allowed, so a typo doesn't silently turn a flag off. It only affects
+// environment variables and config files and must be called before the call
+// to envy.Parse().
+func SetBoolValues(truthy, falsy []string) {
+	std.boolValues = boolValues(truthy, falsy)
+}
+
+// WithBoolValues works like SetBoolValues for this Envy only.
+func WithBoolValues(truthy, falsy []string) Option {
+	return func(e *Envy) {
+		e.boolValues = boolValues(truthy, falsy)
+	}
+}
+
+// SetLenientBools accepts yes/no, y/n and on/off for bool flags, see
+// SetBoolValues.
+func SetLenientBools() {
+	SetBoolValues(LenientTrue, LenientFalse)
+}
+
+// WithLenientBools works like SetLenientBools for this Envy only.
+func WithLenientBools() Option {
+	return WithBoolValues(LenientTrue, LenientFalse)
+}
+
+// boolValues maps each word to the value strconv.ParseBool takes for it.
+func boolValues(truthy, falsy []string) map[string]string {
+	values := map[string]string{}
+	for _, word := range truthy {
+		values[strings.ToLower(strings.TrimSpace(word))] = "true"
+	}
+	for _, word := range falsy {
+		values[strings.ToLower(strings.TrimSpace(word))] = "false"
+	}
+	return values
+}
+
+// parseBool parses a bool from the environment, accepting the words from
+// SetBoolValues if any were given.
+func (e *Envy) parseBool(val string) (bool, error) {
+	if e.boolValues != nil {
+		val = strings.TrimSpace(val)
+		if word, ok := e.boolValues[strings.ToLower(val)]; ok {
+			val = word
+		}
+	}
+	b, err := strconv.ParseBool(val)
+	if err != nil {
+		return false, ErrInvalidBoolFlagValue
+	}
+	return b, nil
+}
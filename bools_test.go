@@ -0,0 +1,59 @@
+package envy_test
+
+import (
+	"testing"
+
+	"github.com/fernferret/envy"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLenientBools(t *testing.T) {
+	tests := []struct {
+		val  string
+		want bool
+		err  bool
+	}{
+		{val: "YES", want: true},
+		{val: " y ", want: true},
+		{val: "On", want: true},
+		{val: "1 ", want: true},
+		{val: "no", want: false},
+		{val: "OFF", want: false},
+		{val: "true", want: true},
+		{val: "maybe", err: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.val, func(t *testing.T) {
+			fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+			verbose := fs.Bool("verbose", !tt.want, "be verbose")
+			err := envy.New(envy.WithFlagSet(fs), envy.WithPrefix("FOO"), envy.WithLenientBools(),
+				envy.WithLookuper(envy.MapLookuper{"FOO_VERBOSE": tt.val})).ParseE()
+			if tt.err {
+				assert.ErrorIs(t, err, envy.ErrInvalidBoolFlagValue)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, *verbose)
+		})
+	}
+}
+
+func TestBoolValues(t *testing.T) {
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	verbose := fs.Bool("verbose", false, "be verbose")
+	env := envy.MapLookuper{"FOO_VERBOSE": "Ja"}
+	assert.NoError(t, envy.New(envy.WithFlagSet(fs), envy.WithPrefix("FOO"), envy.WithLookuper(env),
+		envy.WithBoolValues([]string{"ja"}, []string{"nein"})).ParseE())
+	assert.True(t, *verbose)
+}
+
+func TestStrictBools(t *testing.T) {
+	for _, val := range []string{"yes", "1 "} {
+		fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+		fs.Bool("verbose", false, "be verbose")
+		err := envy.New(envy.WithFlagSet(fs), envy.WithPrefix("FOO"),
+			envy.WithLookuper(envy.MapLookuper{"FOO_VERBOSE": val})).ParseE()
+		assert.ErrorIs(t, err, envy.ErrInvalidBoolFlagValue)
+	}
+}
@@ -0,0 +1,73 @@
+package envy
+
+import (
+	"archive/tar"
+	"bytes"
+	"encoding/json"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+// SupportBundleVersion is the version of the layout of SupportBundle's
+// archive, bumped whenever a file is renamed or changes format.
+const SupportBundleVersion = 1
+
+// SupportBundle writes a support bundle for the default pflag.CommandLine, see
+// SupportBundleFlagSet.
+func SupportBundle(w io.Writer) error {
+	return SupportBundleFlagSet(w, pflag.CommandLine)
+}
+
+// SupportBundleFlagSet writes a tar archive describing the configuration of
+// the given FlagSet, giving users of envy configured services one standard
+// thing to attach to bug reports. Wrap w in a gzip.Writer to compress it. The
+// archive holds:
+//
+//	VERSION       the SupportBundleVersion
+//	config.json   the value of every flag
+//	sources.json  where each value came from, see SourcesFlagSet
+//	explain.txt   every layer considered for each flag, see ExplainFlagSet
+//
+// Secrets are redacted everywhere. It must be called after pflag.Parse().
+func SupportBundleFlagSet(w io.Writer, fs *pflag.FlagSet) error {
+	config := map[string]string{}
+	explain := &bytes.Buffer{}
+	visitAll(fs, func(f *pflag.Flag) {
+		config[f.Name] = displayValue(f)
+		explain.WriteString(ExplainFlagSet(f.Name, fs).String())
+	})
+	configJSON, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return err
+	}
+	sourcesJSON, err := json.MarshalIndent(SourcesFlagSet(fs), "", "  ")
+	if err != nil {
+		return err
+	}
+
+	files := []struct {
+		name string
+		data []byte
+	}{
+		{"VERSION", []byte(strconv.Itoa(SupportBundleVersion) + "\n")},
+		{"config.json", append(configJSON, '\n')},
+		{"sources.json", append(sourcesJSON, '\n')},
+		{"explain.txt", explain.Bytes()},
+	}
+
+	tw := tar.NewWriter(w)
+	now := time.Now()
+	for _, file := range files {
+		hdr := &tar.Header{Name: file.name, Mode: 0o644, Size: int64(len(file.data)), ModTime: now}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if _, err := tw.Write(file.data); err != nil {
+			return err
+		}
+	}
+	return tw.Close()
+}
@@ -0,0 +1,61 @@
+package envy_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/fernferret/envy"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSupportBundle(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("FOO_TOKEN", "hunter2")
+	pflag.CommandLine = pflag.NewFlagSet("test", pflag.PanicOnError)
+
+	pflag.String("url", "http://localhost", "set the url")
+	pflag.String("token", "", "api token")
+	envy.Secret("token")
+	envy.Parse("FOO")
+	pflag.CommandLine.Parse([]string{"--url=http://flag"})
+
+	buf := &bytes.Buffer{}
+	assert.NoError(t, envy.SupportBundle(buf))
+
+	files := map[string]string{}
+	tr := tar.NewReader(buf)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		files[hdr.Name] = string(data)
+	}
+
+	assert.Equal(t, "1\n", files["VERSION"])
+	assert.NotContains(t, files["config.json"]+files["sources.json"]+files["explain.txt"], "hunter2")
+
+	config := map[string]string{}
+	assert.NoError(t, json.Unmarshal([]byte(files["config.json"]), &config))
+	assert.Equal(t, map[string]string{"token": "<redacted>", "url": "http://flag"}, config)
+
+	var sources []map[string]string
+	assert.NoError(t, json.Unmarshal([]byte(files["sources.json"]), &sources))
+	assert.Equal(t, "env", sources[0]["Origin"])
+	assert.Equal(t, "flag", sources[1]["Origin"])
+
+	assert.Contains(t, files["explain.txt"], "--token\n")
+	assert.Contains(t, files["explain.txt"], "* flag         http://flag\n")
+}
@@ -0,0 +1,217 @@
+package envy
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+var (
+	ErrInvalidCIDR    = errors.New("invalid CIDR")
+	ErrInvalidIPRange = errors.New("invalid IP range, example: 10.0.0.1-10.0.0.50")
+	ErrOverlap        = errors.New("overlapping networks")
+)
+
+// IPRange is an inclusive range of IP addresses of the same family.
+type IPRange struct {
+	Start net.IP
+	End   net.IP
+}
+
+// Contains reports whether the IP is within the range.
+func (r IPRange) Contains(ip net.IP) bool {
+	if ip = normalizeIP(ip, r.Start); ip == nil {
+		return false
+	}
+	return bytes.Compare(ip, r.Start) >= 0 && bytes.Compare(ip, r.End) <= 0
+}
+
+func (r IPRange) String() string {
+	return fmt.Sprintf("%s-%s", r.Start, r.End)
+}
+
+// cidrListValue holds a list of non overlapping networks. Like pflag's slices,
+// the first Set replaces the default and later ones append.
+type cidrListValue struct {
+	p       *[]net.IPNet
+	changed bool
+}
+
+func (v *cidrListValue) Set(val string) error {
+	var nets []net.IPNet
+	if v.changed {
+		nets = append(nets, *v.p...)
+	}
+	for _, field := range splitList(val) {
+		n, err := parseCIDR(field)
+		if err != nil {
+			return err
+		}
+		nets = append(nets, *n)
+	}
+	for i := range nets {
+		for j := i + 1; j < len(nets); j++ {
+			if nets[i].Contains(nets[j].IP) || nets[j].Contains(nets[i].IP) {
+				return fmt.Errorf("%w: %s and %s", ErrOverlap, &nets[i], &nets[j])
+			}
+		}
+	}
+	*v.p = nets
+	v.changed = true
+	return nil
+}
+
+func (v *cidrListValue) String() string {
+	strs := make([]string, 0, len(*v.p))
+	for i := range *v.p {
+		strs = append(strs, (&(*v.p)[i]).String())
+	}
+	return "[" + strings.Join(strs, ",") + "]"
+}
+
+func (v *cidrListValue) Type() string {
+	return "cidrSlice"
+}
+
+// ipRangeListValue holds a list of non overlapping IP ranges. Like pflag's
+// slices, the first Set replaces the default and later ones append.
+type ipRangeListValue struct {
+	p       *[]IPRange
+	changed bool
+}
+
+func (v *ipRangeListValue) Set(val string) error {
+	var ranges []IPRange
+	if v.changed {
+		ranges = append(ranges, *v.p...)
+	}
+	for _, field := range splitList(val) {
+		r, err := parseIPRange(field)
+		if err != nil {
+			return err
+		}
+		ranges = append(ranges, r)
+	}
+	for i := range ranges {
+		for j := i + 1; j < len(ranges); j++ {
+			if ranges[i].Contains(ranges[j].Start) || ranges[j].Contains(ranges[i].Start) {
+				return fmt.Errorf("%w: %s and %s", ErrOverlap, ranges[i], ranges[j])
+			}
+		}
+	}
+	*v.p = ranges
+	v.changed = true
+	return nil
+}
+
+func (v *ipRangeListValue) String() string {
+	strs := make([]string, 0, len(*v.p))
+	for _, r := range *v.p {
+		strs = append(strs, r.String())
+	}
+	return "[" + strings.Join(strs, ",") + "]"
+}
+
+func (v *ipRangeListValue) Type() string {
+	return "ipRangeSlice"
+}
+
+// CIDRListVar defines a CIDR list flag on the default pflag.CommandLine, see
+// CIDRListVarOnFlagSet.
+func CIDRListVar(p *[]net.IPNet, name string, value []string, usage string) {
+	CIDRListVarOnFlagSet(p, name, value, usage, pflag.CommandLine)
+}
+
+// CIDRListVarOnFlagSet defines a flag holding a list of networks, separated by
+// commas or whitespace, which is common for allow lists. Plain IPs are treated
+// as single host networks and overlapping networks are rejected. It panics if
+// the default value isn't valid.
+func CIDRListVarOnFlagSet(p *[]net.IPNet, name string, value []string, usage string, fs *pflag.FlagSet) {
+	v := &cidrListValue{p: p}
+	*p = nil
+	if err := v.Set(strings.Join(value, ",")); err != nil {
+		panic(err)
+	}
+	v.changed = false
+	fs.Var(v, name, usage)
+}
+
+// IPRangeListVar defines an IP range list flag on the default
+// pflag.CommandLine, see IPRangeListVarOnFlagSet.
+func IPRangeListVar(p *[]IPRange, name string, value []string, usage string) {
+	IPRangeListVarOnFlagSet(p, name, value, usage, pflag.CommandLine)
+}
+
+// IPRangeListVarOnFlagSet defines a flag holding a list of IP ranges like
+// 10.0.0.1-10.0.0.50, separated by commas or whitespace. Plain IPs are treated
+// as a range of one address and overlapping ranges are rejected. It panics if
+// the default value isn't valid.
+func IPRangeListVarOnFlagSet(p *[]IPRange, name string, value []string, usage string, fs *pflag.FlagSet) {
+	v := &ipRangeListValue{p: p}
+	*p = nil
+	if err := v.Set(strings.Join(value, ",")); err != nil {
+		panic(err)
+	}
+	v.changed = false
+	fs.Var(v, name, usage)
+}
+
+// splitList splits on commas and whitespace, dropping empty entries.
+func splitList(val string) []string {
+	return strings.FieldsFunc(val, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t' || r == '\n'
+	})
+}
+
+func parseCIDR(val string) (*net.IPNet, error) {
+	if !strings.Contains(val, "/") {
+		ip := net.ParseIP(val)
+		if ip == nil {
+			return nil, fmt.Errorf("%w: %q", ErrInvalidCIDR, val)
+		}
+		if ip4 := ip.To4(); ip4 != nil {
+			return &net.IPNet{IP: ip4, Mask: net.CIDRMask(32, 32)}, nil
+		}
+		return &net.IPNet{IP: ip, Mask: net.CIDRMask(128, 128)}, nil
+	}
+	_, n, err := net.ParseCIDR(val)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidCIDR, val)
+	}
+	return n, nil
+}
+
+func parseIPRange(val string) (IPRange, error) {
+	parts := strings.SplitN(val, "-", 2)
+	start := net.ParseIP(strings.TrimSpace(parts[0]))
+	end := start
+	if len(parts) == 2 {
+		end = net.ParseIP(strings.TrimSpace(parts[1]))
+	}
+	if start == nil || end == nil {
+		return IPRange{}, fmt.Errorf("%w: %q", ErrInvalidIPRange, val)
+	}
+	if start4 := start.To4(); start4 != nil {
+		start = start4
+	}
+	if end = normalizeIP(end, start); end == nil || bytes.Compare(start, end) > 0 {
+		return IPRange{}, fmt.Errorf("%w: %q", ErrInvalidIPRange, val)
+	}
+	return IPRange{Start: start, End: end}, nil
+}
+
+// normalizeIP returns ip in the same length form as ref, or nil if they're of
+// different families.
+func normalizeIP(ip, ref net.IP) net.IP {
+	if len(ref) == net.IPv4len {
+		return ip.To4()
+	}
+	if ip.To4() != nil {
+		return nil
+	}
+	return ip.To16()
+}
@@ -0,0 +1,100 @@
+package envy_test
+
+import (
+	"net"
+	"os"
+	"testing"
+
+	"github.com/fernferret/envy"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCIDRListVar(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		exp   string
+		err   error
+	}{
+		{name: "test comma separated", value: "10.0.0.0/8,192.168.0.0/16", exp: "[10.0.0.0/8,192.168.0.0/16]"},
+		{name: "test space separated", value: "10.0.0.0/8  fd00::/8", exp: "[10.0.0.0/8,fd00::/8]"},
+		{name: "test plain ips", value: "127.0.0.1, ::1", exp: "[127.0.0.1/32,::1/128]"},
+		{name: "test overlap", value: "10.0.0.0/8,10.1.0.0/16", err: envy.ErrOverlap},
+		{name: "test invalid", value: "10.0.0.0/33", err: envy.ErrInvalidCIDR},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+
+			var nets []net.IPNet
+			envy.CIDRListVarOnFlagSet(&nets, "allow", []string{"0.0.0.0/0"}, "allowed networks", fs)
+
+			err := fs.Set("allow", tt.value)
+			if tt.err != nil {
+				assert.ErrorContains(t, err, tt.err.Error())
+				assert.Equal(t, "[0.0.0.0/0]", fs.Lookup("allow").Value.String())
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.exp, fs.Lookup("allow").Value.String())
+		})
+	}
+}
+
+func TestCIDRListVarAppends(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("FOO_ALLOW", "10.0.0.0/8")
+	pflag.CommandLine = pflag.NewFlagSet("test", pflag.PanicOnError)
+
+	var nets []net.IPNet
+	envy.CIDRListVar(&nets, "allow", nil, "allowed networks")
+	envy.Parse("FOO")
+
+	assert.Equal(t, "[]", pflag.Lookup("allow").DefValue)
+	assert.NoError(t, pflag.Set("allow", "192.168.0.0/16"))
+	assert.Len(t, nets, 2)
+	assert.Error(t, pflag.Set("allow", "10.2.0.0/16"))
+}
+
+func TestIPRangeListVar(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		exp   string
+		err   error
+	}{
+		{name: "test ranges", value: "10.0.0.1-10.0.0.50, 10.0.0.100-10.0.0.200", exp: "[10.0.0.1-10.0.0.50,10.0.0.100-10.0.0.200]"},
+		{name: "test single ip", value: "10.0.0.7", exp: "[10.0.0.7-10.0.0.7]"},
+		{name: "test ipv6 range", value: "fd00::1-fd00::ff", exp: "[fd00::1-fd00::ff]"},
+		{name: "test overlap", value: "10.0.0.1-10.0.0.50,10.0.0.50", err: envy.ErrOverlap},
+		{name: "test backwards", value: "10.0.0.50-10.0.0.1", err: envy.ErrInvalidIPRange},
+		{name: "test mixed families", value: "10.0.0.1-fd00::1", err: envy.ErrInvalidIPRange},
+		{name: "test invalid", value: "10.0.0.1-nope", err: envy.ErrInvalidIPRange},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+
+			var ranges []envy.IPRange
+			envy.IPRangeListVarOnFlagSet(&ranges, "pool", nil, "address pool", fs)
+
+			err := fs.Set("pool", tt.value)
+			if tt.err != nil {
+				assert.ErrorContains(t, err, tt.err.Error())
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.exp, fs.Lookup("pool").Value.String())
+		})
+	}
+}
+
+func TestIPRangeContains(t *testing.T) {
+	r := envy.IPRange{Start: net.ParseIP("10.0.0.1").To4(), End: net.ParseIP("10.0.0.50").To4()}
+
+	assert.True(t, r.Contains(net.ParseIP("10.0.0.1")))
+	assert.True(t, r.Contains(net.ParseIP("10.0.0.50")))
+	assert.False(t, r.Contains(net.ParseIP("10.0.0.51")))
+	assert.False(t, r.Contains(net.ParseIP("fd00::1")))
+}
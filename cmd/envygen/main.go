@@ -0,0 +1,427 @@
+// Command envygen generates the pflag definitions and envy bindings for
+// structs tagged the way envy.Bind expects, for programs that want the struct
+// ergonomics without reflection at runtime. Add a directive next to the
+// struct:
+//
+//	//go:generate go run github.com/fernferret/envy/cmd/envygen -type options
+//
+// and run go generate to write options_envy.go with a RegisterFlags method:
+//
+//	opts := options{}
+//	opts.RegisterFlags(pflag.CommandLine)
+//	envy.Parse("MYAPP")
+//
+// It reads the flag, env, default, usage and prefix tags just like envy.Bind,
+// nesting structs from the same package. Fields of any type it doesn't know
+// are assumed to implement pflag.Value, and the compiler will say so if they
+// don't.
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+func main() {
+	typeNames := flag.String("type", "", "comma separated list of struct types to generate bindings for")
+	output := flag.String("output", "", "output file, defaults to <type>_envy.go")
+	flag.Parse()
+	if *typeNames == "" {
+		fmt.Fprintln(os.Stderr, "envygen: -type is required")
+		os.Exit(2)
+	}
+
+	dir := "."
+	if flag.NArg() > 0 {
+		dir = flag.Arg(0)
+	}
+	types := strings.Split(*typeNames, ",")
+	path := *output
+	if path == "" {
+		path = filepath.Join(dir, strings.ToLower(types[0])+"_envy.go")
+	}
+
+	pkg, files, err := parseDir(dir, path)
+	if err != nil {
+		fail(err)
+	}
+	src, err := generate(pkg, files, types)
+	if err != nil {
+		fail(err)
+	}
+	if err := os.WriteFile(path, src, 0o644); err != nil {
+		fail(err)
+	}
+}
+
+func fail(err error) {
+	fmt.Fprintf(os.Stderr, "envygen: %v\n", err)
+	os.Exit(1)
+}
+
+// parseDir parses the non-test Go files in the directory, skipping the file
+// about to be generated.
+func parseDir(dir, output string) (string, []*ast.File, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return "", nil, err
+	}
+	fset := token.NewFileSet()
+	var pkg string
+	var files []*ast.File
+	for _, path := range paths {
+		if strings.HasSuffix(path, "_test.go") || filepath.Clean(path) == filepath.Clean(output) {
+			continue
+		}
+		file, err := parser.ParseFile(fset, path, nil, 0)
+		if err != nil {
+			return "", nil, err
+		}
+		pkg = file.Name.Name
+		files = append(files, file)
+	}
+	if len(files) == 0 {
+		return "", nil, fmt.Errorf("no Go files in %s", dir)
+	}
+	return pkg, files, nil
+}
+
+// generator writes the body of each RegisterFlags method.
+type generator struct {
+	structs map[string]*ast.StructType
+	buf     bytes.Buffer
+	imports map[string]bool
+}
+
+// generate returns the formatted source defining RegisterFlags for each type.
+func generate(pkg string, files []*ast.File, types []string) ([]byte, error) {
+	g := &generator{structs: map[string]*ast.StructType{}, imports: map[string]bool{"github.com/spf13/pflag": true}}
+	for _, file := range files {
+		ast.Inspect(file, func(n ast.Node) bool {
+			if spec, ok := n.(*ast.TypeSpec); ok {
+				if st, ok := spec.Type.(*ast.StructType); ok {
+					g.structs[spec.Name.Name] = st
+				}
+			}
+			return true
+		})
+	}
+
+	var body bytes.Buffer
+	for _, name := range types {
+		st, ok := g.structs[name]
+		if !ok {
+			return nil, fmt.Errorf("struct type %s not found", name)
+		}
+		g.buf.Reset()
+		if err := g.structFields(st, "o", ""); err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		fmt.Fprintf(&body, "\n// RegisterFlags defines a flag on fs for every field of %s and binds it\n", name)
+		fmt.Fprintf(&body, "// with envy, it must be called before the call to envy.Parse().\n")
+		fmt.Fprintf(&body, "func (o *%s) RegisterFlags(fs *pflag.FlagSet) {\n%s}\n", name, g.buf.String())
+	}
+
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "// Code generated by envygen. DO NOT EDIT.\n\npackage %s\n\nimport (\n", pkg)
+
+	// The standard library goes first, like goimports would have it.
+	var std, others []string
+	for path := range g.imports {
+		if strings.Contains(strings.Split(path, "/")[0], ".") {
+			others = append(others, path)
+		} else {
+			std = append(std, path)
+		}
+	}
+	for i, group := range [][]string{std, others} {
+		if i > 0 && len(std) > 0 {
+			out.WriteString("\n")
+		}
+		sort.Strings(group)
+		for _, path := range group {
+			fmt.Fprintf(&out, "\t%q\n", path)
+		}
+	}
+	fmt.Fprintf(&out, ")\n%s", body.String())
+	return format.Source(out.Bytes())
+}
+
+// structFields writes the definitions for every field of the struct, found
+// at expr, with each flag name starting with the group.
+func (g *generator) structFields(st *ast.StructType, expr, group string) error {
+	for _, field := range st.Fields.List {
+		var tag reflect.StructTag
+		if field.Tag != nil {
+			unquoted, err := strconv.Unquote(field.Tag.Value)
+			if err != nil {
+				return err
+			}
+			tag = reflect.StructTag(unquoted)
+		}
+
+		names := field.Names
+		embedded := len(names) == 0
+		if embedded {
+			names = []*ast.Ident{ast.NewIdent(typeName(field.Type))}
+		}
+		for _, ident := range names {
+			name, ok := tag.Lookup("flag")
+			if name == "-" {
+				continue
+			}
+			nested, pointer := g.nested(field.Type)
+			if !ast.IsExported(ident.Name) && !(embedded && nested != nil && !pointer) {
+				continue
+			}
+			if !ok {
+				name = kebabCase(ident.Name)
+			}
+			fieldExpr := expr + "." + ident.Name
+			if nested != nil {
+				if pointer {
+					fmt.Fprintf(&g.buf, "if %s == nil {\n%s = new(%s)\n}\n", fieldExpr, fieldExpr, typeName(field.Type))
+				}
+				if err := g.structFields(nested, fieldExpr, groupOf(group, name, embedded, tag)); err != nil {
+					return err
+				}
+				continue
+			}
+			if err := g.field(field.Type, fieldExpr, group+name, tag); err != nil {
+				return fmt.Errorf("field %s: %w", ident.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// nested returns the struct a field holds, or points to, if it's declared in
+// the same package.
+func (g *generator) nested(typ ast.Expr) (*ast.StructType, bool) {
+	pointer := false
+	if star, ok := typ.(*ast.StarExpr); ok {
+		typ, pointer = star.X, true
+	}
+	if ident, ok := typ.(*ast.Ident); ok {
+		if st, ok := g.structs[ident.Name]; ok {
+			return st, pointer
+		}
+	}
+	return nil, false
+}
+
+// groupOf returns the group for the flags of a nested struct.
+func groupOf(group, name string, embedded bool, tag reflect.StructTag) string {
+	pfx, ok := tag.Lookup("prefix")
+	switch {
+	case ok:
+		name = pfx
+	case embedded:
+		name = ""
+	}
+	if name == "" {
+		return group
+	}
+	return group + name + "-"
+}
+
+// The pflag function defining a flag of each type envygen knows.
+var definers = map[string]string{
+	"string":        "StringVar",
+	"bool":          "BoolVar",
+	"int":           "IntVar",
+	"int32":         "Int32Var",
+	"int64":         "Int64Var",
+	"uint":          "UintVar",
+	"uint32":        "Uint32Var",
+	"uint64":        "Uint64Var",
+	"float32":       "Float32Var",
+	"float64":       "Float64Var",
+	"time.Duration": "DurationVar",
+	"[]string":      "StringSliceVar",
+	"[]int":         "IntSliceVar",
+}
+
+// field writes the definition of a single flag and applies its tags.
+func (g *generator) field(typ ast.Expr, expr, name string, tag reflect.StructTag) error {
+	typeStr := typeString(typ)
+	usage := strconv.Quote(tag.Get("usage"))
+	definer, known := definers[typeStr]
+	if def, ok := tag.Lookup("default"); ok {
+		if !known {
+			fmt.Fprintf(&g.buf, "if err := %s.Set(%q); err != nil {\npanic(err)\n}\n", expr, def)
+		} else {
+			lit, err := g.literal(typeStr, def)
+			if err != nil {
+				return fmt.Errorf("default %q: %w", def, err)
+			}
+			fmt.Fprintf(&g.buf, "%s = %s\n", expr, lit)
+		}
+	}
+	if known {
+		fmt.Fprintf(&g.buf, "fs.%s(&%s, %q, %s, %s)\n", definer, expr, name, expr, usage)
+	} else {
+		fmt.Fprintf(&g.buf, "fs.Var(&%s, %q, %s)\n", expr, name, usage)
+	}
+
+	switch env, ok := tag.Lookup("env"); {
+	case !ok:
+	case env == "-":
+		g.imports["github.com/fernferret/envy"] = true
+		fmt.Fprintf(&g.buf, "envy.DisableOnFlagSet(%q, fs)\n", name)
+	default:
+		g.imports["github.com/fernferret/envy"] = true
+		var quoted []string
+		for _, envName := range strings.Split(env, ",") {
+			quoted = append(quoted, strconv.Quote(envName))
+		}
+		fmt.Fprintf(&g.buf, "envy.SetEnvNamesOnFlagSet(%q, fs, %s)\n", name, strings.Join(quoted, ", "))
+	}
+	return nil
+}
+
+// literal parses a default tag the way pflag would and returns it as Go
+// source, so mistakes are caught by go generate rather than at startup.
+func (g *generator) literal(typ, def string) (string, error) {
+	switch typ {
+	case "string":
+		return strconv.Quote(def), nil
+	case "bool":
+		v, err := strconv.ParseBool(def)
+		return strconv.FormatBool(v), err
+	case "int", "int32", "int64":
+		v, err := strconv.ParseInt(def, 0, bitSize(typ))
+		return strconv.FormatInt(v, 10), err
+	case "uint", "uint32", "uint64":
+		v, err := strconv.ParseUint(def, 0, bitSize(typ))
+		return strconv.FormatUint(v, 10), err
+	case "float32", "float64":
+		v, err := strconv.ParseFloat(def, bitSize(typ))
+		return strconv.FormatFloat(v, 'g', -1, bitSize(typ)), err
+	case "time.Duration":
+		d, err := time.ParseDuration(def)
+		g.imports["time"] = true
+		return durationLiteral(d), err
+	case "[]string":
+		if def == "" {
+			return "[]string{}", nil
+		}
+		vals, err := csv.NewReader(strings.NewReader(def)).Read()
+		if err != nil {
+			return "", err
+		}
+		quoted := make([]string, len(vals))
+		for i, v := range vals {
+			quoted[i] = strconv.Quote(v)
+		}
+		return "[]string{" + strings.Join(quoted, ", ") + "}", nil
+	case "[]int":
+		if def == "" {
+			return "[]int{}", nil
+		}
+		vals := strings.Split(def, ",")
+		for i, v := range vals {
+			n, err := strconv.Atoi(strings.TrimSpace(v))
+			if err != nil {
+				return "", err
+			}
+			vals[i] = strconv.Itoa(n)
+		}
+		return "[]int{" + strings.Join(vals, ", ") + "}", nil
+	}
+	return "", errors.New("unsupported type " + typ)
+}
+
+// bitSize returns the size of a sized numeric type, or 64.
+func bitSize(typ string) int {
+	if strings.HasSuffix(typ, "32") {
+		return 32
+	}
+	return 64
+}
+
+// durationLiteral writes the duration in the largest unit it's a multiple of.
+func durationLiteral(d time.Duration) string {
+	units := []struct {
+		name string
+		d    time.Duration
+	}{
+		{"time.Hour", time.Hour},
+		{"time.Minute", time.Minute},
+		{"time.Second", time.Second},
+		{"time.Millisecond", time.Millisecond},
+		{"time.Microsecond", time.Microsecond},
+	}
+	if d == 0 {
+		return "0"
+	}
+	for _, unit := range units {
+		if d%unit.d == 0 {
+			return fmt.Sprintf("%d * %s", d/unit.d, unit.name)
+		}
+	}
+	return fmt.Sprintf("%d * time.Nanosecond", d)
+}
+
+// typeString returns the source of a field's type, like []string or
+// time.Duration.
+func typeString(typ ast.Expr) string {
+	switch t := typ.(type) {
+	case *ast.Ident:
+		return t.Name
+	case *ast.SelectorExpr:
+		return typeString(t.X) + "." + t.Sel.Name
+	case *ast.StarExpr:
+		return "*" + typeString(t.X)
+	case *ast.ArrayType:
+		if t.Len == nil {
+			return "[]" + typeString(t.Elt)
+		}
+	}
+	return fmt.Sprintf("%T", typ)
+}
+
+// typeName returns the name of a possibly pointer type, used as the name of
+// embedded fields.
+func typeName(typ ast.Expr) string {
+	if star, ok := typ.(*ast.StarExpr); ok {
+		typ = star.X
+	}
+	if sel, ok := typ.(*ast.SelectorExpr); ok {
+		return sel.Sel.Name
+	}
+	return typeString(typ)
+}
+
+// kebabCase turns a Go field name into a flag name the same way envy.Bind
+// does, so HTTPPort becomes http-port and CountFancy count-fancy.
+func kebabCase(name string) string {
+	runes := []rune(name)
+	b := &strings.Builder{}
+	for i, r := range runes {
+		if i > 0 && unicode.IsUpper(r) {
+			prevLower := !unicode.IsUpper(runes[i-1])
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if prevLower || nextLower {
+				b.WriteByte('-')
+			}
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return b.String()
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const input = `package app
+
+import (
+	"time"
+
+	"github.com/fernferret/envy"
+)
+
+type logOptions struct {
+	LogLevel string ` + "`default:\"info\" usage:\"log level\"`" + `
+}
+
+type tlsOptions struct {
+	CertFile string ` + "`usage:\"certificate\"`" + `
+}
+
+type options struct {
+	logOptions
+	URL      string        ` + "`default:\"http://localhost\" usage:\"set the url\"`" + `
+	HTTPPort int           ` + "`usage:\"port to listen on\"`" + `
+	Timeout  time.Duration ` + "`flag:\"request-timeout\" env:\"HTTP_TIMEOUT,TIMEOUT\" default:\"90s\"`" + `
+	Once     bool          ` + "`env:\"-\"`" + `
+	Tags     []string      ` + "`default:\"a,b\"`" + `
+	Ratio    float64       ` + "`default:\"0.5\"`" + `
+	Region   regionValue   ` + "`default:\"us-east-1\"`" + `
+	Network  envy.IPRange
+	TLS      *tlsOptions   ` + "`prefix:\"tls\"`" + `
+	Ignored  string        ` + "`flag:\"-\"`" + `
+	internal string
+}
+`
+
+const expected = `// Code generated by envygen. DO NOT EDIT.
+
+package app
+
+import (
+	"time"
+
+	"github.com/fernferret/envy"
+	"github.com/spf13/pflag"
+)
+
+// RegisterFlags defines a flag on fs for every field of options and binds it
+// with envy, it must be called before the call to envy.Parse().
+func (o *options) RegisterFlags(fs *pflag.FlagSet) {
+	o.logOptions.LogLevel = "info"
+	fs.StringVar(&o.logOptions.LogLevel, "log-level", o.logOptions.LogLevel, "log level")
+	o.URL = "http://localhost"
+	fs.StringVar(&o.URL, "url", o.URL, "set the url")
+	fs.IntVar(&o.HTTPPort, "http-port", o.HTTPPort, "port to listen on")
+	o.Timeout = 90 * time.Second
+	fs.DurationVar(&o.Timeout, "request-timeout", o.Timeout, "")
+	envy.SetEnvNamesOnFlagSet("request-timeout", fs, "HTTP_TIMEOUT", "TIMEOUT")
+	fs.BoolVar(&o.Once, "once", o.Once, "")
+	envy.DisableOnFlagSet("once", fs)
+	o.Tags = []string{"a", "b"}
+	fs.StringSliceVar(&o.Tags, "tags", o.Tags, "")
+	o.Ratio = 0.5
+	fs.Float64Var(&o.Ratio, "ratio", o.Ratio, "")
+	if err := o.Region.Set("us-east-1"); err != nil {
+		panic(err)
+	}
+	fs.Var(&o.Region, "region", "")
+	fs.Var(&o.Network, "network", "")
+	if o.TLS == nil {
+		o.TLS = new(tlsOptions)
+	}
+	fs.StringVar(&o.TLS.CertFile, "tls-cert-file", o.TLS.CertFile, "certificate")
+}
+`
+
+func parse(t *testing.T, src string) []*ast.File {
+	file, err := parser.ParseFile(token.NewFileSet(), "options.go", src, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return []*ast.File{file}
+}
+
+func TestGenerate(t *testing.T) {
+	src, err := generate("app", parse(t, input), []string{"options"})
+	assert.NoError(t, err)
+	assert.Equal(t, expected, string(src))
+}
+
+func TestGenerateErrors(t *testing.T) {
+	_, err := generate("app", parse(t, input), []string{"missing"})
+	assert.EqualError(t, err, "struct type missing not found")
+
+	bad := "package app\n\ntype options struct {\n\tPort int `default:\"http\"`\n}\n"
+	_, err = generate("app", parse(t, bad), []string{"options"})
+	assert.EqualError(t, err, `options: field Port: default "http": strconv.ParseInt: parsing "http": invalid syntax`)
+}
+
+func TestDurationLiteral(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"0s", "0"},
+		{"2h", "2 * time.Hour"},
+		{"90m", "90 * time.Minute"},
+		{"1m30s", "90 * time.Second"},
+		{"1.5s", "1500 * time.Millisecond"},
+		{"3ns", "3 * time.Nanosecond"},
+	}
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			d, err := time.ParseDuration(test.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, test.expected, durationLiteral(d))
+		})
+	}
+}
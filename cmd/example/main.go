@@ -9,22 +9,17 @@ import (
 )
 
 type example struct {
-	Url        string
-	Once       bool
-	Count      int
-	CountFancy int
+	Url        string `default:"http://127.0.0.1:8080" usage:"set the url"`
+	Once       bool   `env:"-" usage:"perform the thing once and exit"`
+	Count      int    `default:"13" usage:"a standard count"`
+	CountFancy int    `env:"-" default:"7" usage:"a fancy count"`
 }
 
 func main() {
 	opts := example{}
-	pflag.StringVar(&opts.Url, "url", "http://127.0.0.1:8080", "set the url")
-	pflag.IntVar(&opts.Count, "count", 13, "a standard count")
-
-	pflag.BoolVar(&opts.Once, "once", false, "perform the thing once and exit")
-	pflag.IntVar(&opts.CountFancy, "count-fancy", 7, "a fancy count")
-
-	envy.Disable("once")
-	envy.Disable("count-fancy")
+	if err := envy.Bind(&opts); err != nil {
+		panic(err)
+	}
 
 	envy.Parse("EXAMPLE")
 
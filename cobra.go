@@ -0,0 +1,98 @@
+package envy
+
+import (
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+// Command is the part of a *cobra.Command that BindCobra needs, so envy
+// doesn't have to depend on cobra.
+type Command[C any] interface {
+	Name() string
+	Flags() *pflag.FlagSet
+	PersistentFlags() *pflag.FlagSet
+	Commands() []C
+}
+
+// CobraOption configures BindCobra.
+type CobraOption func(*cobraBinding)
+
+type cobraBinding struct {
+	pfx      string
+	template string
+}
+
+// PrefixTemplate sets how each command's prefix is built. {APP} is the prefix
+// given to BindCobra, {CMD} the names of every command below the root joined
+// with underscores and {NAME} the command's own name. Empty parts are
+// dropped, so the root command always gets {APP}. For the --port flag of
+// "app serve http":
+//
+//	{APP}_{CMD}   APP_SERVE_HTTP_PORT, the default
+//	{APP}_{NAME}  APP_HTTP_PORT, skipping intermediate commands
+//	{APP}         APP_PORT, flattening every command into one namespace
+func PrefixTemplate(tmpl string) CobraOption {
+	return func(b *cobraBinding) {
+		b.template = tmpl
+	}
+}
+
+// BindCobra binds every flag of a cobra command tree to the environment. Each
+// command gets its own prefix built from its name under its parent's, so the
+// --port flag of "app serve" is read from APP_SERVE_PORT while flags of the
+// root command, including persistent ones every subcommand inherits, use APP.
+// PrefixTemplate changes how the prefixes are built. Call it after every
+// command and flag is defined, right before Execute:
+//
+//	if err := envy.BindCobra(rootCmd, "APP"); err != nil {
+//		log.Fatal(err)
+//	}
+//	rootCmd.Execute()
+//
+// Like ParseE, it binds every flag it can and returns a ParseErrors for the
+// rest.
+func BindCobra[C Command[C]](root C, pfx string, opts ...CobraOption) error {
+	b := &cobraBinding{pfx: pfx, template: "{APP}_{CMD}"}
+	for _, opt := range opts {
+		opt(b)
+	}
+	if errs := bindCommand(b, root, nil); len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// bindCommand binds the command's flags under its prefix, then those of its
+// subcommands. The path holds the names of the commands below the root.
+func bindCommand[C Command[C]](b *cobraBinding, cmd C, path []string) ParseErrors {
+	pfx := b.prefixFor(path)
+	errs := std.on(pfx, cmd.PersistentFlags()).parse()
+	errs = append(errs, std.on(pfx, cmd.Flags()).parse()...)
+	for _, sub := range cmd.Commands() {
+		subPath := append(append([]string{}, path...), envKey(sub.Name()))
+		errs = append(errs, bindCommand(b, sub, subPath)...)
+	}
+	return errs
+}
+
+// prefixFor expands the template for the command at the path.
+func (b *cobraBinding) prefixFor(path []string) string {
+	var name string
+	if len(path) > 0 {
+		name = path[len(path)-1]
+	}
+	pfx := strings.NewReplacer(
+		"{APP}", strings.ToUpper(b.pfx),
+		"{CMD}", strings.Join(path, "_"),
+		"{NAME}", name,
+	).Replace(b.template)
+
+	var parts []string
+	for _, part := range strings.Split(pfx, "_") {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(parts, "_")
+}
@@ -0,0 +1,86 @@
+package envy_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/fernferret/envy"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+)
+
+// command mimics the parts of *cobra.Command envy uses.
+type command struct {
+	name       string
+	flags      *pflag.FlagSet
+	persistent *pflag.FlagSet
+	commands   []*command
+}
+
+func newCommand(name string, subs ...*command) *command {
+	return &command{
+		name:       name,
+		flags:      pflag.NewFlagSet(name, pflag.ContinueOnError),
+		persistent: pflag.NewFlagSet(name, pflag.ContinueOnError),
+		commands:   subs,
+	}
+}
+
+func (c *command) Name() string                    { return c.name }
+func (c *command) Flags() *pflag.FlagSet           { return c.flags }
+func (c *command) PersistentFlags() *pflag.FlagSet { return c.persistent }
+func (c *command) Commands() []*command            { return c.commands }
+
+func TestBindCobra(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("APP_VERBOSE", "true")
+	os.Setenv("APP_SERVE_PORT", "9090")
+	os.Setenv("APP_SERVE_GRPC_GATEWAY_ADDR", ":8081")
+	os.Setenv("APP_PORT", "1")
+
+	gateway := newCommand("grpc-gateway")
+	serve := newCommand("serve", gateway)
+	root := newCommand("app", serve)
+	verbose := root.PersistentFlags().Bool("verbose", false, "verbose output")
+	port := serve.Flags().Int("port", 8080, "port to listen on")
+	addr := gateway.Flags().String("addr", ":80", "gateway address")
+
+	assert.NoError(t, envy.BindCobra(root, "APP"))
+	assert.True(t, *verbose)
+	assert.Equal(t, 9090, *port)
+	assert.Equal(t, ":8081", *addr)
+
+	os.Setenv("APP_SERVE_PORT", "many")
+	serve = newCommand("serve")
+	serve.Flags().Int("port", 8080, "port to listen on")
+	err := envy.BindCobra(newCommand("app", serve), "APP")
+	assert.EqualError(t, err, `--port from APP_SERVE_PORT: strconv.ParseInt: parsing "many": invalid syntax`)
+}
+
+func TestBindCobraPrefixTemplate(t *testing.T) {
+	tests := []struct {
+		template string
+		envName  string
+	}{
+		{"{APP}_{CMD}", "APP_SERVE_HTTP_PORT"},
+		{"{APP}_{NAME}", "APP_HTTP_PORT"},
+		{"{APP}", "APP_PORT"},
+		{"{CMD}_{APP}", "SERVE_HTTP_APP_PORT"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.template, func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(tt.envName, "9090")
+			os.Setenv("APP_VERBOSE", "true")
+
+			http := newCommand("http")
+			root := newCommand("app", newCommand("serve", http))
+			verbose := root.PersistentFlags().Bool("verbose", false, "verbose output")
+			port := http.Flags().Int("port", 8080, "port to listen on")
+
+			assert.NoError(t, envy.BindCobra(root, "app", envy.PrefixTemplate(tt.template)))
+			assert.Equal(t, 9090, *port)
+			assert.True(t, *verbose)
+		})
+	}
+}
@@ -0,0 +1,181 @@
+package envy
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/pflag"
+	"gopkg.in/yaml.v3"
+)
+
+// Set by ParseFlagSet to record the config file that supplied the flag's value.
+const AnnotationFile = "envy_file"
+
+var ErrConfigKeyConflict = errors.New("config keys set the same flag")
+
+// Formats of config files.
+const (
+	formatYAML = "yaml"
+	formatJSON = "json"
+)
+
+// configFile holds the values of one config file by flag name.
+type configFile struct {
+	path   string
+	format string
+	values map[string]string
+}
+
+// readConfig reads and flattens a config file in the given format.
+func readConfig(path, format string) (configFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return configFile{}, err
+	}
+	doc := map[string]interface{}{}
+	if format == formatJSON {
+		// Keep numbers as written, float64 would turn 1000000 into 1e+06.
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.UseNumber()
+		err = dec.Decode(&doc)
+	} else {
+		err = yaml.Unmarshal(data, &doc)
+	}
+	if err != nil {
+		return configFile{}, err
+	}
+	c, err := newConfigFile(path, doc)
+	if err != nil {
+		return configFile{}, err
+	}
+	c.format = format
+	return c, nil
+}
+
+// withFile is the Option behind WithYAMLFile and WithJSONFile.
+func withFile(path, format string) Option {
+	return func(e *Envy) {
+		c, err := readConfig(path, format)
+		if err != nil {
+			e.optErrs = append(e.optErrs, &SetError{EnvName: path, Err: err})
+			return
+		}
+		e.files = append(e.files, c)
+	}
+}
+
+// setFiles replaces the default Envy's config files of one format, keeping
+// the others. If a file can't be read nothing is changed.
+func setFiles(format string, paths []string) error {
+	var files []configFile
+	for _, c := range std.files {
+		if c.format != format {
+			files = append(files, c)
+		}
+	}
+	for _, path := range paths {
+		c, err := readConfig(path, format)
+		if err != nil {
+			return err
+		}
+		files = append(files, c)
+	}
+	std.files = files
+	return nil
+}
+
+// newConfigFile flattens a decoded document into a configFile. Nested sections
+// become dotted keys, so server: {port: 80} sets --server.port, or
+// --server-port if there's no such flag. Lists are joined with commas like
+// slice flags expect. Keys that only differ in dots and dashes, like a.b-c and
+// a-b.c, would set the same flag so they're reported rather than one winning
+// at random.
+func newConfigFile(path string, doc map[string]interface{}) (configFile, error) {
+	flat := map[string]string{}
+	flatten("", doc, flat)
+
+	c := configFile{path: path, values: map[string]string{}}
+	for key, val := range flat {
+		c.values[key] = val
+	}
+	aliases := map[string][]string{}
+	for key := range flat {
+		alias := strings.ReplaceAll(key, ".", "-")
+		if _, ok := flat[alias]; !ok {
+			aliases[alias] = append(aliases[alias], key)
+		}
+	}
+	for alias, keys := range aliases {
+		if len(keys) > 1 {
+			sort.Strings(keys)
+			return configFile{}, fmt.Errorf("%w: %s", ErrConfigKeyConflict, strings.Join(keys, ", "))
+		}
+		c.values[alias] = flat[keys[0]]
+	}
+	return c, nil
+}
+
+// flatten adds every scalar in the value to out under its dotted key.
+func flatten(key string, v interface{}, out map[string]string) {
+	join := func(k string) string {
+		if key == "" {
+			return k
+		}
+		return key + "." + k
+	}
+	switch t := v.(type) {
+	case map[string]interface{}:
+		for k, val := range t {
+			flatten(join(k), val, out)
+		}
+	case map[interface{}]interface{}:
+		for k, val := range t {
+			flatten(join(fmt.Sprint(k)), val, out)
+		}
+	case []interface{}:
+		items := make([]string, len(t))
+		for i, item := range t {
+			items[i] = fmt.Sprint(item)
+		}
+		out[key] = strings.Join(items, ",")
+	case nil:
+	default:
+		out[key] = fmt.Sprint(t)
+	}
+}
+
+// lookupFile returns the value for the flag from the last config file that has
+// one, so later files override earlier ones.
+func (e *Envy) lookupFile(f *pflag.Flag) (string, string, bool) {
+	for i := len(e.files) - 1; i >= 0; i-- {
+		if val, ok := e.files[i].values[f.Name]; ok {
+			return e.files[i].path, val, true
+		}
+	}
+	return "", "", false
+}
+
+// applyFile sets the flag from the config files, if any of them have it.
+func (e *Envy) applyFile(f *pflag.Flag) (bool, *SetError) {
+	path, val, ok := e.lookupFile(f)
+	if src, done := f.Annotations[AnnotationFile]; !ok || done && src[0] == path {
+		// Values already read from this file are left alone so slices aren't
+		// appended to twice.
+		return ok, nil
+	}
+	norm, err := e.normalize(f, val)
+	if err == nil {
+		e.remember(f)
+		err = setValue(f, norm)
+	}
+	if err != nil {
+		return true, valueError(f, path, val, err)
+	}
+	annotate(f, AnnotationFile, path)
+	return true, nil
+}
@@ -0,0 +1,173 @@
+package envy
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+var (
+	ErrNotOneOf   = errors.New("value is not one of the allowed values")
+	ErrOutOfRange = errors.New("value is out of range")
+	ErrNoMatch    = errors.New("value does not match the pattern")
+)
+
+// checkedValue runs a flag's constraints before every Set, whether the value
+// comes from the command line, the environment or a config file.
+type checkedValue struct {
+	pflag.Value
+	checks []func(string) error
+}
+
+func (v *checkedValue) Set(val string) error {
+	if err := v.check(val); err != nil {
+		return err
+	}
+	return v.Value.Set(val)
+}
+
+func (v *checkedValue) unwrap() pflag.Value {
+	return v.Value
+}
+
+// guard checks each value given to a slice's Append or Replace.
+func (v *checkedValue) guard(vals []string, change func() error) error {
+	for _, val := range vals {
+		if err := v.check(val); err != nil {
+			return err
+		}
+	}
+	return change()
+}
+
+func (v *checkedValue) check(val string) error {
+	for _, check := range v.checks {
+		if err := check(val); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Validate adds a check to a flag in the default pflag.CommandLine, see
+// ValidateOnFlagSet.
+func Validate(name string, fn func(string) error) {
+	ValidateOnFlagSet(name, fn, pflag.CommandLine)
+}
+
+// ValidateOnFlagSet adds a check that every value given to the flag must
+// pass, from the command line, the environment or a config file, before the
+// flag is Set. Several checks run in the order they were added. The default
+// value isn't checked. It must be called before the call to envy.Parse().
+func ValidateOnFlagSet(name string, fn func(string) error, fs *pflag.FlagSet) {
+	std.on("", fs).Validate(name, fn)
+}
+
+// Validate adds a check to a flag, see ValidateOnFlagSet.
+func (e *Envy) Validate(name string, fn func(string) error) {
+	e.constrain(name, "", fn)
+}
+
+// OneOf limits a flag in the default pflag.CommandLine to a list of values,
+// see OneOfOnFlagSet.
+func OneOf(name string, allowed ...string) {
+	OneOfOnFlagSet(name, pflag.CommandLine, allowed...)
+}
+
+// OneOfOnFlagSet limits a flag to a list of values, compared exactly, and
+// lists them in its usage. See Enum for a value that also ignores case. It
+// must be called before the call to envy.Parse().
+func OneOfOnFlagSet(name string, fs *pflag.FlagSet, allowed ...string) {
+	std.on("", fs).OneOf(name, allowed...)
+}
+
+// OneOf limits a flag to a list of values, see OneOfOnFlagSet.
+func (e *Envy) OneOf(name string, allowed ...string) {
+	list := strings.Join(allowed, ", ")
+	e.constrain(name, "one of "+list, func(val string) error {
+		if contains(allowed, val) {
+			return nil
+		}
+		return fmt.Errorf("%w: %q must be one of %s", ErrNotOneOf, val, list)
+	})
+}
+
+// IntRange limits an integer flag in the default pflag.CommandLine to a range,
+// see IntRangeOnFlagSet.
+func IntRange(name string, min, max int64) {
+	IntRangeOnFlagSet(name, min, max, pflag.CommandLine)
+}
+
+// IntRangeOnFlagSet limits an integer flag to the range from min to max,
+// inclusive, and shows the range in its usage, like IntRange("port", 1, 65535).
+// It must be called before the call to envy.Parse().
+func IntRangeOnFlagSet(name string, min, max int64, fs *pflag.FlagSet) {
+	std.on("", fs).IntRange(name, min, max)
+}
+
+// IntRange limits an integer flag to a range, see IntRangeOnFlagSet.
+func (e *Envy) IntRange(name string, min, max int64) {
+	e.constrain(name, fmt.Sprintf("%d to %d", min, max), func(val string) error {
+		n, err := strconv.ParseInt(strings.TrimSpace(val), 0, 64)
+		if err != nil {
+			// Left for the flag itself to reject.
+			return nil
+		}
+		if n < min || n > max {
+			return fmt.Errorf("%w: %d must be from %d to %d", ErrOutOfRange, n, min, max)
+		}
+		return nil
+	})
+}
+
+// MatchRegexp limits a flag in the default pflag.CommandLine to values
+// matching a pattern, see MatchRegexpOnFlagSet.
+func MatchRegexp(name, pattern string) {
+	MatchRegexpOnFlagSet(name, pattern, pflag.CommandLine)
+}
+
+// MatchRegexpOnFlagSet limits a flag to values matching a regular expression,
+// like MatchRegexp("name", `^[a-z-]+$`), and shows the pattern in its usage.
+// Anchor the pattern to match the whole value. It must be called before the
+// call to envy.Parse().
+func MatchRegexpOnFlagSet(name, pattern string, fs *pflag.FlagSet) {
+	std.on("", fs).MatchRegexp(name, pattern)
+}
+
+// MatchRegexp limits a flag to values matching a pattern, see
+// MatchRegexpOnFlagSet.
+func (e *Envy) MatchRegexp(name, pattern string) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		e.fail(&SetError{Flag: name, Err: err})
+		return
+	}
+	e.constrain(name, "matching "+pattern, func(val string) error {
+		if re.MatchString(val) {
+			return nil
+		}
+		return fmt.Errorf("%w: %q must match %s", ErrNoMatch, val, pattern)
+	})
+}
+
+// constrain adds a check to the flag, describing it at the end of its usage.
+func (e *Envy) constrain(name, desc string, check func(string) error) {
+	f := e.fs.Lookup(name)
+	if f == nil {
+		e.fail(&SetError{Flag: name, Err: ErrFlagNotExists})
+		return
+	}
+	v, ok := wrapped[*checkedValue](f)
+	if !ok {
+		v = &checkedValue{Value: f.Value}
+		f.Value = wrap(v)
+	}
+	v.checks = append(v.checks, check)
+	if desc != "" {
+		f.Usage = fmt.Sprintf("%s (%s)", f.Usage, desc)
+	}
+}
@@ -0,0 +1,122 @@
+package envy_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/fernferret/envy"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+)
+
+func newConstrainedFlagSet() *pflag.FlagSet {
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	fs.String("log-level", "info", "log level")
+	fs.Int("port", 8080, "port to listen on")
+	fs.String("name", "app", "service name")
+	envy.OneOfOnFlagSet("log-level", fs, "debug", "info", "warn")
+	envy.IntRangeOnFlagSet("port", 1, 65535, fs)
+	envy.MatchRegexpOnFlagSet("name", `^[a-z-]+$`, fs)
+	return fs
+}
+
+func TestConstraints(t *testing.T) {
+	tests := []struct {
+		name   string
+		env    envy.MapLookuper
+		err    error
+		errMsg string
+	}{
+		{"valid", envy.MapLookuper{"FOO_LOG_LEVEL": "warn", "FOO_PORT": "443", "FOO_NAME": "my-app"}, nil, ""},
+		{"one of", envy.MapLookuper{"FOO_LOG_LEVEL": "trace"}, envy.ErrNotOneOf,
+			`--log-level from FOO_LOG_LEVEL: value is not one of the allowed values: "trace" must be one of debug, info, warn`},
+		{"range", envy.MapLookuper{"FOO_PORT": "70000"}, envy.ErrOutOfRange,
+			"--port from FOO_PORT: value is out of range: 70000 must be from 1 to 65535"},
+		{"regexp", envy.MapLookuper{"FOO_NAME": "My App"}, envy.ErrNoMatch,
+			`--name from FOO_NAME: value does not match the pattern: "My App" must match ^[a-z-]+$`},
+		{"not a number", envy.MapLookuper{"FOO_PORT": "http"}, nil,
+			`--port from FOO_PORT: strconv.ParseInt: parsing "http": invalid syntax`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fs := newConstrainedFlagSet()
+			err := envy.New(envy.WithFlagSet(fs), envy.WithPrefix("FOO"), envy.WithLookuper(test.env)).ParseE()
+			if test.errMsg == "" {
+				assert.NoError(t, err)
+				return
+			}
+			if test.err != nil {
+				assert.ErrorIs(t, err, test.err)
+			}
+			assert.EqualError(t, err, test.errMsg)
+		})
+	}
+}
+
+func TestConstraintsCommandLine(t *testing.T) {
+	fs := newConstrainedFlagSet()
+	assert.NoError(t, envy.New(envy.WithFlagSet(fs), envy.WithPrefix("FOO"), envy.WithLookuper(envy.MapLookuper{})).ParseE())
+	assert.ErrorContains(t, fs.Parse([]string{"--port=0"}), "0 must be from 1 to 65535")
+	assert.NoError(t, fs.Parse([]string{"--port=1", "--log-level=debug"}))
+	assert.Equal(t, "1", fs.Lookup("port").Value.String())
+	assert.Equal(t, "int", fs.Lookup("port").Value.Type())
+}
+
+func TestConstraintsUsage(t *testing.T) {
+	fs := newConstrainedFlagSet()
+	assert.NoError(t, envy.New(envy.WithFlagSet(fs), envy.WithPrefix("FOO"), envy.WithLookuper(envy.MapLookuper{})).ParseE())
+	assert.Equal(t, "log level (one of debug, info, warn) [FOO_LOG_LEVEL]", fs.Lookup("log-level").Usage)
+	assert.Equal(t, "port to listen on (1 to 65535) [FOO_PORT]", fs.Lookup("port").Usage)
+	assert.Equal(t, "service name (matching ^[a-z-]+$) [FOO_NAME]", fs.Lookup("name").Usage)
+	assert.Equal(t, "port to listen on (1 to 65535)", envy.SchemaFlagSet(fs)[2].Usage)
+}
+
+func TestValidate(t *testing.T) {
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	tags := fs.StringSlice("tags", nil, "tags")
+	envy.ValidateOnFlagSet("tags", func(val string) error {
+		if strings.Contains(val, " ") {
+			return errors.New("tags can't contain spaces")
+		}
+		return nil
+	}, fs)
+	envy.IntRangeOnFlagSet("tags", 0, 1, fs)
+
+	env := envy.MapLookuper{"FOO_TAGS": "a,b"}
+	assert.NoError(t, envy.New(envy.WithFlagSet(fs), envy.WithPrefix("FOO"), envy.WithLookuper(env)).ParseE())
+	assert.Equal(t, []string{"a", "b"}, *tags)
+	assert.Equal(t, "FOO_TAGS=a,b", envy.EnvironFlagSet(fs)[len(envy.EnvironFlagSet(fs))-1])
+	assert.EqualError(t, fs.Parse([]string{"--tags=a b"}), `invalid argument "a b" for "--tags" flag: tags can't contain spaces`)
+
+	assert.Panics(t, func() {
+		envy.MatchRegexpOnFlagSet("tags", "[", fs)
+	})
+	assert.Panics(t, func() {
+		envy.OneOfOnFlagSet("missing", fs, "a")
+	})
+}
+
+func TestConstraintsKeepInterfaces(t *testing.T) {
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	fs.Bool("verbose", false, "be verbose")
+	tags := fs.StringSlice("tags", nil, "tags")
+	envy.OneOfOnFlagSet("verbose", fs, "true", "false")
+	envy.OneOfOnFlagSet("tags", fs, "a", "b")
+
+	b, ok := fs.Lookup("verbose").Value.(interface{ IsBoolFlag() bool })
+	assert.True(t, ok && b.IsBoolFlag())
+	assert.NoError(t, fs.Parse([]string{"--verbose"}))
+
+	s, ok := fs.Lookup("tags").Value.(pflag.SliceValue)
+	if !ok {
+		t.Fatal("expected a pflag.SliceValue")
+	}
+	assert.ErrorIs(t, s.Replace([]string{"a", "c"}), envy.ErrNotOneOf)
+	assert.ErrorIs(t, s.Append("c"), envy.ErrNotOneOf)
+	assert.NoError(t, s.Replace([]string{"a", "b"}))
+	assert.Equal(t, []string{"a", "b"}, *tags)
+
+	err := envy.New(envy.WithFlagSet(fs), envy.WithPrefix("FOO"), envy.WithLookuper(envy.MapLookuper{"FOO_TAGS": "b,c"})).ParseE()
+	assert.ErrorIs(t, err, envy.ErrNotOneOf)
+}
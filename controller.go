@@ -0,0 +1,39 @@
+package envy
+
+import "github.com/spf13/pflag"
+
+// ControllerOptions holds the values set by the flags from ControllerFlags,
+// named after the fields of controller-runtime's manager.Options they feed.
+type ControllerOptions struct {
+	MetricsBindAddress     string
+	HealthProbeBindAddress string
+	LeaderElection         bool
+}
+
+// ControllerFlags defines the flags of a kubebuilder operator scaffold on the
+// default pflag.CommandLine, see ControllerFlagsOnFlagSet.
+func ControllerFlags() *ControllerOptions {
+	return ControllerFlagsOnFlagSet(pflag.CommandLine)
+}
+
+// ControllerFlagsOnFlagSet defines --metrics-bind-address,
+// --health-probe-bind-address and --leader-elect on the given FlagSet with the
+// same defaults as a kubebuilder scaffold, which envy binds like any other flag
+// so MYAPP_LEADER_ELECT=true works too. envy doesn't depend on
+// controller-runtime, copy the values into manager.Options once parsed:
+//
+//	opts := envy.ControllerFlags()
+//	envy.Parse("MYAPP")
+//	pflag.Parse()
+//	mgr, err := ctrl.NewManager(cfg, ctrl.Options{
+//		Metrics:                server.Options{BindAddress: opts.MetricsBindAddress},
+//		HealthProbeBindAddress: opts.HealthProbeBindAddress,
+//		LeaderElection:         opts.LeaderElection,
+//	})
+func ControllerFlagsOnFlagSet(fs *pflag.FlagSet) *ControllerOptions {
+	o := &ControllerOptions{}
+	fs.StringVar(&o.MetricsBindAddress, "metrics-bind-address", ":8080", "address the metrics endpoint binds to, 0 disables it")
+	fs.StringVar(&o.HealthProbeBindAddress, "health-probe-bind-address", ":8081", "address the health probe endpoint binds to")
+	fs.BoolVar(&o.LeaderElection, "leader-elect", false, "enable leader election, ensuring only one active controller manager")
+	return o
+}
@@ -0,0 +1,28 @@
+package envy_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/fernferret/envy"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestControllerFlags(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("MYAPP_LEADER_ELECT", "true")
+	os.Setenv("MYAPP_METRICS_BIND_ADDRESS", "0")
+	pflag.CommandLine = pflag.NewFlagSet("test", pflag.PanicOnError)
+
+	opts := envy.ControllerFlags()
+	envy.Parse("MYAPP")
+	pflag.CommandLine.Parse([]string{"--health-probe-bind-address=:9440"})
+
+	assert.Equal(t, &envy.ControllerOptions{
+		MetricsBindAddress:     "0",
+		HealthProbeBindAddress: ":9440",
+		LeaderElection:         true,
+	}, opts)
+	assert.Equal(t, "enable leader election, ensuring only one active controller manager [MYAPP_LEADER_ELECT true]", pflag.Lookup("leader-elect").Usage)
+}
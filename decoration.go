@@ -0,0 +1,86 @@
+package envy
+
+import (
+	"fmt"
+
+	"github.com/spf13/pflag"
+)
+
+// Decoration controls how much envy adds to the usage text of each flag.
+type Decoration int
+
+const (
+	// DecorationFull appends the environment variable and its value, if set.
+	DecorationFull Decoration = iota
+
+	// DecorationMinimal appends only the environment variable name.
+	DecorationMinimal
+
+	// DecorationNone leaves the usage text untouched.
+	DecorationNone
+)
+
+// Catalog provides the text envy adds to a flag's usage. Internationalized
+// CLIs can supply their own to translate the decoration consistently with the
+// rest of their help output.
+type Catalog interface {
+	// Unset decorates the usage of a flag whose environment variable isn't
+	// set, or whose value shouldn't be shown.
+	Unset(usage, envName string) string
+
+	// Set decorates the usage of a flag whose environment variable is set.
+	Set(usage, envName, value string) string
+}
+
+// SetDecoration controls how envy modifies the usage text of the flags it
+// binds. Programs that publish their own documentation can use DecorationNone
+// to bind environment variables without touching the help output at all. It
+// must be called before the call to envy.Parse().
+func SetDecoration(d Decoration) {
+	std.decoration = d
+}
+
+// SetCatalog replaces the text envy uses to decorate usage, passing nil
+// restores the default. It must be called before the call to envy.Parse().
+func SetCatalog(c Catalog) {
+	if c == nil {
+		c = defaultCatalog{}
+	}
+	std.catalog = c
+}
+
+// defaultCatalog produces usage like "set the url [FOO_URL http://127.0.0.1]".
+type defaultCatalog struct{}
+
+func (defaultCatalog) Unset(usage, envName string) string {
+	return fmt.Sprintf("%s [%s]", usage, envName)
+}
+
+func (defaultCatalog) Set(usage, envName, value string) string {
+	return fmt.Sprintf("%s [%s %s]", usage, envName, value)
+}
+
+// decorate updates the usage of the flag according to the current decoration.
+// It always starts from the original usage, so parsing again doesn't decorate
+// it twice.
+func (e *Envy) decorate(f *pflag.Flag, envName, val string, set bool) {
+	if _, ok := f.Annotations[AnnotationUsage]; !ok {
+		annotate(f, AnnotationUsage, f.Usage)
+	}
+	f.Usage = usageOf(f)
+	switch {
+	case e.decoration == DecorationNone:
+		return
+	case !set || e.decoration == DecorationMinimal:
+		f.Usage = e.catalog.Unset(f.Usage, envName)
+	case isSecret(f):
+		// Never leak secrets into the help text.
+		f.Usage = e.catalog.Unset(f.Usage, envName)
+	case isHashed(f):
+		f.Usage = e.catalog.Set(f.Usage, envName, hashValue(val))
+	case isRedactor(f):
+		f.Usage = e.catalog.Set(f.Usage, envName, valueOf(f).(Redactor).Redacted())
+	default:
+		f.Usage = e.catalog.Set(f.Usage, envName, val)
+	}
+}
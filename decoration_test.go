@@ -0,0 +1,80 @@
+package envy_test
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/fernferret/envy"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetDecoration(t *testing.T) {
+	defer envy.SetDecoration(envy.DecorationFull)
+
+	tests := []struct {
+		name       string
+		decoration envy.Decoration
+		exp        string
+	}{
+		{
+			name:       "test full decoration",
+			decoration: envy.DecorationFull,
+			exp:        "set the url [FOO_URL http://127.0.0.1]",
+		},
+		{
+			name:       "test minimal decoration",
+			decoration: envy.DecorationMinimal,
+			exp:        "set the url [FOO_URL]",
+		},
+		{
+			name:       "test no decoration",
+			decoration: envy.DecorationNone,
+			exp:        "set the url",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv("FOO_URL", "http://127.0.0.1")
+			pflag.CommandLine = pflag.NewFlagSet("test", pflag.PanicOnError)
+
+			pflag.String("url", "", "set the url")
+
+			envy.SetDecoration(tt.decoration)
+			envy.Parse("FOO")
+
+			flag := pflag.Lookup("url")
+			assert.Equal(t, tt.exp, flag.Usage)
+			assert.Equal(t, "http://127.0.0.1", flag.Value.String())
+		})
+	}
+}
+
+type germanCatalog struct{}
+
+func (germanCatalog) Unset(usage, envName string) string {
+	return fmt.Sprintf("%s [Umgebung: %s]", usage, envName)
+}
+
+func (germanCatalog) Set(usage, envName, value string) string {
+	return fmt.Sprintf("%s [Umgebung: %s=%s]", usage, envName, value)
+}
+
+func TestSetCatalog(t *testing.T) {
+	defer envy.SetCatalog(nil)
+
+	os.Clearenv()
+	os.Setenv("FOO_URL", "http://127.0.0.1")
+	pflag.CommandLine = pflag.NewFlagSet("test", pflag.PanicOnError)
+
+	pflag.String("url", "", "die URL")
+	pflag.Bool("once", false, "nur einmal")
+
+	envy.SetCatalog(germanCatalog{})
+	envy.Parse("FOO")
+
+	assert.Equal(t, "die URL [Umgebung: FOO_URL=http://127.0.0.1]", pflag.Lookup("url").Usage)
+	assert.Equal(t, "nur einmal [Umgebung: FOO_ONCE]", pflag.Lookup("once").Usage)
+}
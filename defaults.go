@@ -0,0 +1,118 @@
+package envy
+
+import (
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+// Used to keep the default a flag was defined with in code once the
+// environment supplies its default, see SetEnvAsDefault and StringVarE.
+const AnnotationCodeDefault = "envy_code_default"
+
+// SetEnvAsDefault makes values read by Parse from the environment become the
+// flags' defaults instead of overriding them, so --help shows them as the
+// default like flags defined with StringVarE. Flags set this way count as using
+// their default, so PrintSummary, SourcesFlagSet and friends don't report them
+// as set from the environment, while SchemaFlagSet and lock files keep the
+// default from code so they don't depend on the host. It must be called before
+// the call to envy.Parse().
+func SetEnvAsDefault(on bool) {
+	std.envAsDefault = on
+}
+
+// StringVarE defines a string flag whose default comes from the environment
+// variable envName when it's set, falling back to value. Unlike Parse, the
+// variable changes the default itself so --help shows it as the default, and
+// the flag counts as using its default rather than being set from the
+// environment. SchemaFlagSet and lock files keep value as the default.
+func StringVarE(fs *pflag.FlagSet, p *string, name, envName, value, usage string) {
+	fs.StringVar(p, name, value, usage)
+	defaultFromEnv(fs, name, envName)
+}
+
+// BoolVarE defines a bool flag whose default comes from the environment, see
+// StringVarE. Like Parse, values other than true or false panic, or are
+// returned by ParseE.
+func BoolVarE(fs *pflag.FlagSet, p *bool, name, envName string, value bool, usage string) {
+	fs.BoolVar(p, name, value, usage)
+	defaultFromEnv(fs, name, envName)
+}
+
+// IntVarE defines an int flag whose default comes from the environment, see
+// StringVarE.
+func IntVarE(fs *pflag.FlagSet, p *int, name, envName string, value int, usage string) {
+	fs.IntVar(p, name, value, usage)
+	defaultFromEnv(fs, name, envName)
+}
+
+// Float64VarE defines a float64 flag whose default comes from the environment,
+// see StringVarE.
+func Float64VarE(fs *pflag.FlagSet, p *float64, name, envName string, value float64, usage string) {
+	fs.Float64Var(p, name, value, usage)
+	defaultFromEnv(fs, name, envName)
+}
+
+// DurationVarE defines a time.Duration flag whose default comes from the
+// environment, see StringVarE.
+func DurationVarE(fs *pflag.FlagSet, p *time.Duration, name, envName string, value time.Duration, usage string) {
+	fs.DurationVar(p, name, value, usage)
+	defaultFromEnv(fs, name, envName)
+}
+
+// StringSliceVarE defines a string slice flag whose default comes from the
+// comma separated environment variable, see StringVarE.
+func StringSliceVarE(fs *pflag.FlagSet, p *[]string, name, envName string, value []string, usage string) {
+	fs.StringSliceVar(p, name, value, usage)
+	defaultFromEnv(fs, name, envName)
+}
+
+// defaultFromEnv sets the flag and its DefValue from the environment variable.
+// The variable is also recorded as the flag's custom name, so a later Parse
+// reads the same one and treats it as the default too.
+func defaultFromEnv(fs *pflag.FlagSet, name, envName string) {
+	f := fs.Lookup(name)
+	envName = envKey(envName)
+	annotate(f, AnnotationCustom, envName)
+	annotate(f, AnnotationCodeDefault, f.DefValue)
+
+	val, ok := std.lookuper.Lookup(envName)
+	if !ok {
+		return
+	}
+	norm, err := std.normalize(f, val)
+	if err == nil {
+		err = setDefault(f, norm)
+	}
+	if err != nil {
+		std.on("", fs).fail(valueError(f, envName, val, err))
+	}
+}
+
+// setDefault sets the flag to the value and makes it the flag's default,
+// keeping the default from code for codeDefault.
+func setDefault(f *pflag.Flag, val string) error {
+	if err := setValue(f, val); err != nil {
+		return err
+	}
+	if !envDefault(f) {
+		annotate(f, AnnotationCodeDefault, f.DefValue)
+	}
+	f.DefValue = f.Value.String()
+	return nil
+}
+
+// envDefault reports whether the flag's default may come from the environment.
+func envDefault(f *pflag.Flag) bool {
+	_, ok := f.Annotations[AnnotationCodeDefault]
+	return ok
+}
+
+// codeDefault returns the default the flag was defined with, ignoring any
+// default from the environment.
+func codeDefault(f *pflag.Flag) string {
+	if def, ok := f.Annotations[AnnotationCodeDefault]; ok {
+		return def[0]
+	}
+	return f.DefValue
+}
@@ -0,0 +1,121 @@
+package envy_test
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/fernferret/envy"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVarE(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("MYAPP_URL", "http://example.com")
+	os.Setenv("MYAPP_VERBOSE", "true")
+	os.Setenv("MYAPP_INTERVAL", "90s")
+	os.Setenv("MYAPP_TAGS", "a,b")
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+
+	var (
+		url      string
+		verbose  bool
+		workers  int
+		ratio    float64
+		interval time.Duration
+		tags     []string
+	)
+	envy.StringVarE(fs, &url, "url", "MYAPP_URL", "http://localhost", "set the url")
+	envy.BoolVarE(fs, &verbose, "verbose", "myapp-verbose", false, "verbose output")
+	envy.IntVarE(fs, &workers, "workers", "MYAPP_WORKERS", 4, "number of workers")
+	envy.Float64VarE(fs, &ratio, "ratio", "MYAPP_RATIO", 0.5, "sample ratio")
+	envy.DurationVarE(fs, &interval, "interval", "MYAPP_INTERVAL", time.Minute, "check interval")
+	envy.StringSliceVarE(fs, &tags, "tags", "MYAPP_TAGS", nil, "tags")
+
+	assert.Equal(t, "http://example.com", url)
+	assert.True(t, verbose)
+	assert.Equal(t, 4, workers)
+	assert.Equal(t, 0.5, ratio)
+	assert.Equal(t, 90*time.Second, interval)
+	assert.Equal(t, []string{"a", "b"}, tags)
+
+	// The environment is the default, so nothing counts as changed
+	assert.Equal(t, "http://example.com", fs.Lookup("url").DefValue)
+	assert.Equal(t, "1m30s", fs.Lookup("interval").DefValue)
+	assert.False(t, fs.Lookup("url").Changed)
+
+	// The flags use their default, and the schema keeps the one from code
+	assert.Equal(t, envy.FromDefault, envy.SourcesFlagSet(fs)[3].Origin)
+	assert.NotContains(t, fs.Lookup("url").Annotations, envy.AnnotationSource)
+	assert.Equal(t, "http://localhost", envy.SchemaFlagSet(fs)[3].Default)
+
+	// A later Parse reads the same variables
+	envy.ParseFlagSet("OTHER", fs)
+	assert.Equal(t, "set the url [MYAPP_URL http://example.com]", fs.Lookup("url").Usage)
+	assert.Equal(t, []string{"a", "b"}, tags)
+
+	// The command line still wins
+	assert.NoError(t, fs.Parse([]string{"--url=http://flag"}))
+	assert.Equal(t, "http://flag", url)
+}
+
+func TestVarEInvalid(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("MYAPP_VERBOSE", "yes")
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+
+	var verbose bool
+	assert.PanicsWithError(t, "--verbose from MYAPP_VERBOSE: "+envy.ErrInvalidBoolFlagValue.Error(), func() {
+		envy.BoolVarE(fs, &verbose, "verbose", "MYAPP_VERBOSE", false, "verbose output")
+	})
+}
+
+func TestVarEReturnErrors(t *testing.T) {
+	envy.SetStrictness(envy.ReturnErrors)
+	defer envy.SetStrictness(envy.PanicOnErrors)
+
+	os.Clearenv()
+	os.Setenv("MYAPP_VERBOSE", "yes")
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+
+	var verbose bool
+	envy.BoolVarE(fs, &verbose, "verbose", "MYAPP_VERBOSE", false, "verbose output")
+	assert.False(t, verbose)
+	assert.EqualError(t, envy.ParseFlagSetE("MYAPP", fs), "--verbose from MYAPP_VERBOSE: "+envy.ErrInvalidBoolFlagValue.Error())
+}
+
+func TestSetEnvAsDefault(t *testing.T) {
+	envy.SetEnvAsDefault(true)
+	defer envy.SetEnvAsDefault(false)
+
+	os.Clearenv()
+	os.Setenv("FOO_URL", "http://example.com")
+	os.Setenv("FOO_INTERVAL", "90s")
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	fs.String("url", "http://localhost", "set the url")
+	fs.Duration("interval", time.Minute, "check interval")
+	fs.Int("workers", 4, "number of workers")
+
+	envy.ParseFlagSet("FOO", fs)
+	assert.NoError(t, fs.Parse(nil))
+
+	assert.Equal(t, "http://example.com", fs.Lookup("url").Value.String())
+	assert.Equal(t, "http://example.com", fs.Lookup("url").DefValue)
+	assert.Equal(t, "1m30s", fs.Lookup("interval").DefValue)
+	assert.Equal(t, "4", fs.Lookup("workers").DefValue)
+	assert.False(t, fs.Lookup("url").Changed)
+
+	// Nothing counts as set from the environment, and the schema, and so the
+	// lock file, doesn't depend on it
+	assert.Equal(t, envy.FromDefault, envy.SourcesFlagSet(fs)[1].Origin)
+	assert.NotContains(t, fs.Lookup("url").Annotations, envy.AnnotationSource)
+	assert.Equal(t, "http://localhost", envy.SchemaFlagSet(fs)[1].Default)
+	assert.Equal(t, "1m0s", envy.SchemaFlagSet(fs)[0].Default)
+
+	// Instances don't follow the package setting
+	fs = pflag.NewFlagSet("test", pflag.ContinueOnError)
+	fs.String("url", "http://localhost", "set the url")
+	envy.New(envy.WithPrefix("FOO"), envy.WithFlagSet(fs)).Parse()
+	assert.Equal(t, "http://localhost", fs.Lookup("url").DefValue)
+}
@@ -0,0 +1,84 @@
+package envy
+
+import "github.com/spf13/pflag"
+
+// Used to record pairs of deprecated and replacement environment variables.
+const AnnotationDeprecated = "envy_deprecated"
+
+// DeprecationHandler is called by Parse when a flag's value was read from a
+// deprecated environment variable.
+type DeprecationHandler func(flagName, oldName, newName string)
+
+// defaultDeprecationHandler logs a warning, see SetOutput.
+func defaultDeprecationHandler(flagName, oldName, newName string) {
+	logf("%s is deprecated, set %s instead", oldName, newName)
+}
+
+// SetDeprecationHandler replaces what Parse does when a deprecated environment
+// variable is used, passing nil restores the default of logging a warning. It
+// must be called before the call to envy.Parse().
+func SetDeprecationHandler(fn DeprecationHandler) {
+	if fn == nil {
+		fn = defaultDeprecationHandler
+	}
+	std.deprecated = fn
+}
+
+// DeprecateEnvName keeps reading a flag in the default pflag.CommandLine from
+// an old environment variable, see DeprecateEnvNameOnFlagSet.
+func DeprecateEnvName(name, oldName, newName string) {
+	DeprecateEnvNameOnFlagSet(name, oldName, newName, pflag.CommandLine)
+}
+
+// DeprecateEnvNameOnFlagSet keeps reading a flag from oldName after its
+// variable was renamed to newName, calling the DeprecationHandler whenever the
+// old one supplies the value so operators know to switch. newName wins if both
+// are set. Unlike SetEnvName it can be called several times for the same flag,
+// but must be called after any SetEnvName and before the call to envy.Parse().
+func DeprecateEnvNameOnFlagSet(name, oldName, newName string, fs *pflag.FlagSet) {
+	std.on("", fs).DeprecateEnvName(name, oldName, newName)
+}
+
+// DeprecateEnvName keeps reading a flag from an old environment variable, see
+// DeprecateEnvNameOnFlagSet.
+func (e *Envy) DeprecateEnvName(name, oldName, newName string) {
+	f := e.fs.Lookup(name)
+	if f == nil {
+		e.fail(&SetError{Flag: name, Err: ErrFlagNotExists})
+		return
+	}
+	deprecateEnvName(f, oldName, newName)
+}
+
+// deprecateEnvName adds oldName to the flag's variables, after newName.
+func deprecateEnvName(f *pflag.Flag, oldName, newName string) {
+	oldName, newName = envKey(oldName), envKey(newName)
+
+	names := f.Annotations[AnnotationCustom]
+	if !contains(names, newName) {
+		names = append(names, newName)
+	}
+	annotate(f, AnnotationCustom, append(names, oldName)...)
+	annotate(f, AnnotationDeprecated, append(f.Annotations[AnnotationDeprecated], oldName, newName)...)
+}
+
+// replacementFor returns the variable that replaced a deprecated one.
+func replacementFor(f *pflag.Flag, envName string) (string, bool) {
+	pairs := f.Annotations[AnnotationDeprecated]
+	for i := 0; i+1 < len(pairs); i += 2 {
+		if pairs[i] == envName {
+			return pairs[i+1], true
+		}
+	}
+	return "", false
+}
+
+// contains reports whether the list holds the item.
+func contains(list []string, item string) bool {
+	for _, v := range list {
+		if v == item {
+			return true
+		}
+	}
+	return false
+}
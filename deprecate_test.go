@@ -0,0 +1,82 @@
+package envy_test
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/fernferret/envy"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeprecateEnvName(t *testing.T) {
+	defer envy.SetOutput(os.Stderr)
+
+	tests := []struct {
+		name  string
+		env   map[string]string
+		value string
+		log   string
+	}{
+		{
+			name:  "test new name",
+			env:   map[string]string{"FOO_DB_URL": "postgres://new"},
+			value: "postgres://new",
+		},
+		{
+			name:  "test old name",
+			env:   map[string]string{"FOO_DATABASE": "postgres://old"},
+			value: "postgres://old",
+			log:   "envy: FOO_DATABASE is deprecated, set FOO_DB_URL instead\n",
+		},
+		{
+			name:  "test older name",
+			env:   map[string]string{"DATABASE_URL": "postgres://older"},
+			value: "postgres://older",
+			log:   "envy: DATABASE_URL is deprecated, set FOO_DB_URL instead\n",
+		},
+		{
+			name:  "test new name wins",
+			env:   map[string]string{"FOO_DB_URL": "postgres://new", "FOO_DATABASE": "postgres://old"},
+			value: "postgres://new",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			os.Clearenv()
+			for k, v := range tt.env {
+				os.Setenv(k, v)
+			}
+			buf := &bytes.Buffer{}
+			envy.SetOutput(buf)
+			pflag.CommandLine = pflag.NewFlagSet("test", pflag.PanicOnError)
+			pflag.String("db-url", "", "database url")
+
+			envy.DeprecateEnvName("db-url", "foo-database", "FOO_DB_URL")
+			envy.DeprecateEnvName("db-url", "DATABASE_URL", "FOO_DB_URL")
+			envy.Parse("FOO")
+			assert.Equal(t, tt.value, pflag.Lookup("db-url").Value.String())
+			assert.Equal(t, tt.log, buf.String())
+		})
+	}
+}
+
+func TestSetDeprecationHandler(t *testing.T) {
+	defer envy.SetDeprecationHandler(nil)
+
+	os.Clearenv()
+	os.Setenv("KUBE_CONFIG", "/old")
+	pflag.CommandLine = pflag.NewFlagSet("test", pflag.PanicOnError)
+	pflag.String("kube-config", "", "kube config")
+
+	var got []string
+	envy.SetDeprecationHandler(func(flagName, oldName, newName string) {
+		got = append(got, flagName, oldName, newName)
+	})
+	envy.SetEnvName("kube-config", "KUBECONFIG")
+	envy.DeprecateEnvName("kube-config", "KUBE_CONFIG", "KUBECONFIG")
+	envy.Parse("FOO")
+	assert.Equal(t, []string{"kube-config", "KUBE_CONFIG", "KUBECONFIG"}, got)
+	assert.Equal(t, "kube config [KUBE_CONFIG /old]", pflag.Lookup("kube-config").Usage)
+}
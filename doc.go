@@ -0,0 +1,75 @@
+package envy
+
+import "github.com/spf13/pflag"
+
+const (
+	// Used to record an example value for generated documentation.
+	AnnotationDocExample = "envy_doc_example"
+
+	// Used to record the version that introduced a flag.
+	AnnotationDocSince = "envy_doc_since"
+
+	// Used to record a link to further documentation for a flag.
+	AnnotationDocLink = "envy_doc_link"
+)
+
+// DocOption adds documentation metadata to a flag, see Doc.
+type DocOption func(f *pflag.Flag)
+
+// DocExample gives an example value, like "10m".
+func DocExample(example string) DocOption {
+	return func(f *pflag.Flag) {
+		annotate(f, AnnotationDocExample, example)
+	}
+}
+
+// DocSince records the version that introduced the flag, like "v1.3".
+func DocSince(version string) DocOption {
+	return func(f *pflag.Flag) {
+		annotate(f, AnnotationDocSince, version)
+	}
+}
+
+// DocLink links to further documentation about the flag.
+func DocLink(url string) DocOption {
+	return func(f *pflag.Flag) {
+		annotate(f, AnnotationDocLink, url)
+	}
+}
+
+// Doc adds documentation metadata to a flag in the default pflag.CommandLine,
+// see DocOnFlagSet.
+func Doc(name string, opts ...DocOption) {
+	DocOnFlagSet(name, pflag.CommandLine, opts...)
+}
+
+// DocOnFlagSet adds documentation metadata beyond the usage string to a flag,
+// which the Schema includes so generated documentation can be richer:
+//
+//	envy.Doc("interval", envy.DocExample("10m"), envy.DocSince("v1.3"), envy.DocLink("https://example.com/interval"))
+//
+// It can be called any time before the docs are generated.
+func DocOnFlagSet(name string, fs *pflag.FlagSet, opts ...DocOption) {
+	std.on("", fs).Doc(name, opts...)
+}
+
+// Doc adds documentation metadata to a flag, see DocOnFlagSet.
+func (e *Envy) Doc(name string, opts ...DocOption) {
+	f := e.fs.Lookup(name)
+	if f == nil {
+		e.fail(&SetError{Flag: name, Err: ErrFlagNotExists})
+		return
+	}
+	for _, opt := range opts {
+		opt(f)
+	}
+}
+
+// docOf returns the flag's documentation metadata by annotation key, empty if
+// it has none.
+func docOf(f *pflag.Flag, key string) string {
+	if val, ok := f.Annotations[key]; ok {
+		return val[0]
+	}
+	return ""
+}
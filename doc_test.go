@@ -0,0 +1,31 @@
+package envy_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/fernferret/envy"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDoc(t *testing.T) {
+	os.Clearenv()
+	pflag.CommandLine = pflag.NewFlagSet("test", pflag.PanicOnError)
+	pflag.Duration("interval", 0, "poll interval")
+	envy.Doc("interval", envy.DocExample("10m"), envy.DocSince("v1.3"), envy.DocLink("https://example.com/interval"))
+	envy.Parse("FOO")
+
+	assert.Equal(t, []envy.Binding{{
+		Flag:     "interval",
+		Type:     "duration",
+		EnvNames: []string{"FOO_INTERVAL"},
+		Default:  "0s",
+		Usage:    "poll interval",
+		Example:  "10m",
+		Since:    "v1.3",
+		Link:     "https://example.com/interval",
+	}}, envy.Schema())
+
+	assert.Panics(t, func() { envy.Doc("missing", envy.DocSince("v1.3")) })
+}
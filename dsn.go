@@ -0,0 +1,103 @@
+package envy
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+// DSN is a database connection string that can either be given whole as a URL
+// or assembled from its parts, see DSNVar.
+type DSN struct {
+	Scheme   string
+	URL      string
+	Host     string
+	User     string
+	Password string
+	Name     string
+}
+
+// dsnURLValue is a string flag that redacts the password of the URL it holds.
+type dsnURLValue string
+
+func (v *dsnURLValue) Set(val string) error {
+	*v = dsnURLValue(val)
+	return nil
+}
+
+func (v *dsnURLValue) String() string {
+	return string(*v)
+}
+
+func (v *dsnURLValue) Type() string {
+	return "url"
+}
+
+func (v *dsnURLValue) Redacted() string {
+	return redactURL(string(*v))
+}
+
+// DSNVar defines a database URL flag and its component flags on the default
+// pflag.CommandLine, see DSNVarOnFlagSet.
+func DSNVar(d *DSN, name, scheme, usage string) {
+	DSNVarOnFlagSet(d, name, scheme, usage, pflag.CommandLine)
+}
+
+// DSNVarOnFlagSet defines a flag holding a full database URL along with flags
+// for each part of it, named after the URL flag without any "-url" suffix. For
+// "database-url" these are --database-host (which may include a port),
+// --database-user, --database-password and --database-name. The password
+// flag is marked with Secret and the password in the URL flag is redacted
+// anywhere envy prints it.
+func DSNVarOnFlagSet(d *DSN, name, scheme, usage string, fs *pflag.FlagSet) {
+	d.Scheme = scheme
+	base := strings.TrimSuffix(name, "-url")
+
+	fs.Var((*dsnURLValue)(&d.URL), name, usage+", overrides the --"+base+"-* flags")
+	fs.StringVar(&d.Host, base+"-host", d.Host, "database host[:port]")
+	fs.StringVar(&d.User, base+"-user", d.User, "database user")
+	fs.StringVar(&d.Password, base+"-password", d.Password, "database password")
+	fs.StringVar(&d.Name, base+"-name", d.Name, "database name")
+	SecretOnFlagSet(base+"-password", fs)
+}
+
+// String returns the URL if one was given, otherwise it's assembled from the
+// parts. It's empty if neither the URL nor a host was set.
+func (d *DSN) String() string {
+	if d.URL != "" {
+		return d.URL
+	}
+	if d.Host == "" {
+		return ""
+	}
+	u := &url.URL{
+		Scheme: d.Scheme,
+		Host:   d.Host,
+		Path:   "/" + d.Name,
+	}
+	if d.Password != "" {
+		u.User = url.UserPassword(d.User, d.Password)
+	} else if d.User != "" {
+		u.User = url.User(d.User)
+	}
+	return u.String()
+}
+
+// Redacted returns the DSN with any password replaced, safe for logging.
+func (d *DSN) Redacted() string {
+	return redactURL(d.String())
+}
+
+// redactURL hides the password in the URL, unparsable values are hidden
+// entirely since they may contain one.
+func redactURL(raw string) string {
+	if raw == "" {
+		return ""
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return redacted
+	}
+	return u.Redacted()
+}
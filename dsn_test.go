@@ -0,0 +1,74 @@
+package envy_test
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/fernferret/envy"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDSN(t *testing.T) {
+	tests := []struct {
+		name     string
+		dsn      envy.DSN
+		exp      string
+		redacted string
+	}{
+		{
+			name: "test empty",
+		},
+		{
+			name:     "test full url",
+			dsn:      envy.DSN{Scheme: "postgres", URL: "postgres://app:hunter2@db:5432/app", Host: "ignored"},
+			exp:      "postgres://app:hunter2@db:5432/app",
+			redacted: "postgres://app:xxxxx@db:5432/app",
+		},
+		{
+			name:     "test assembled",
+			dsn:      envy.DSN{Scheme: "postgres", Host: "db:5432", User: "app", Password: "p@ss", Name: "app"},
+			exp:      "postgres://app:p%40ss@db:5432/app",
+			redacted: "postgres://app:xxxxx@db:5432/app",
+		},
+		{
+			name:     "test assembled without password",
+			dsn:      envy.DSN{Scheme: "mysql", Host: "db", User: "app", Name: "app"},
+			exp:      "mysql://app@db/app",
+			redacted: "mysql://app@db/app",
+		},
+		{
+			name:     "test unparsable url",
+			dsn:      envy.DSN{URL: "postgres://app:%zz@db"},
+			exp:      "postgres://app:%zz@db",
+			redacted: "<redacted>",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.exp, tt.dsn.String())
+			assert.Equal(t, tt.redacted, tt.dsn.Redacted())
+		})
+	}
+}
+
+func TestDSNVar(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("FOO_DATABASE_URL", "postgres://app:hunter2@db/app")
+	os.Setenv("FOO_DATABASE_PASSWORD", "hunter2")
+	pflag.CommandLine = pflag.NewFlagSet("test", pflag.PanicOnError)
+
+	var dsn envy.DSN
+	envy.DSNVar(&dsn, "database-url", "postgres", "database to connect to")
+	envy.Parse("FOO")
+	pflag.Parse()
+
+	assert.Equal(t, "postgres://app:hunter2@db/app", dsn.String())
+	assert.Equal(t, "database to connect to, overrides the --database-* flags [FOO_DATABASE_URL postgres://app:xxxxx@db/app]", pflag.Lookup("database-url").Usage)
+	assert.Equal(t, "database password [FOO_DATABASE_PASSWORD]", pflag.Lookup("database-password").Usage)
+
+	buf := &bytes.Buffer{}
+	envy.PrintSummary(buf)
+	assert.NotContains(t, buf.String(), "hunter2")
+}
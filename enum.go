@@ -0,0 +1,105 @@
+package envy
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+var ErrInvalidEnumValue = errors.New("value is not one of the allowed choices")
+
+// Chooser is implemented by pflag values that only accept a fixed set of
+// values, allowing completion and documentation to list them, see Complete and
+// SchemaFlagSet.
+type Chooser interface {
+	Choices() []string
+}
+
+// Complete returns the choices of a flag in the default pflag.CommandLine
+// starting with toComplete, see CompleteFlagSet.
+func Complete(name, toComplete string) []string {
+	return CompleteFlagSet(name, toComplete, pflag.CommandLine)
+}
+
+// CompleteFlagSet returns the choices of a flag whose value implements
+// Chooser that start with toComplete, ignoring case, for shell completion.
+// With cobra:
+//
+//	cmd.RegisterFlagCompletionFunc("format", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+//		return envy.CompleteFlagSet("format", toComplete, cmd.Flags()), cobra.ShellCompDirectiveNoFileComp
+//	})
+func CompleteFlagSet(name, toComplete string, fs *pflag.FlagSet) []string {
+	var matches []string
+	for _, choice := range choicesOf(fs.Lookup(name)) {
+		if strings.HasPrefix(strings.ToLower(choice), strings.ToLower(toComplete)) {
+			matches = append(matches, choice)
+		}
+	}
+	return matches
+}
+
+// choicesOf returns the choices of the flag if its value implements Chooser.
+func choicesOf(f *pflag.Flag) []string {
+	if f == nil {
+		return nil
+	}
+	if c, ok := valueOf(f).(Chooser); ok {
+		return c.Choices()
+	}
+	return nil
+}
+
+// EnumValue is a pflag.Value restricted to a fixed set of choices, see Enum.
+type EnumValue[T ~string] struct {
+	value   T
+	allowed []T
+}
+
+// Enum returns a pflag.Value that only accepts the allowed choices, compared
+// case insensitively, defaulting to the first one. It panics if no choices are
+// given. Register it with FlagSet.Var and read it back with Get:
+//
+//	format := envy.Enum[Format]("text", "json")
+//	pflag.Var(format, "format", "output format")
+func Enum[T ~string](allowed ...T) *EnumValue[T] {
+	if len(allowed) == 0 {
+		panic(ErrInvalidEnumValue)
+	}
+	return &EnumValue[T]{value: allowed[0], allowed: allowed}
+}
+
+// Get returns the current choice.
+func (e *EnumValue[T]) Get() T {
+	return e.value
+}
+
+// Set changes the choice, storing it as spelled in the allowed list.
+func (e *EnumValue[T]) Set(val string) error {
+	for _, choice := range e.allowed {
+		if strings.EqualFold(string(choice), val) {
+			e.value = choice
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %q must be one of %s", ErrInvalidEnumValue, val, strings.Join(e.Choices(), ", "))
+}
+
+func (e *EnumValue[T]) String() string {
+	return string(e.value)
+}
+
+// Type lists the choices so they show up in the flag's help.
+func (e *EnumValue[T]) Type() string {
+	return strings.Join(e.Choices(), "|")
+}
+
+// Choices returns the allowed values as strings.
+func (e *EnumValue[T]) Choices() []string {
+	choices := make([]string, 0, len(e.allowed))
+	for _, choice := range e.allowed {
+		choices = append(choices, string(choice))
+	}
+	return choices
+}
@@ -0,0 +1,71 @@
+package envy_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/fernferret/envy"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+)
+
+type format string
+
+const (
+	formatText format = "text"
+	formatJSON format = "json"
+)
+
+func TestEnum(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("FOO_FORMAT", "JSON")
+	pflag.CommandLine = pflag.NewFlagSet("test", pflag.PanicOnError)
+
+	f := envy.Enum(formatText, formatJSON)
+	pflag.Var(f, "format", "output format")
+
+	assert.Equal(t, "text", pflag.Lookup("format").DefValue)
+
+	envy.Parse("FOO")
+	assert.Equal(t, formatJSON, f.Get())
+	assert.Equal(t, []string{"text", "json"}, f.Choices())
+
+	err := f.Set("yaml")
+	assert.ErrorIs(t, err, envy.ErrInvalidEnumValue)
+	assert.Equal(t, formatJSON, f.Get())
+}
+
+func TestEnumCompletionAndSchema(t *testing.T) {
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	fs.Var(envy.Enum("text", "json", "jsonl"), "format", "output format")
+	fs.String("url", "", "set the url")
+	envy.OneOfOnFlagSet("url", fs, "a", "b")
+	envy.ParseFlagSet("FOO", fs)
+
+	assert.Equal(t, []string{"json", "jsonl"}, envy.CompleteFlagSet("format", "J", fs))
+	assert.Equal(t, []string{"text", "json", "jsonl"}, envy.CompleteFlagSet("format", "", fs))
+	assert.Nil(t, envy.CompleteFlagSet("url", "", fs))
+	assert.Nil(t, envy.CompleteFlagSet("missing", "", fs))
+
+	schema := envy.SchemaFlagSet(fs)
+	assert.Equal(t, []string{"text", "json", "jsonl"}, schema[0].Choices)
+	assert.Nil(t, schema[1].Choices)
+}
+
+func TestEnumNoChoices(t *testing.T) {
+	assert.Panics(t, func() { envy.Enum[format]() })
+}
+
+func ExampleEnum() {
+	// Reset CommandLine flags for example, you don't need this in your code!
+	pflag.CommandLine = pflag.NewFlagSet("test", pflag.PanicOnError)
+	os.Clearenv()
+
+	pflag.Var(envy.Enum("text", "json", "yaml"), "format", "output format")
+
+	envy.Parse("COOL_APP")
+
+	pflag.CommandLine.SetOutput(os.Stdout)
+	pflag.PrintDefaults()
+	// Output: --format text|json|yaml   output format [COOL_APP_FORMAT] (default text)
+}
@@ -0,0 +1,161 @@
+package envy
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+var ErrInvalidEnvFile = errors.New("expected KEY=VALUE")
+
+// ReadEnvFile reads variables from a file in the format systemd's
+// EnvironmentFile= uses, like /etc/default/myapp, see ParseEnvFile.
+func ReadEnvFile(path string) (MapLookuper, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	env, err := parseEnvFile(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("%s:%w", path, err)
+	}
+	return env, nil
+}
+
+// ParseEnvFile reads variables in the format systemd's EnvironmentFile= uses.
+// Lines starting with # or ; are comments, and each other line is KEY=VALUE
+// with whitespace around both ignored. Values may be wrapped in single quotes,
+// taken literally, or double quotes, where \", \\, \$ and \` are unescaped. A
+// backslash at the end of a line continues the value on the next one. Daemons
+// can then read /etc/default/myapp without a wrapper script:
+//
+//	env, err := envy.ReadEnvFile("/etc/default/myapp")
+//	envy.SetLookuper(envy.Chain{envy.EnvLookuper{}, env})
+func ParseEnvFile(r io.Reader) (MapLookuper, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	env, err := parseEnvFile(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("line %w", err)
+	}
+	return env, nil
+}
+
+// parseEnvFile parses the contents of an environment file, errors start with
+// the line number so callers can add the file name.
+func parseEnvFile(data string) (MapLookuper, error) {
+	env := MapLookuper{}
+	line := 1
+	i := 0
+	for i < len(data) {
+		start := line
+		end := strings.IndexByte(data[i:], '\n')
+		if end < 0 {
+			end = len(data) - i
+		}
+		trimmed := strings.TrimSpace(data[i : i+end])
+		if trimmed == "" || trimmed[0] == '#' || trimmed[0] == ';' {
+			i += end + 1
+			line++
+			continue
+		}
+
+		eq := strings.IndexByte(data[i:i+end], '=')
+		key := ""
+		if eq >= 0 {
+			key = strings.TrimSpace(data[i : i+eq])
+		}
+		if !validEnvKey(key) {
+			return nil, fmt.Errorf("%d: %w", start, ErrInvalidEnvFile)
+		}
+
+		var val string
+		var err error
+		val, i, line, err = parseEnvValue(data, i+eq+1, line)
+		if err != nil {
+			return nil, fmt.Errorf("%d: %w", start, err)
+		}
+		env[key] = val
+	}
+	return env, nil
+}
+
+// parseEnvValue reads the value starting at i up to the end of its line,
+// following quotes and continuations, returning where the next line starts.
+func parseEnvValue(data string, i, line int) (string, int, int, error) {
+	b := &strings.Builder{}
+
+	// Trailing whitespace is dropped, unless it was quoted or escaped.
+	keep := 0
+	value := func() string {
+		s := b.String()
+		return s[:keep] + strings.TrimRight(s[keep:], " \t\r")
+	}
+	for i < len(data) && (data[i] == ' ' || data[i] == '\t') {
+		i++
+	}
+	for i < len(data) {
+		c := data[i]
+		switch {
+		case c == '\n':
+			return value(), i + 1, line + 1, nil
+		case c == '\'' || c == '"':
+			closing := false
+			for i++; i < len(data); i++ {
+				if data[i] == c {
+					closing = true
+					break
+				}
+				if data[i] == '\n' {
+					line++
+				}
+				if c == '"' && data[i] == '\\' && i+1 < len(data) {
+					switch next := data[i+1]; next {
+					case '\n':
+						i++
+						line++
+						continue
+					case '"', '\\', '$', '`':
+						i++
+						b.WriteByte(next)
+						continue
+					}
+				}
+				b.WriteByte(data[i])
+			}
+			if !closing {
+				return "", i, line, errors.New("unterminated quote")
+			}
+			keep = b.Len()
+		case c == '\\' && i+1 < len(data):
+			i++
+			if data[i] == '\n' {
+				line++
+			} else {
+				b.WriteByte(data[i])
+				keep = b.Len()
+			}
+		default:
+			b.WriteByte(c)
+		}
+		i++
+	}
+	return value(), i, line, nil
+}
+
+// validEnvKey reports whether key is a valid shell variable name.
+func validEnvKey(key string) bool {
+	if key == "" || key[0] >= '0' && key[0] <= '9' {
+		return false
+	}
+	for _, c := range key {
+		if c != '_' && !(c >= 'a' && c <= 'z') && !(c >= 'A' && c <= 'Z') && !(c >= '0' && c <= '9') {
+			return false
+		}
+	}
+	return true
+}
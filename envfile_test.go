@@ -0,0 +1,84 @@
+package envy_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/fernferret/envy"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseEnvFile(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want envy.MapLookuper
+	}{
+		{"plain", "FOO=bar\n", envy.MapLookuper{"FOO": "bar"}},
+		{"whitespace", "  FOO = bar baz  \n", envy.MapLookuper{"FOO": "bar baz"}},
+		{"comments", "# FOO=1\n; BAR=2\n\nBAZ=3", envy.MapLookuper{"BAZ": "3"}},
+		{"empty", "FOO=\n", envy.MapLookuper{"FOO": ""}},
+		{"single quotes", `FOO='a \"b\" $c  '`, envy.MapLookuper{"FOO": `a \"b\" $c  `}},
+		{"double quotes", `FOO="a \"b\" \$c \\ \n"`, envy.MapLookuper{"FOO": `a "b" $c \ \n`}},
+		{"mixed quotes", `FOO=a"b c"'d'`, envy.MapLookuper{"FOO": "ab cd"}},
+		{"escaped", `FOO=a\ b\\`, envy.MapLookuper{"FOO": `a b\`}},
+		{"continuation", "FOO=a \\\nb\nBAR=c", envy.MapLookuper{"FOO": "a b", "BAR": "c"}},
+		{"quoted continuation", "FOO=\"a\\\nb\"\nBAR=c", envy.MapLookuper{"FOO": "ab", "BAR": "c"}},
+		{"multiline quote", "FOO='a\nb'\nBAR=c", envy.MapLookuper{"FOO": "a\nb", "BAR": "c"}},
+		{"hash in value", "FOO=a#b", envy.MapLookuper{"FOO": "a#b"}},
+		{"equals in value", "FOO=a=b", envy.MapLookuper{"FOO": "a=b"}},
+		{"crlf", "FOO=bar\r\nBAR=baz\r\n", envy.MapLookuper{"FOO": "bar", "BAR": "baz"}},
+		{"later wins", "FOO=1\nFOO=2", envy.MapLookuper{"FOO": "2"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env, err := envy.ParseEnvFile(strings.NewReader(tt.data))
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, env)
+		})
+	}
+}
+
+func TestParseEnvFileErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		err  string
+	}{
+		{"no equals", "FOO=1\nBAR\n", "line 2: expected KEY=VALUE"},
+		{"bad key", "1FOO=1", "line 1: expected KEY=VALUE"},
+		{"empty key", "=1", "line 1: expected KEY=VALUE"},
+		{"unterminated", "FOO=1\nBAR=\"a\nb", "line 2: unterminated quote"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := envy.ParseEnvFile(strings.NewReader(tt.data))
+			assert.EqualError(t, err, tt.err)
+		})
+	}
+}
+
+func TestReadEnvFile(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("MYAPP_NAME", "from-env")
+	path := filepath.Join(t.TempDir(), "myapp")
+	assert.NoError(t, os.WriteFile(path, []byte("MYAPP_NAME=from-file\nMYAPP_COUNT=3\n"), 0o600))
+
+	env, err := envy.ReadEnvFile(path)
+	assert.NoError(t, err)
+
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	name := fs.String("name", "", "name")
+	count := fs.Int("count", 1, "count")
+	e := envy.New(envy.WithPrefix("MYAPP"), envy.WithFlagSet(fs), envy.WithLookuper(envy.Chain{envy.EnvLookuper{}, env}))
+	assert.NoError(t, e.ParseE())
+	assert.Equal(t, "from-env", *name)
+	assert.Equal(t, 3, *count)
+
+	assert.NoError(t, os.WriteFile(path, []byte("oops\n"), 0o600))
+	_, err = envy.ReadEnvFile(path)
+	assert.EqualError(t, err, path+":1: expected KEY=VALUE")
+}
@@ -0,0 +1,67 @@
+package envy
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+var ErrUnknownEnvironment = errors.New("environment is not one of the configured environments")
+
+// Chain is a Lookuper that checks each Lookuper in order, the first one with
+// the variable wins.
+type Chain []Lookuper
+
+func (c Chain) Lookup(key string) (string, bool) {
+	for _, l := range c {
+		if val, ok := l.Lookup(key); ok {
+			return val, true
+		}
+	}
+	return "", false
+}
+
+// Environment describes where configuration comes from in one deployment
+// environment, like dev or prod.
+type Environment struct {
+	Name string
+
+	// Checked in order, the first one with a variable wins.
+	Sources []Lookuper
+
+	// How mistakes are reported, see SetStrictness.
+	Strictness Strictness
+}
+
+// Environments selects the sources and strictness of an Envy by the value of
+// the process environment variable varName, like MYAPP_ENV=dev, matched
+// against each Environment's name ignoring case. If varName isn't set the
+// first Environment is used, an unknown name is reported by Parse:
+//
+//	envy.New(envy.WithPrefix("MYAPP"), envy.Environments("MYAPP_ENV",
+//		envy.Environment{Name: "dev", Sources: []envy.Lookuper{envy.EnvLookuper{}, dotenv}, Strictness: envy.ReturnErrors},
+//		envy.Environment{Name: "prod", Sources: []envy.Lookuper{vault, envy.EnvLookuper{}}},
+//	))
+func Environments(varName string, envs ...Environment) Option {
+	return func(e *Envy) {
+		if len(envs) == 0 {
+			return
+		}
+		selected := &envs[0]
+		if name, ok := os.LookupEnv(varName); ok {
+			selected = nil
+			for i := range envs {
+				if strings.EqualFold(envs[i].Name, name) {
+					selected = &envs[i]
+				}
+			}
+			if selected == nil {
+				e.optErrs = append(e.optErrs, &SetError{EnvName: varName, Err: fmt.Errorf("%w: %q", ErrUnknownEnvironment, name)})
+				return
+			}
+		}
+		e.lookuper = Chain(selected.Sources)
+		e.strictness = selected.Strictness
+	}
+}
@@ -0,0 +1,67 @@
+package envy_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/fernferret/envy"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnvironments(t *testing.T) {
+	dotenv := envy.MapLookuper{"FOO_URL": "http://dotenv", "FOO_WORKERS": "2"}
+	vault := envy.MapLookuper{"FOO_URL": "http://vault"}
+	envs := []envy.Environment{
+		{Name: "dev", Sources: []envy.Lookuper{envy.EnvLookuper{}, dotenv}, Strictness: envy.ReturnErrors},
+		{Name: "prod", Sources: []envy.Lookuper{vault, envy.EnvLookuper{}}},
+	}
+
+	tests := []struct {
+		name    string
+		env     string
+		url     string
+		workers string
+		err     error
+	}{
+		{name: "test unset uses first", url: "http://env", workers: "2"},
+		{name: "test dev", env: "DEV", url: "http://env", workers: "2"},
+		{name: "test prod", env: "prod", url: "http://vault", workers: "1"},
+		{name: "test unknown", env: "stage", err: envy.ErrUnknownEnvironment},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv("FOO_URL", "http://env")
+			if tt.env != "" {
+				os.Setenv("FOO_ENV", tt.env)
+			}
+			fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+			fs.String("url", "", "set the url")
+			fs.Int("workers", 1, "number of workers")
+
+			e := envy.New(envy.WithPrefix("FOO"), envy.WithFlagSet(fs), envy.Environments("FOO_ENV", envs...))
+			err := e.ParseE()
+			if tt.err != nil {
+				assert.ErrorIs(t, err, tt.err)
+				assert.EqualError(t, err, `FOO_ENV: environment is not one of the configured environments: "stage"`)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.url, fs.Lookup("url").Value.String())
+			assert.Equal(t, tt.workers, fs.Lookup("workers").Value.String())
+		})
+	}
+}
+
+func TestEnvironmentsStrictness(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("FOO_ENV", "dev")
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+
+	e := envy.New(envy.WithFlagSet(fs), envy.Environments("FOO_ENV",
+		envy.Environment{Name: "prod"},
+		envy.Environment{Name: "dev", Strictness: envy.ReturnErrors},
+	))
+	assert.NotPanics(t, func() { e.Disable("missing") })
+}
@@ -3,7 +3,7 @@ package envy
 import (
 	"errors"
 	"fmt"
-	"os"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -11,13 +11,27 @@ import (
 	"github.com/spf13/pflag"
 )
 
+// The keys of the pflag.Flag annotations envy uses to keep track of each flag,
+// here and beside the features that use them. They're stable, so external
+// tools and tests can read them, or set ones like AnnotationDisable on flags
+// defined by code that doesn't know about envy.
 const (
 	// Used to disable envy entirely for the given flag.
-	envyDisable = "envy_disable"
+	AnnotationDisable = "envy_disable"
 
 	// Used to set an override that ignores the prefix, useful for well known
 	// environment variables like KUBECONFIG
-	envyCustom = "envy_custom"
+	AnnotationCustom = "envy_custom"
+
+	// Used to hide the value of a flag anywhere envy prints it.
+	AnnotationSecret = "envy_secret"
+
+	// Set by ParseFlagSet to record the environment variable that supplied the
+	// flag's value.
+	AnnotationSource = "envy_source"
+
+	// Set by ParseFlagSet on every flag it binds, holds the prefix used.
+	AnnotationBound = "envy_bound"
 )
 
 var (
@@ -25,8 +39,60 @@ var (
 	ErrCustomAlreadyDefined     = errors.New("custom flag already exists")
 	ErrInvalidBoolFlagValue     = errors.New("bool flag got value that was't 'true' or 'false'")
 	ErrInvalidDurationFlagValue = errors.New("duration flag got value that was't parsable as a golang duration, example: 1m30s")
+	ErrNotParsed                = errors.New("flag set has not been parsed by envy")
+	ErrNoEnvNames               = errors.New("at least one environment variable name is required")
 )
 
+// SetError is what ParseFlagSet panics with when a flag rejects the value of
+// its environment variable, including values that refuse to be Set more than
+// once, and what misuse like an unknown flag name panics with. Flag is empty
+// for errors from a Gate's variable and EnvName is empty for misuse. Value
+// holds the rejected value, or "<redacted>" for a Secret flag, and is left out
+// of the message so it never ends up in logs by accident. Err wraps the cause,
+// so errors.Is still matches sentinels like ErrInvalidBoolFlagValue.
+type SetError struct {
+	Flag    string
+	EnvName string
+	Value   string
+	Err     error
+}
+
+func (e *SetError) Error() string {
+	switch {
+	case e.Flag == "" && e.EnvName == "":
+		return e.Err.Error()
+	case e.Flag == "":
+		return fmt.Sprintf("%s: %v", e.EnvName, e.Err)
+	case e.EnvName == "":
+		return fmt.Sprintf("--%s: %v", e.Flag, e.Err)
+	}
+	return fmt.Sprintf("--%s from %s: %v", e.Flag, e.EnvName, e.Err)
+}
+
+func (e *SetError) Unwrap() error {
+	return e.Err
+}
+
+// ParseErrors is returned by ParseFlagSetE, holding an error for every flag
+// that couldn't be set from the environment in lexical order by name.
+type ParseErrors []*SetError
+
+func (e ParseErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+func (e ParseErrors) Unwrap() []error {
+	errs := make([]error, len(e))
+	for i, err := range e {
+		errs[i] = err
+	}
+	return errs
+}
+
 // ParseFlagSet will loop through defined flags in the default pflag.CommandLine
 // and automatically add an environment variable parser for the flag name. This
 // Parse func must be called before the call to pflag.Parse() and after you've
@@ -38,62 +104,156 @@ func Parse(pfx string) {
 // ParseFlagSet will loop through defined flags in the given pflag.FlagSet and
 // automatically add an environment variable parser for the flag name. This
 // ParseFlagSet func must be called before the call to pflag.Parse() and after
-// you've defined all your flags.
+// you've defined all your flags. Flags are always visited in lexical order by
+// name, as they are in every report envy produces, even if SortFlags is off.
 func ParseFlagSet(pfx string, fs *pflag.FlagSet) {
+	std.on(pfx, fs).Parse()
+}
+
+// ParseE works like Parse but returns every value that couldn't be set instead
+// of panicking, see ParseFlagSetE.
+func ParseE(pfx string) error {
+	return ParseFlagSetE(pfx, pflag.CommandLine)
+}
 
-	// Transform the pfx to uppercase and remove trailing _s, this allows many
-	// different uses without producing weird results
-	if pfx != "" {
-		pfx = strings.TrimSuffix(strings.ToUpper(pfx), "_") + "_"
+// ParseFlagSetE works like ParseFlagSet but rather than panicking on the first
+// invalid value it binds every flag it can and returns a ParseErrors naming
+// each flag and environment variable that failed, letting libraries decide
+// whether to exit, log or carry on.
+func ParseFlagSetE(pfx string, fs *pflag.FlagSet) error {
+	return std.on(pfx, fs).ParseE()
+}
+
+// parse binds every flag in the FlagSet, collecting any failures.
+func (e *Envy) parse() ParseErrors {
+	errs := e.prepare()
+
+	// Mistakes queued before the parse, like an invalid default from
+	// BoolVarE, are found again when their flag is bound, so keep them once.
+	seen := map[string]bool{}
+	for _, err := range errs {
+		seen[err.Error()] = true
 	}
+	for _, err := range e.bindAll() {
+		if !seen[err.Error()] {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
 
-	fs.VisitAll(func(f *pflag.Flag) {
+// prepare registers modules and returns any mistakes queued for the FlagSet,
+// leaving every flag in place for bindAll.
+func (e *Envy) prepare() ParseErrors {
+	parsed.set(e.fs, e)
+	errs, _ := queued.take(e.fs)
+	errs = append(errs, e.optErrs...)
+	return append(errs, e.registerModules()...)
+}
 
-		// Skip any items with envyDisable set at all, there's no way to set it
-		// as "false"
-		if _, ok := f.Annotations[envyDisable]; ok {
-			return
+// bindAll binds every flag in the FlagSet, collecting any failures and names
+// violating the NamePolicy, then reports overrides to the TelemetryFunc.
+func (e *Envy) bindAll() ParseErrors {
+	var errs ParseErrors
+	var restores []func()
+	visitAll(e.fs, func(f *pflag.Flag) {
+		if e.atomic {
+			restores = append(restores, checkpoint(f))
 		}
+		if err := e.bind(f); err != nil {
+			errs = append(errs, err)
+		}
+	})
+	if len(errs) > 0 {
+		for _, restore := range restores {
+			restore()
+		}
+	}
+	if e.namePolicy != nil {
+		errs = append(errs, e.lint(*e.namePolicy)...)
+	}
+	if e.strict {
+		errs = append(errs, e.unknownNames()...)
+	}
+	if e.telemetry != nil {
+		e.telemetry(e.overrides())
+	}
+	return errs
+}
+
+// bind reads the flag's environment variable, if any, and decorates its usage.
+func (e *Envy) bind(f *pflag.Flag) *SetError {
+
+	// Skip any items with AnnotationDisable set at all, there's no way to set it as
+	// "false"
+	if _, ok := f.Annotations[AnnotationDisable]; ok {
+		return nil
+	}
 
-		var envName string
-		if val, ok := f.Annotations[envyCustom]; ok {
-			// Envy will panic if duplicate custom overrides are defined, so
-			// this is always safe to pull the first item.
-			envName = val[0]
-		} else {
-			envName = fmt.Sprintf("%s%s", pfx, strings.ReplaceAll(strings.ToUpper(f.Name), "-", "_"))
+	if err := e.bindPrefix(f); err != nil {
+		return err
+	}
+
+	envName, val, ok, err := e.lookupFlag(append(e.boundNames(f), e.migrationNames(f)...), f)
+	if err != nil {
+		return &SetError{Flag: f.Name, EnvName: envName, Err: err}
+	}
+
+	// Config files fill in for unset variables, or override them entirely
+	// if WithPrecedence says so.
+	var fromFile bool
+	if !ok || e.filesFirst() {
+		var err *SetError
+		if fromFile, err = e.applyFile(f); err != nil {
+			return err
 		}
+	}
 
-		envUsage := envName
-		if val, ok := os.LookupEnv(envName); ok {
-
-			// Bool flags are a bit more interesting. I don't want to silently
-			// fail if someone passes "yes", so let's panic to blow this thing
-			// wide open!
-			switch f.Value.Type() {
-			case "bool":
-				if _, err := strconv.ParseBool(val); err != nil {
-					panic(ErrInvalidBoolFlagValue)
-				}
-			case "duration":
-				if dur, err := time.ParseDuration(val); err != nil {
-					panic(ErrInvalidDurationFlagValue)
-				} else {
-					// Set the val as the parsed duration, this way it shows up
-					// properly parsed.
-					val = dur.String()
-				}
-			}
+	if ok && !fromFile {
 
-			envUsage = fmt.Sprintf("%s %s", envName, val)
+		// Bool flags are a bit more interesting. I don't want to silently fail
+		// if someone passes "yes", so let's report it to blow this thing wide
+		// open!
+		var err error
+		raw := val
+		if val, err = e.normalize(f, val); err != nil {
+			return valueError(f, envName, raw, err)
+		}
 
-			// We can always set this value since the parse function will always
-			// win and override us.
-			f.Value.Set(val)
+		// We can always set this value since the parse function will always
+		// win and override us. Values that fail to parse are just as bad as
+		// an invalid bool, so report them naming the flag and variable at
+		// fault. Values already read from this variable by an earlier Parse
+		// are left alone. Defaults from the environment only change the
+		// default, so they aren't recorded as coming from it.
+		if !sourcedFrom(f, envName) {
+			migratedTo, migrated, err := e.migrated(f, envName)
+			if err != nil {
+				return &SetError{Flag: f.Name, EnvName: envName, Err: err}
+			}
+			e.remember(f)
+			if e.envAsDefault || envDefault(f) {
+				err = setDefault(f, val)
+			} else if err = setValue(f, val); err == nil {
+				annotate(f, AnnotationSource, envName)
+			}
+			if err != nil {
+				return valueError(f, envName, val, err)
+			}
+			if newName, ok := replacementFor(f, envName); ok {
+				e.deprecated(f.Name, envName, newName)
+			} else if migrated {
+				e.deprecated(f.Name, envName, migratedTo)
+			}
+		}
+	} else if !ok && !fromFile {
+		if err := e.applyExperiment(f); err != nil {
+			return err
 		}
+	}
 
-		f.Usage = fmt.Sprintf("%s [%s]", f.Usage, envUsage)
-	})
+	e.decorate(f, envName, val, ok)
+	return nil
 }
 
 // Disable removes the given flag from using any environment variables. It must
@@ -105,14 +265,18 @@ func Disable(name string) {
 // DisableOnFlagSet removes the given flag from using any environment variables.
 // It must be called before the call to envy.Parse().
 func DisableOnFlagSet(name string, fs *pflag.FlagSet) {
-	f := fs.Lookup(name)
+	std.on("", fs).Disable(name)
+}
+
+// Disable removes the given flag from using any environment variables, see
+// DisableOnFlagSet.
+func (e *Envy) Disable(name string) {
+	f := e.fs.Lookup(name)
 	if f == nil {
-		panic(ErrFlagNotExists)
+		e.fail(&SetError{Flag: name, Err: ErrFlagNotExists})
+		return
 	}
-	if f.Annotations == nil {
-		f.Annotations = make(map[string][]string)
-	}
-	f.Annotations[envyDisable] = []string{"true"}
+	annotate(f, AnnotationDisable, "true")
 }
 
 // SetEnvName allows setting a custom environment variable for a given flag. It
@@ -124,17 +288,164 @@ func SetEnvName(name, envName string) {
 // SetEnvNameOnFlagSet allows setting a custom environment variable for a given
 // flag. It must be called before the call to envy.Parse().
 func SetEnvNameOnFlagSet(name, envName string, fs *pflag.FlagSet) {
-	f := fs.Lookup(name)
+	std.on("", fs).SetEnvName(name, envName)
+}
+
+// SetEnvName allows setting a custom environment variable for a given flag,
+// see SetEnvNameOnFlagSet.
+func (e *Envy) SetEnvName(name, envName string) {
+	e.SetEnvNames(name, envName)
+}
+
+// SetEnvNames allows setting several custom environment variables for a given
+// flag, checked in order, see SetEnvNamesOnFlagSet.
+func SetEnvNames(name string, envNames ...string) {
+	SetEnvNamesOnFlagSet(name, pflag.CommandLine, envNames...)
+}
+
+// SetEnvNamesOnFlagSet allows setting several custom environment variables for
+// a given flag, the first one set wins. This lets programs migrating to a new
+// name honor the old spelling during a deprecation window, like
+// SetEnvNames("kube-config", "KUBECONFIG", "KUBE_CONFIG"). Usage shows the
+// first name, or the one that supplied the value. It must be called before the
+// call to envy.Parse().
+func SetEnvNamesOnFlagSet(name string, fs *pflag.FlagSet, envNames ...string) {
+	std.on("", fs).SetEnvNames(name, envNames...)
+}
+
+// SetEnvNames allows setting several custom environment variables for a given
+// flag, see SetEnvNamesOnFlagSet.
+func (e *Envy) SetEnvNames(name string, envNames ...string) {
+	if len(envNames) == 0 {
+		e.fail(&SetError{Flag: name, Err: ErrNoEnvNames})
+		return
+	}
+	f := e.fs.Lookup(name)
 	if f == nil {
-		panic(ErrFlagNotExists)
+		e.fail(&SetError{Flag: name, Err: ErrFlagNotExists})
+		return
 	}
 	if f.Annotations == nil {
 		f.Annotations = make(map[string][]string)
-	} else if _, ok := f.Annotations[envyCustom]; ok {
+	} else if _, ok := f.Annotations[AnnotationCustom]; ok {
 		// Only allow one to be defined, this will prevent weird errors related
 		// to copying an envy line and forgetting to change the first flag.
-		panic(ErrCustomAlreadyDefined)
+		e.fail(&SetError{Flag: name, Err: ErrCustomAlreadyDefined})
+		return
+	}
+	names := make([]string, len(envNames))
+	for i, envName := range envNames {
+		names[i] = envKey(envName)
+	}
+	f.Annotations[AnnotationCustom] = names
+}
+
+// Secret marks the given flag as holding sensitive material. Envy will still
+// read it from the environment but never prints its value in usage or
+// summaries. It must be called before the call to envy.Parse().
+func Secret(name string) {
+	SecretOnFlagSet(name, pflag.CommandLine)
+}
+
+// SecretOnFlagSet marks the given flag as holding sensitive material. Envy will
+// still read it from the environment but never prints its value in usage or
+// summaries. It must be called before the call to envy.Parse().
+func SecretOnFlagSet(name string, fs *pflag.FlagSet) {
+	std.on("", fs).Secret(name)
+}
+
+// Secret marks the given flag as holding sensitive material, see
+// SecretOnFlagSet.
+func (e *Envy) Secret(name string) {
+	f := e.fs.Lookup(name)
+	if f == nil {
+		e.fail(&SetError{Flag: name, Err: ErrFlagNotExists})
+		return
+	}
+	annotate(f, AnnotationSecret, "true")
+}
+
+// normalize checks env values for types where pflag is more lenient than envy
+// wants to be, returning the value to pass to Set.
+func (e *Envy) normalize(f *pflag.Flag, val string) (string, error) {
+	switch f.Value.Type() {
+	case "int", "int8", "int16", "int32", "int64", "uint", "uint8", "uint16", "uint32", "uint64":
+		return resolveRelative(f, val)
+	case "bool":
+		b, err := e.parseBool(val)
+		if err != nil {
+			return "", err
+		}
+		val = strconv.FormatBool(b)
+	case "duration":
+		dur, err := time.ParseDuration(val)
+		if err != nil {
+			iso, ok := parseISODuration(val)
+			if !e.international || !ok {
+				return "", ErrInvalidDurationFlagValue
+			}
+			dur = iso
+		}
+		// Set the val as the parsed duration, this way it shows up properly
+		// parsed.
+		val = dur.String()
+	case "float32", "float64":
+		if relativeValue.MatchString(strings.TrimSpace(val)) {
+			return resolveRelative(f, val)
+		}
+
+		// Anything Go can parse is left alone so 1.234 still means 1.234.
+		if _, err := strconv.ParseFloat(val, 64); err != nil && e.international {
+			if n, ok := parseLocaleNumber(val); ok {
+				val = strconv.FormatFloat(n, 'f', -1, 64)
+			}
+		}
+	}
+	return val, nil
+}
+
+// visitAll calls fn for every flag in lexical order by name, even if the
+// FlagSet has SortFlags turned off, so anything envy reports or fails on is
+// the same from run to run.
+func visitAll(fs *pflag.FlagSet, fn func(*pflag.Flag)) {
+	var flags []*pflag.Flag
+	fs.VisitAll(func(f *pflag.Flag) {
+		flags = append(flags, f)
+	})
+	sort.Slice(flags, func(i, j int) bool {
+		return flags[i].Name < flags[j].Name
+	})
+	for _, f := range flags {
+		fn(f)
+	}
+}
+
+// valueError reports a value the flag rejected, see SetError.
+func valueError(f *pflag.Flag, envName, val string, err error) *SetError {
+	if isSecret(f) {
+		val = redacted
+	}
+	return &SetError{Flag: f.Name, EnvName: envName, Value: val, Err: err}
+}
+
+// sourcedFrom reports whether the flag's value was already read from the given
+// environment variable.
+func sourcedFrom(f *pflag.Flag, envName string) bool {
+	src, ok := f.Annotations[AnnotationSource]
+	return ok && src[0] == envName
+}
+
+// isSecret reports whether the flag was marked with Secret.
+func isSecret(f *pflag.Flag) bool {
+	_, ok := f.Annotations[AnnotationSecret]
+	return ok
+}
+
+// annotate sets an envy annotation on the flag, creating the annotation map if
+// needed.
+func annotate(f *pflag.Flag, key string, vals ...string) {
+	if f.Annotations == nil {
+		f.Annotations = make(map[string][]string)
 	}
-	envName = strings.ToUpper(strings.ReplaceAll(envName, "-", "_"))
-	f.Annotations[envyCustom] = []string{envName}
+	f.Annotations[key] = vals
 }
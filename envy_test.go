@@ -1,6 +1,7 @@
 package envy_test
 
 import (
+	"errors"
 	"os"
 	"testing"
 	"time"
@@ -143,6 +144,35 @@ func TestParseFlagSet(t *testing.T) {
 				panic: true,
 			},
 		},
+		{
+			name: "test invalid int env",
+			args: args{
+				name:  "count",
+				value: 7,
+				usage: "a count",
+				env: map[string]string{
+					"FOO_COUNT": "seven",
+				},
+				pfx:   "FOO",
+				panic: true,
+			},
+		},
+		{
+			name: "test int env",
+			args: args{
+				name:  "count",
+				value: 7,
+				usage: "a count",
+				env: map[string]string{
+					"FOO_COUNT": "13",
+				},
+				pfx: "FOO",
+				exp: exp{
+					usage: "a count [FOO_COUNT 13]",
+					value: "13",
+				},
+			},
+		},
 		{
 			name: "test disabled flag",
 			args: args{
@@ -205,6 +235,8 @@ func TestParseFlagSet(t *testing.T) {
 				pflag.String(tt.args.name, v, tt.args.usage)
 			case bool:
 				pflag.Bool(tt.args.name, v, tt.args.usage)
+			case int:
+				pflag.Int(tt.args.name, v, tt.args.usage)
 			case time.Duration:
 				pflag.Duration(tt.args.name, v, tt.args.usage)
 			default:
@@ -260,6 +292,119 @@ func TestDuplicateSet(t *testing.T) {
 	assert.Panics(t, func() { envy.SetEnvName("kube-config", "KUBECONFIG") })
 }
 
+func TestParseOrder(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("FOO_ZONE", "nope")
+	os.Setenv("FOO_ALPHA", "nope")
+	fs := pflag.NewFlagSet("test", pflag.PanicOnError)
+	fs.SortFlags = false
+
+	fs.Int("zone", 0, "the zone")
+	fs.Int("alpha", 0, "the alpha")
+
+	// The failure is always the lexically first flag, whatever the order
+	// they're defined or shown in.
+	defer func() {
+		err, _ := recover().(error)
+		assert.ErrorContains(t, err, "--alpha from FOO_ALPHA")
+	}()
+	envy.ParseFlagSet("FOO", fs)
+}
+
+func TestParseE(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("FOO_VERBOSE", "yes")
+	os.Setenv("FOO_COUNT", "many")
+	os.Setenv("FOO_URL", "http://127.0.0.1")
+	os.Setenv("FOO_DEBUG", "maybe")
+	pflag.CommandLine = pflag.NewFlagSet("test", pflag.PanicOnError)
+
+	pflag.Bool("verbose", false, "verbose usage")
+	pflag.Int("count", 1, "count usage")
+	url := pflag.String("url", "", "url usage")
+	envy.Gate("debug", func(fs *pflag.FlagSet) {})
+
+	err := envy.ParseE("FOO")
+	assert.ErrorIs(t, err, envy.ErrInvalidBoolFlagValue)
+	assert.EqualError(t, err, `FOO_DEBUG: bool flag got value that was't 'true' or 'false'
+--count from FOO_COUNT: strconv.ParseInt: parsing "many": invalid syntax
+--verbose from FOO_VERBOSE: bool flag got value that was't 'true' or 'false'`)
+
+	// Everything else is still bound
+	assert.Equal(t, "http://127.0.0.1", *url)
+
+	var errs envy.ParseErrors
+	if assert.ErrorAs(t, err, &errs) {
+		assert.Len(t, errs, 3)
+		assert.Equal(t, "count", errs[1].Flag)
+		assert.Equal(t, "FOO_COUNT", errs[1].EnvName)
+	}
+
+	os.Clearenv()
+	pflag.CommandLine = pflag.NewFlagSet("test", pflag.PanicOnError)
+	pflag.Bool("verbose", false, "verbose usage")
+	assert.NoError(t, envy.ParseE("FOO"))
+}
+
+// onceValue is a string value that can only be set once.
+type onceValue struct {
+	val string
+	set bool
+}
+
+func (v *onceValue) Set(val string) error {
+	if v.set {
+		return errors.New("already set")
+	}
+	v.val, v.set = val, true
+	return nil
+}
+
+func (v *onceValue) String() string { return v.val }
+func (v *onceValue) Type() string   { return "once" }
+
+func TestSetError(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("FOO_TOKEN", "abc")
+	fs := pflag.NewFlagSet("test", pflag.PanicOnError)
+	v := &onceValue{}
+	fs.Var(v, "token", "one time token")
+	v.Set("from code")
+
+	defer func() {
+		var setErr *envy.SetError
+		err, _ := recover().(error)
+		if !errors.As(err, &setErr) {
+			t.Fatalf("expected a *envy.SetError, got %v", err)
+		}
+		assert.Equal(t, "token", setErr.Flag)
+		assert.Equal(t, "FOO_TOKEN", setErr.EnvName)
+		assert.Equal(t, "abc", setErr.Value)
+		assert.EqualError(t, err, "--token from FOO_TOKEN: already set")
+	}()
+	envy.ParseFlagSet("FOO", fs)
+}
+
+func TestSetErrorValue(t *testing.T) {
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	fs.Bool("verbose", false, "be verbose")
+	fs.Int("pin", 0, "pin code")
+	env := envy.MapLookuper{"FOO_VERBOSE": "sure", "FOO_PIN": "12ab"}
+	e := envy.New(envy.WithFlagSet(fs), envy.WithPrefix("FOO"), envy.WithLookuper(env))
+	e.Secret("pin")
+
+	var errs envy.ParseErrors
+	if !errors.As(e.ParseE(), &errs) || len(errs) != 2 {
+		t.Fatalf("expected two errors, got %v", errs)
+	}
+	assert.Equal(t, "<redacted>", errs[0].Value)
+	assert.Equal(t, "sure", errs[1].Value)
+	assert.ErrorIs(t, errs[1], envy.ErrInvalidBoolFlagValue)
+	assert.EqualError(t, errs[1], "--verbose from FOO_VERBOSE: "+envy.ErrInvalidBoolFlagValue.Error())
+
+	assert.PanicsWithError(t, "--missing: "+envy.ErrFlagNotExists.Error(), func() { e.Secret("missing") })
+}
+
 func ExampleParse() {
 	// Reset CommandLine flags for example, don't include these in your code!
 	pflag.CommandLine = pflag.NewFlagSet("test", pflag.PanicOnError)
@@ -336,3 +481,70 @@ func ExampleSetEnvName() {
 	// Output: --once         only run processing once [FOO_ONCE]
 	//       --url string   set the url [MY_HTTP_URL] (default "http://localhost:8080")
 }
+
+func TestSetEnvNames(t *testing.T) {
+	tests := []struct {
+		name  string
+		env   map[string]string
+		usage string
+		value string
+	}{
+		{
+			name:  "test none set",
+			usage: "kube config [KUBECONFIG]",
+		},
+		{
+			name:  "test old name",
+			env:   map[string]string{"KUBE_CONFIG": "/old"},
+			usage: "kube config [KUBE_CONFIG /old]",
+			value: "/old",
+		},
+		{
+			name:  "test new name wins",
+			env:   map[string]string{"KUBE_CONFIG": "/old", "KUBECONFIG": "/new"},
+			usage: "kube config [KUBECONFIG /new]",
+			value: "/new",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			os.Clearenv()
+			for k, v := range tt.env {
+				os.Setenv(k, v)
+			}
+			pflag.CommandLine = pflag.NewFlagSet("test", pflag.PanicOnError)
+			pflag.String("kube-config", "", "kube config")
+
+			envy.SetEnvNames("kube-config", "KUBECONFIG", "kube-config")
+			envy.Parse("FOO")
+			assert.Equal(t, tt.usage, pflag.Lookup("kube-config").Usage)
+			assert.Equal(t, tt.value, pflag.Lookup("kube-config").Value.String())
+		})
+	}
+
+	pflag.CommandLine = pflag.NewFlagSet("test", pflag.PanicOnError)
+	pflag.String("kube-config", "", "kube config")
+	assert.PanicsWithError(t, "--kube-config: "+envy.ErrNoEnvNames.Error(), func() { envy.SetEnvNames("kube-config") })
+	assert.PanicsWithError(t, "--missing: "+envy.ErrFlagNotExists.Error(), func() { envy.SetEnvNames("missing", "A") })
+	envy.SetEnvNames("kube-config", "A", "B")
+	assert.PanicsWithError(t, "--kube-config: "+envy.ErrCustomAlreadyDefined.Error(), func() { envy.SetEnvName("kube-config", "C") })
+}
+
+func TestAnnotations(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("FOO_URL", "http://example.com")
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	fs.String("url", "", "set the url")
+	fs.String("token", "", "api token")
+	fs.Bool("once", false, "only once")
+
+	// Flags defined elsewhere can be configured without calling envy.
+	fs.SetAnnotation("once", envy.AnnotationDisable, []string{"true"})
+	fs.SetAnnotation("token", envy.AnnotationSecret, []string{"true"})
+	envy.ParseFlagSetE("FOO", fs)
+
+	assert.Equal(t, []string{"FOO_"}, fs.Lookup("url").Annotations[envy.AnnotationBound])
+	assert.Equal(t, []string{"FOO_URL"}, fs.Lookup("url").Annotations[envy.AnnotationSource])
+	assert.NotContains(t, fs.Lookup("once").Annotations, envy.AnnotationBound)
+	assert.Equal(t, "<redacted>", envy.SourcesFlagSet(fs)[1].Value)
+}
@@ -0,0 +1,108 @@
+package envy
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+var ErrNoCommand = errors.New("no command given")
+
+// Environ returns the process environment with the variables for every flag
+// envy bound in the default pflag.CommandLine, see EnvironFlagSet.
+func Environ() []string {
+	return EnvironFlagSet(pflag.CommandLine)
+}
+
+// EnvironFlagSet returns the process environment with each flag envy bound in
+// the given FlagSet set to its current value under its primary environment
+// variable. Since flags given on the command line win, the result describes
+// the fully resolved configuration. It must be called after pflag.Parse().
+func EnvironFlagSet(fs *pflag.FlagSet) []string {
+	e := instanceFor(fs)
+	resolved := map[string]string{}
+	var order []string
+	visitAll(fs, func(f *pflag.Flag) {
+		pfx, ok := f.Annotations[AnnotationBound]
+		if !ok {
+			return
+		}
+		name := e.envNameFor(pfx[0], f)
+		if _, ok := resolved[name]; !ok {
+			order = append(order, name)
+		}
+		resolved[name] = envValue(f)
+	})
+
+	var env []string
+	for _, kv := range os.Environ() {
+		name := strings.SplitN(kv, "=", 2)[0]
+		if _, ok := resolved[name]; !ok {
+			env = append(env, kv)
+		}
+	}
+	for _, name := range order {
+		env = append(env, name+"="+resolved[name])
+	}
+	return env
+}
+
+// Exec runs the program with the environment from Environ, see ExecFlagSet.
+func Exec(argv []string) error {
+	return ExecFlagSet(argv, pflag.CommandLine)
+}
+
+// ExecFlagSet replaces the current process with the program in argv, passing
+// it the environment from EnvironFlagSet. This lets envy act as a small
+// supervisor so legacy programs that only read environment variables benefit
+// from envy's flags. It only returns if the program couldn't be started. On
+// Windows, where a process can't be replaced, the program is run as a child
+// and the current process exits with its exit code.
+func ExecFlagSet(argv []string, fs *pflag.FlagSet) error {
+	if len(argv) == 0 {
+		return ErrNoCommand
+	}
+	path, err := exec.LookPath(argv[0])
+	if err != nil {
+		return err
+	}
+	return execve(path, argv, EnvironFlagSet(fs))
+}
+
+// envValue formats the flag's value so envy would parse it back the same way,
+// slices are joined with commas rather than printed with brackets.
+func envValue(f *pflag.Flag) string {
+	if s, ok := valueOf(f).(pflag.SliceValue); ok {
+		return strings.Join(s.GetSlice(), ",")
+	}
+	return f.Value.String()
+}
+
+// ReExec starts a new copy of the running binary, see ReExecFlagSet.
+func ReExec(extraEnv ...string) (*exec.Cmd, error) {
+	return ReExecFlagSet(pflag.CommandLine, extraEnv...)
+}
+
+// ReExecFlagSet starts a new copy of the running binary with the same
+// arguments and the environment from EnvironFlagSet, plus any extra KEY=VALUE
+// pairs which take priority. Pinning the resolved values into the environment
+// means the child ends up with exactly the same configuration even if the
+// parent's environment was changed since it started, which is what zero
+// downtime upgrades need. The child shares stdin, stdout and stderr and is
+// returned already started.
+func ReExecFlagSet(fs *pflag.FlagSet, extraEnv ...string) (*exec.Cmd, error) {
+	path, err := os.Executable()
+	if err != nil {
+		return nil, err
+	}
+	cmd := exec.Command(path, os.Args[1:]...)
+	cmd.Env = append(EnvironFlagSet(fs), extraEnv...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	return cmd, nil
+}
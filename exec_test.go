@@ -0,0 +1,82 @@
+package envy_test
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/fernferret/envy"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnviron(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("PATH", "/usr/bin")
+	os.Setenv("FOO_URL", "http://from-env")
+	pflag.CommandLine = pflag.NewFlagSet("test", pflag.PanicOnError)
+
+	pflag.String("url", "http://localhost", "set the url")
+	pflag.StringSlice("tags", []string{"a", "b"}, "tags")
+	pflag.Bool("dry-run", false, "don't do anything")
+	pflag.String("kube-config", "", "kube config")
+	envy.Disable("dry-run")
+	envy.SetEnvName("kube-config", "KUBECONFIG")
+	envy.Parse("FOO")
+
+	pflag.CommandLine.Parse([]string{"--kube-config=/tmp/config"})
+
+	assert.Equal(t, []string{
+		"PATH=/usr/bin",
+		"KUBECONFIG=/tmp/config",
+		"FOO_TAGS=a,b",
+		"FOO_URL=http://from-env",
+	}, envy.Environ())
+}
+
+func TestReExec(t *testing.T) {
+	// When re-executed, the test binary only runs this test and reports the
+	// environment it was given.
+	if os.Getenv("ENVY_TEST_REEXEC") == "1" {
+		fmt.Printf("url=%s\n", os.Getenv("FOO_URL"))
+		return
+	}
+
+	os.Clearenv()
+	pflag.CommandLine = pflag.NewFlagSet("test", pflag.PanicOnError)
+	pflag.String("url", "http://localhost", "set the url")
+	envy.Parse("FOO")
+	pflag.CommandLine.Parse([]string{"--url=http://pinned"})
+
+	// Swap os.Args so the child only runs this test
+	args := os.Args
+	defer func() { os.Args = args }()
+	os.Args = []string{args[0], "-test.run=^TestReExec$"}
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	cmd, err := envy.ReExec("ENVY_TEST_REEXEC=1")
+	os.Stdout = stdout
+	w.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out, _ := io.ReadAll(r)
+	assert.NoError(t, cmd.Wait())
+	assert.Contains(t, string(out), "url=http://pinned\n")
+}
+
+func TestExecNoCommand(t *testing.T) {
+	assert.ErrorIs(t, envy.Exec(nil), envy.ErrNoCommand)
+}
+
+func TestExecNotFound(t *testing.T) {
+	os.Clearenv()
+	assert.Error(t, envy.Exec([]string{"envy-does-not-exist"}))
+}
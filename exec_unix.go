@@ -0,0 +1,9 @@
+//go:build !windows
+
+package envy
+
+import "syscall"
+
+func execve(path string, argv, env []string) error {
+	return syscall.Exec(path, argv, env)
+}
@@ -0,0 +1,115 @@
+package envy
+
+import (
+	"errors"
+	"hash/fnv"
+	"os"
+	"strconv"
+
+	"github.com/spf13/pflag"
+)
+
+const (
+	// Used to hold an experiment's alternate value and percentage.
+	AnnotationExperiment = "envy_experiment"
+
+	// Set by ParseFlagSet to record whether this instance got the
+	// experiment's value.
+	AnnotationAssigned = "envy_assigned"
+)
+
+var ErrInvalidPercentage = errors.New("experiment percentage must be between 0 and 100")
+
+// Assignments of instances to an experiment.
+const (
+	Treatment = "treatment"
+	Control   = "control"
+)
+
+// The identity experiments are bucketed by, see SetInstanceID.
+var instanceID = defaultInstanceID()
+
+// defaultInstanceID uses the pod UID when running in Kubernetes with it
+// exposed through the downward API, falling back to the hostname.
+func defaultInstanceID() string {
+	if uid, ok := os.LookupEnv("POD_UID"); ok {
+		return uid
+	}
+	host, _ := os.Hostname()
+	return host
+}
+
+// SetInstanceID replaces the identity experiments use to pick instances and
+// CheckReplicas publishes fingerprints under, which defaults to the POD_UID
+// environment variable or the hostname. It must be called before the call to
+// envy.Parse().
+func SetInstanceID(id string) {
+	instanceID = id
+}
+
+// Experiment gives a flag in the default pflag.CommandLine an alternate value
+// on a percentage of instances, see ExperimentOnFlagSet.
+func Experiment(name, value string, percent float64) {
+	ExperimentOnFlagSet(name, value, percent, pflag.CommandLine)
+}
+
+// ExperimentOnFlagSet gives a flag an alternate value on a deterministic
+// percentage of instances, for canarying a configuration change. Each instance
+// is picked by hashing its instance ID with the flag name, so it gets the same
+// assignment on every restart and different experiments pick different
+// instances. The value replaces the default only, so the environment and the
+// command line still win. Sources reports the assignment. It must be called
+// before the call to envy.Parse().
+func ExperimentOnFlagSet(name, value string, percent float64, fs *pflag.FlagSet) {
+	std.on("", fs).Experiment(name, value, percent)
+}
+
+// Experiment gives a flag an alternate value on a percentage of instances, see
+// ExperimentOnFlagSet.
+func (e *Envy) Experiment(name, value string, percent float64) {
+	f := e.fs.Lookup(name)
+	if f == nil {
+		e.fail(&SetError{Flag: name, Err: ErrFlagNotExists})
+		return
+	}
+	if percent < 0 || percent > 100 {
+		e.fail(&SetError{Flag: name, Err: ErrInvalidPercentage})
+		return
+	}
+	annotate(f, AnnotationExperiment, value, strconv.FormatFloat(percent, 'f', -1, 64))
+}
+
+// applyExperiment sets the flag to the experiment's value if this instance was
+// picked.
+func (e *Envy) applyExperiment(f *pflag.Flag) *SetError {
+	exp, ok := f.Annotations[AnnotationExperiment]
+	if !ok {
+		return nil
+	}
+	percent, _ := strconv.ParseFloat(exp[1], 64)
+	if !inExperiment(f.Name, percent) {
+		annotate(f, AnnotationAssigned, Control)
+		return nil
+	}
+	if err := setValue(f, exp[0]); err != nil {
+		return valueError(f, "", exp[0], err)
+	}
+	annotate(f, AnnotationAssigned, Treatment)
+	return nil
+}
+
+// inExperiment deterministically picks this instance for the flag's experiment
+// with the given probability.
+func inExperiment(name string, percent float64) bool {
+	h := fnv.New32a()
+	h.Write([]byte(name + "/" + instanceID))
+	return float64(h.Sum32()%10000) < percent*100
+}
+
+// assignment returns whether the flag's experiment applied, if it has one.
+func assignment(f *pflag.Flag) string {
+	if val, ok := f.Annotations[AnnotationAssigned]; ok {
+		return val[0]
+	}
+	return ""
+}
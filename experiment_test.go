@@ -0,0 +1,94 @@
+package envy_test
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/fernferret/envy"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExperiment(t *testing.T) {
+	defer envy.SetInstanceID("")
+
+	tests := []struct {
+		name    string
+		percent float64
+		env     string
+		value   string
+		source  envy.FlagSource
+	}{
+		{
+			name:    "test everyone",
+			percent: 100,
+			value:   "2m0s",
+			source:  envy.FlagSource{Flag: "interval", Origin: envy.FromExperiment, EnvName: "FOO_INTERVAL", Value: "2m0s", Experiment: envy.Treatment},
+		},
+		{
+			name:    "test nobody",
+			percent: 0,
+			value:   "1m0s",
+			source:  envy.FlagSource{Flag: "interval", Origin: envy.FromDefault, EnvName: "FOO_INTERVAL", Value: "1m0s", Experiment: envy.Control},
+		},
+		{
+			name:    "test env wins",
+			percent: 100,
+			env:     "30s",
+			value:   "30s",
+			source:  envy.FlagSource{Flag: "interval", Origin: envy.FromEnv, EnvName: "FOO_INTERVAL", Value: "30s"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			os.Clearenv()
+			if tt.env != "" {
+				os.Setenv("FOO_INTERVAL", tt.env)
+			}
+			envy.SetInstanceID("pod-1")
+			pflag.CommandLine = pflag.NewFlagSet("test", pflag.PanicOnError)
+			pflag.Duration("interval", 60e9, "check interval")
+
+			envy.Experiment("interval", "2m", tt.percent)
+			envy.Parse("FOO")
+			pflag.CommandLine.Parse(nil)
+
+			assert.Equal(t, tt.value, pflag.Lookup("interval").Value.String())
+			assert.Equal(t, []envy.FlagSource{tt.source}, envy.Sources())
+		})
+	}
+}
+
+func TestExperimentSpread(t *testing.T) {
+	defer envy.SetInstanceID("")
+	os.Clearenv()
+
+	picked := 0
+	for i := 0; i < 1000; i++ {
+		envy.SetInstanceID(fmt.Sprintf("pod-%d", i))
+		fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+		workers := fs.Int("workers", 1, "number of workers")
+		envy.ExperimentOnFlagSet("workers", "2", 25, fs)
+		envy.ParseFlagSet("FOO", fs)
+		if *workers == 2 {
+			picked++
+		}
+	}
+	assert.InDelta(t, 250, picked, 50)
+
+	// The same instance always gets the same answer
+	for i := 0; i < 2; i++ {
+		envy.SetInstanceID("pod-7")
+		fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+		fs.Int("workers", 1, "number of workers")
+		envy.ExperimentOnFlagSet("workers", "2", 50, fs)
+		envy.ParseFlagSet("FOO", fs)
+		assert.Equal(t, envy.Treatment, envy.SourcesFlagSet(fs)[0].Experiment)
+	}
+
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	fs.Int("workers", 1, "number of workers")
+	assert.Panics(t, func() { envy.ExperimentOnFlagSet("workers", "2", 101, fs) })
+	assert.Panics(t, func() { envy.ExperimentOnFlagSet("missing", "2", 10, fs) })
+}
@@ -0,0 +1,143 @@
+package envy
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/pflag"
+)
+
+// Layer is one place a flag's value could have come from.
+type Layer struct {
+	// Where the value comes from, like "flag", "env FOO_URL" or "default".
+	Source string
+
+	// The candidate value, only meaningful if Set is true.
+	Value string
+	Set   bool
+}
+
+// Explanation lists every candidate value for a flag, highest priority first.
+type Explanation struct {
+	Flag   string
+	Layers []Layer
+
+	// Index of the layer that won, or -1 if the value was changed some other
+	// way, like from code.
+	Winner int
+}
+
+// Explain describes where the value of a flag in the default
+// pflag.CommandLine came from, see ExplainFlagSet.
+func Explain(name string) Explanation {
+	return ExplainFlagSet(name, pflag.CommandLine)
+}
+
+// ExplainFlagSet describes where the value of a flag in the given FlagSet came
+// from, listing the command line, each environment variable envy checks, each
+// config file, any experiment and the default in priority order. It answers "why is this
+// value X" and must be called after pflag.Parse(). Secrets are redacted.
+func ExplainFlagSet(name string, fs *pflag.FlagSet) Explanation {
+	f := fs.Lookup(name)
+	if f == nil {
+		panic(&SetError{Flag: name, Err: ErrFlagNotExists})
+	}
+
+	e := Explanation{Flag: name, Winner: -1}
+	e.Layers = append(e.Layers, Layer{Source: "flag", Value: displayValue(f), Set: f.Changed})
+	if _, ok := f.Annotations[AnnotationBound]; ok {
+		inst := instanceFor(fs)
+
+		// Old prefixes from MigratePrefix are checked after the current ones,
+		// the same as bind does.
+		names := append(inst.boundNames(f), inst.migrationNames(f)...)
+		var envs, files []Layer
+		for _, envName := range names {
+			val, ok := inst.lookuper.Lookup(envName)
+			if ok {
+				val = rawDisplayValue(f, val)
+			}
+			envs = append(envs, Layer{Source: "env " + envName, Value: val, Set: ok})
+		}
+		if path, ok := f.Annotations[AnnotationSecretPath]; ok {
+			val, ok := inst.lookuper.Lookup(path[0])
+			if ok {
+				val = rawDisplayValue(f, val)
+			}
+			envs = append(envs, Layer{Source: "secret " + path[0], Value: val, Set: ok})
+		}
+		if inst.fileSuffixFor(f) {
+			for _, envName := range names {
+				path, ok := inst.lookuper.Lookup(envName + "_FILE")
+				envs = append(envs, Layer{Source: "env " + envName + "_FILE", Value: path, Set: ok})
+			}
+		}
+		for _, path := range inst.secretFiles(names, f) {
+			_, err := os.Stat(path)
+			envs = append(envs, Layer{Source: "file " + path, Value: redacted, Set: err == nil})
+		}
+		for i := len(inst.files) - 1; i >= 0; i-- {
+			val, ok := inst.files[i].values[f.Name]
+			if ok {
+				val = rawDisplayValue(f, val)
+			}
+			files = append(files, Layer{Source: "file " + inst.files[i].path, Value: val, Set: ok})
+		}
+		if inst.filesFirst() {
+			e.Layers = append(append(e.Layers, files...), envs...)
+		} else {
+			e.Layers = append(append(e.Layers, envs...), files...)
+		}
+		if exp, ok := f.Annotations[AnnotationExperiment]; ok {
+			e.Layers = append(e.Layers, Layer{Source: "experiment", Value: rawDisplayValue(f, exp[0]), Set: assignment(f) == Treatment})
+		}
+	}
+	defValue := f.DefValue
+	switch {
+	case defValue == "":
+	case isSecret(f):
+		defValue = redacted
+	case isHashed(f):
+		defValue = hashValue(defValue)
+	}
+	e.Layers = append(e.Layers, Layer{Source: "default", Value: defValue, Set: true})
+
+	for i, layer := range e.Layers {
+		if layer.Set {
+			e.Winner = i
+			break
+		}
+	}
+
+	// Anything set outside of envy and pflag.Parse won't match any layer.
+	if !f.Changed && f.Value.String() != f.DefValue {
+		_, fromEnv := f.Annotations[AnnotationSource]
+		_, fromFile := f.Annotations[AnnotationFile]
+		if !fromEnv && !fromFile && assignment(f) != Treatment {
+			e.Winner = -1
+		}
+	}
+	return e
+}
+
+// String renders the explanation as a small table, marking the winner.
+func (e Explanation) String() string {
+	b := &strings.Builder{}
+	tw := tabwriter.NewWriter(b, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "--%s\n", e.Flag)
+	for i, layer := range e.Layers {
+		mark := " "
+		if i == e.Winner {
+			mark = "*"
+		}
+		val := "<unset>"
+		if layer.Set {
+			val = layer.Value
+		}
+		fmt.Fprintf(tw, "%s %s\t%s\n", mark, layer.Source, val)
+	}
+	tw.Flush()
+	return b.String()
+}
@@ -0,0 +1,98 @@
+package envy_test
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/fernferret/envy"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExplain(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("https_proxy", "http://lower:3128")
+	os.Setenv("FOO_TOKEN", "hunter2")
+	pflag.CommandLine = pflag.NewFlagSet("test", pflag.PanicOnError)
+
+	envy.ProxyFlags()
+	pflag.String("token", "", "api token")
+	pflag.String("url", "http://localhost", "set the url")
+	pflag.Bool("once", false, "only once")
+	envy.Secret("token")
+	envy.Disable("once")
+	envy.Parse("FOO")
+	pflag.CommandLine.Parse([]string{"--once"})
+
+	e := envy.Explain("https-proxy")
+	assert.Equal(t, 2, e.Winner)
+	assert.Equal(t, []envy.Layer{
+		{Source: "flag", Value: "http://lower:3128"},
+		{Source: "env HTTPS_PROXY"},
+		{Source: "env https_proxy", Value: "http://lower:3128", Set: true},
+		{Source: "default", Value: "", Set: true},
+	}, e.Layers)
+
+	e = envy.Explain("token")
+	assert.Equal(t, envy.Layer{Source: "env FOO_TOKEN", Value: "<redacted>", Set: true}, e.Layers[1])
+
+	e = envy.Explain("once")
+	assert.Equal(t, 0, e.Winner)
+	assert.Len(t, e.Layers, 2)
+
+	e = envy.Explain("url")
+	assert.Equal(t, 2, e.Winner)
+
+	// Changed from code, not by any layer
+	pflag.Lookup("url").Value.Set("http://sneaky")
+	assert.Equal(t, -1, envy.Explain("url").Winner)
+
+	assert.PanicsWithError(t, "--missing: "+envy.ErrFlagNotExists.Error(), func() { envy.Explain("missing") })
+}
+
+func TestExplainMigrated(t *testing.T) {
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	fs.String("url", "def", "set the url")
+	fs.Int("workers", 1, "number of workers")
+	envy.ExperimentOnFlagSet("workers", "2", 100, fs)
+
+	e := envy.New(envy.WithFlagSet(fs), envy.WithPrefix("NEW"), envy.WithLookuper(envy.MapLookuper{"OLD_URL": "x"}),
+		envy.WithDeprecationHandler(func(flag, oldName, newName string) {}))
+	e.MigratePrefix("OLD", "NEW")
+	assert.NoError(t, e.ParseE())
+	assert.Equal(t, "x", fs.Lookup("url").Value.String())
+
+	ex := envy.ExplainFlagSet("url", fs)
+	assert.Equal(t, []envy.Layer{
+		{Source: "flag", Value: "x"},
+		{Source: "env NEW_URL"},
+		{Source: "env OLD_URL", Value: "x", Set: true},
+		{Source: "default", Value: "def", Set: true},
+	}, ex.Layers)
+	assert.Equal(t, 2, ex.Winner)
+
+	ex = envy.ExplainFlagSet("workers", fs)
+	assert.Equal(t, envy.Layer{Source: "experiment", Value: "2", Set: true}, ex.Layers[3])
+	assert.Equal(t, 3, ex.Winner)
+}
+
+func ExampleExplain() {
+	// Reset CommandLine flags for example, you don't need this in your code!
+	pflag.CommandLine = pflag.NewFlagSet("test", pflag.PanicOnError)
+	os.Clearenv()
+
+	pflag.String("url", "http://localhost:8080", "set the url")
+
+	// Simulate COOL_APP_URL being set
+	os.Setenv("COOL_APP_URL", "https://example.com")
+
+	envy.Parse("COOL_APP")
+	pflag.Parse()
+
+	fmt.Print(envy.Explain("url"))
+	// Output: --url
+	//   flag              <unset>
+	// * env COOL_APP_URL  https://example.com
+	//   default           http://localhost:8080
+}
@@ -0,0 +1,184 @@
+package envy
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+var (
+	ErrFeatureAlreadyDefined = errors.New("feature gate already exists")
+	ErrUnknownFeature        = errors.New("feature gate does not exist")
+	ErrFeatureLocked         = errors.New("feature gate is locked to its default")
+	ErrInvalidFeatureValue   = errors.New("feature gates must be given as a comma separated list of name=true|false")
+)
+
+// Maturity is the stage of a feature gate, shown in the flag usage.
+type Maturity int
+
+const (
+	Alpha Maturity = iota
+	Beta
+	GA
+	Deprecated
+)
+
+func (m Maturity) String() string {
+	switch m {
+	case Alpha:
+		return "ALPHA"
+	case Beta:
+		return "BETA"
+	case GA:
+		return "GA"
+	case Deprecated:
+		return "DEPRECATED"
+	}
+	return fmt.Sprintf("Maturity(%d)", int(m))
+}
+
+// FeatureSpec describes a single feature gate.
+type FeatureSpec struct {
+	Default  bool
+	Maturity Maturity
+
+	// Prevents the gate from being changed from its default, usually set once
+	// a feature has gone GA and the old code path has been removed.
+	LockToDefault bool
+}
+
+// FeatureGates is a registry of named boolean gates in the style of
+// Kubernetes' component-base. It implements pflag.Value so it can be set with
+// --feature-gates=Foo=true,Bar=false and, through envy, MYAPP_FEATURE_GATES.
+// Each Set merges into the existing values, so gates given on the command line
+// override the same gates from the environment while keeping the rest.
+type FeatureGates struct {
+	known   map[string]FeatureSpec
+	enabled map[string]bool
+
+	// The flags from AddFlag, whose usage lists every gate.
+	flags []*pflag.Flag
+}
+
+// NewFeatureGates returns an empty feature gate registry.
+func NewFeatureGates() *FeatureGates {
+	return &FeatureGates{
+		known:   map[string]FeatureSpec{},
+		enabled: map[string]bool{},
+	}
+}
+
+// Add registers a feature gate, panicking if it already exists. Gates added
+// after AddFlag still show up in the flag's usage.
+func (g *FeatureGates) Add(name string, spec FeatureSpec) {
+	if _, ok := g.known[name]; ok {
+		panic(ErrFeatureAlreadyDefined)
+	}
+	old := g.Usage()
+	g.known[name] = spec
+
+	// Swap the list of gates in place so any decoration envy already added
+	// to the usage is kept.
+	usage := g.Usage()
+	for _, f := range g.flags {
+		f.Usage = strings.Replace(f.Usage, old, usage, 1)
+		if orig, ok := f.Annotations[AnnotationUsage]; ok && orig[0] == old {
+			annotate(f, AnnotationUsage, usage)
+		}
+	}
+}
+
+// Enabled reports whether the named feature is enabled, panicking if it was
+// never added.
+func (g *FeatureGates) Enabled(name string) bool {
+	spec, ok := g.known[name]
+	if !ok {
+		panic(ErrUnknownFeature)
+	}
+	if val, ok := g.enabled[name]; ok {
+		return val
+	}
+	return spec.Default
+}
+
+// AddFlag registers the --feature-gates flag on the given FlagSet.
+func (g *FeatureGates) AddFlag(fs *pflag.FlagSet) {
+	fs.Var(g, "feature-gates", g.Usage())
+	g.flags = append(g.flags, fs.Lookup("feature-gates"))
+}
+
+// Usage describes the flag format along with every known gate.
+func (g *FeatureGates) Usage() string {
+	lines := []string{"a set of key=value pairs that describe feature gates, options are:"}
+	names := make([]string, 0, len(g.known))
+	for name := range g.known {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		spec := g.known[name]
+		lines = append(lines, fmt.Sprintf("%s=true|false (%s - default=%v)", name, spec.Maturity, spec.Default))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Set parses a comma separated list of name=bool pairs and merges them into
+// the gates. Nothing is changed if any pair is invalid. Enabling a Deprecated
+// gate logs a warning to the output from SetOutput.
+func (g *FeatureGates) Set(value string) error {
+	parsed := map[string]bool{}
+	for _, pair := range strings.Split(value, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		parts := strings.SplitN(pair, "=", 2)
+		if len(parts) != 2 {
+			return fmt.Errorf("%w: %q", ErrInvalidFeatureValue, pair)
+		}
+		name, val := strings.TrimSpace(parts[0]), parts[1]
+		spec, ok := g.known[name]
+		if !ok {
+			return fmt.Errorf("%w: %q", ErrUnknownFeature, name)
+		}
+		enabled, err := strconv.ParseBool(strings.TrimSpace(val))
+		if err != nil {
+			return fmt.Errorf("%w: %q", ErrInvalidFeatureValue, pair)
+		}
+		if spec.LockToDefault && enabled != spec.Default {
+			return fmt.Errorf("%w: %q", ErrFeatureLocked, name)
+		}
+		parsed[name] = enabled
+	}
+	names := make([]string, 0, len(parsed))
+	for name, enabled := range parsed {
+		g.enabled[name] = enabled
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if parsed[name] && g.known[name].Maturity == Deprecated {
+			logf("feature gate %s is deprecated", name)
+		}
+	}
+	return nil
+}
+
+// String returns the explicitly set gates, sorted by name.
+func (g *FeatureGates) String() string {
+	pairs := make([]string, 0, len(g.enabled))
+	for name, enabled := range g.enabled {
+		pairs = append(pairs, fmt.Sprintf("%s=%v", name, enabled))
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+// Type matches the name Kubernetes uses for this kind of flag.
+func (g *FeatureGates) Type() string {
+	return "mapStringBool"
+}
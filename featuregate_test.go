@@ -0,0 +1,143 @@
+package envy_test
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/fernferret/envy"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestGates() *envy.FeatureGates {
+	gates := envy.NewFeatureGates()
+	gates.Add("Turbo", envy.FeatureSpec{Maturity: envy.Alpha})
+	gates.Add("Cache", envy.FeatureSpec{Default: true, Maturity: envy.Beta})
+	gates.Add("Legacy", envy.FeatureSpec{Default: true, Maturity: envy.GA, LockToDefault: true})
+	return gates
+}
+
+func TestFeatureGatesSet(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		exp   map[string]bool
+		err   error
+	}{
+		{
+			name:  "test defaults",
+			value: "",
+			exp:   map[string]bool{"Turbo": false, "Cache": true, "Legacy": true},
+		},
+		{
+			name:  "test override",
+			value: "Turbo=true, Cache=false",
+			exp:   map[string]bool{"Turbo": true, "Cache": false, "Legacy": true},
+		},
+		{
+			name:  "test unknown gate",
+			value: "Turbo=true,Nope=true",
+			err:   envy.ErrUnknownFeature,
+			exp:   map[string]bool{"Turbo": false},
+		},
+		{
+			name:  "test invalid value",
+			value: "Turbo=yes",
+			err:   envy.ErrInvalidFeatureValue,
+		},
+		{
+			name:  "test missing value",
+			value: "Turbo",
+			err:   envy.ErrInvalidFeatureValue,
+		},
+		{
+			name:  "test locked gate",
+			value: "Legacy=false",
+			err:   envy.ErrFeatureLocked,
+			exp:   map[string]bool{"Legacy": true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gates := newTestGates()
+			err := gates.Set(tt.value)
+			assert.ErrorIs(t, err, tt.err)
+			for name, exp := range tt.exp {
+				assert.Equal(t, exp, gates.Enabled(name), name)
+			}
+		})
+	}
+}
+
+func TestFeatureGatesEnvAndFlag(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("FOO_FEATURE_GATES", "Turbo=true,Cache=false")
+	pflag.CommandLine = pflag.NewFlagSet("test", pflag.PanicOnError)
+
+	gates := newTestGates()
+	gates.AddFlag(pflag.CommandLine)
+
+	envy.Parse("FOO")
+	pflag.CommandLine.Parse([]string{"--feature-gates=Cache=true"})
+
+	// The flag wins for Cache while Turbo is kept from the environment
+	assert.True(t, gates.Enabled("Turbo"))
+	assert.True(t, gates.Enabled("Cache"))
+	assert.Equal(t, "Cache=true,Turbo=true", gates.String())
+}
+
+func TestFeatureGatesDeprecated(t *testing.T) {
+	buf := &bytes.Buffer{}
+	envy.SetOutput(buf)
+	defer envy.SetOutput(os.Stderr)
+
+	gates := newTestGates()
+	gates.Add("OldCache", envy.FeatureSpec{Maturity: envy.Deprecated})
+
+	assert.NoError(t, gates.Set("OldCache=false"))
+	assert.Empty(t, buf.String())
+	assert.NoError(t, gates.Set("OldCache=true,Turbo=true"))
+	assert.Equal(t, "envy: feature gate OldCache is deprecated\n", buf.String())
+}
+
+func TestFeatureGatesAddedLater(t *testing.T) {
+	os.Clearenv()
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+
+	gates := envy.NewFeatureGates()
+	gates.Add("Turbo", envy.FeatureSpec{Maturity: envy.Alpha})
+	gates.AddFlag(fs)
+	envy.ParseFlagSet("FOO", fs)
+	gates.Add("Cache", envy.FeatureSpec{Default: true, Maturity: envy.Beta})
+
+	assert.Equal(t, gates.Usage()+" [FOO_FEATURE_GATES]", fs.Lookup("feature-gates").Usage)
+	assert.Contains(t, fs.FlagUsages(), "Cache=true|false (BETA - default=true)")
+	assert.Equal(t, gates.Usage(), envy.SchemaFlagSet(fs)[0].Usage)
+}
+
+func TestFeatureGatesPanics(t *testing.T) {
+	gates := newTestGates()
+
+	assert.Panics(t, func() { gates.Enabled("Nope") })
+	assert.Panics(t, func() { gates.Add("Turbo", envy.FeatureSpec{}) })
+}
+
+func ExampleFeatureGates() {
+	// Reset CommandLine flags for example, you don't need this in your code!
+	pflag.CommandLine = pflag.NewFlagSet("test", pflag.PanicOnError)
+	os.Clearenv()
+
+	gates := envy.NewFeatureGates()
+	gates.Add("Turbo", envy.FeatureSpec{Maturity: envy.Alpha})
+	gates.Add("Cache", envy.FeatureSpec{Default: true, Maturity: envy.Beta})
+	gates.AddFlag(pflag.CommandLine)
+
+	envy.Parse("COOL_APP")
+
+	pflag.CommandLine.SetOutput(os.Stdout)
+	pflag.PrintDefaults()
+	// Output: --feature-gates mapStringBool   a set of key=value pairs that describe feature gates, options are:
+	//                                       Cache=true|false (BETA - default=true)
+	//                                       Turbo=true|false (ALPHA - default=false) [COOL_APP_FEATURE_GATES]
+}
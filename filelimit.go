@@ -0,0 +1,93 @@
+package envy
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+var (
+	ErrFileTooLarge     = errors.New("file is larger than the limit")
+	ErrFileHashMismatch = errors.New("file does not match the expected SHA-256")
+)
+
+// Used to record the SHA-256 a flag's _FILE must match.
+const AnnotationFileHash = "envy_file_hash"
+
+// SetMaxFileSize limits how many bytes envy reads from files named by _FILE
+// variables, see SetFileSuffix, so a variable pointing at the wrong file
+// can't exhaust memory. Larger files are reported naming the flag and path.
+// Zero, the default, means no limit. It must be called before the call to
+// envy.Parse().
+func SetMaxFileSize(n int64) {
+	std.maxFileSize = n
+}
+
+// WithMaxFileSize works like SetMaxFileSize for this Envy only.
+func WithMaxFileSize(n int64) Option {
+	return func(e *Envy) {
+		e.maxFileSize = n
+	}
+}
+
+// VerifyFileHash requires the file a flag in the default pflag.CommandLine is
+// read from to match a SHA-256, see VerifyFileHashOnFlagSet.
+func VerifyFileHash(name, sum string) {
+	VerifyFileHashOnFlagSet(name, sum, pflag.CommandLine)
+}
+
+// VerifyFileHashOnFlagSet requires the file named by the flag's _FILE
+// variable to have the given SHA-256, as printed by sha256sum and optionally
+// prefixed with sha256:, so a large certificate bundle or schema is known to
+// be the right one. The hash is computed while the file is read and a
+// mismatch is reported naming the flag and path. It must be called before the
+// call to envy.Parse().
+func VerifyFileHashOnFlagSet(name, sum string, fs *pflag.FlagSet) {
+	std.on("", fs).VerifyFileHash(name, sum)
+}
+
+// VerifyFileHash requires the file the flag is read from to match a SHA-256,
+// see VerifyFileHashOnFlagSet.
+func (e *Envy) VerifyFileHash(name, sum string) {
+	f := e.fs.Lookup(name)
+	if f == nil {
+		e.fail(&SetError{Flag: name, Err: ErrFlagNotExists})
+		return
+	}
+	annotate(f, AnnotationFileHash, strings.ToLower(strings.TrimPrefix(sum, "sha256:")))
+}
+
+// readFlagFile reads the flag's value from a file named by a _FILE variable,
+// enforcing the size limit and the flag's expected hash.
+func (e *Envy) readFlagFile(f *pflag.Flag, path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	var r io.Reader = file
+	if e.maxFileSize > 0 {
+		// Read one byte more than allowed to tell a file that's exactly at
+		// the limit from one that's over it.
+		r = io.LimitReader(file, e.maxFileSize+1)
+	}
+	h := sha256.New()
+	data, err := io.ReadAll(io.TeeReader(r, h))
+	if err != nil {
+		return "", err
+	}
+	if e.maxFileSize > 0 && int64(len(data)) > e.maxFileSize {
+		return "", fmt.Errorf("%s: %w of %d bytes", path, ErrFileTooLarge, e.maxFileSize)
+	}
+	if want, ok := f.Annotations[AnnotationFileHash]; ok && hex.EncodeToString(h.Sum(nil)) != want[0] {
+		return "", fmt.Errorf("%s: %w", path, ErrFileHashMismatch)
+	}
+	return trimNewline(string(data)), nil
+}
@@ -0,0 +1,80 @@
+package envy_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"testing"
+
+	"github.com/fernferret/envy"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithMaxFileSize(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		err  error
+	}{
+		{"under", "abc", nil},
+		{"at limit", "abcd", nil},
+		{"over", "abcde", envy.ErrFileTooLarge},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			os.Clearenv()
+			path := writeFile(t, "ca", tt.data)
+			os.Setenv("MYAPP_CA_FILE", path)
+
+			fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+			ca := fs.String("ca", "", "ca bundle")
+			e := envy.New(envy.WithPrefix("MYAPP"), envy.WithFlagSet(fs), envy.WithFileSuffix(true), envy.WithMaxFileSize(4))
+			err := e.ParseE()
+			if tt.err == nil {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.data, *ca)
+				return
+			}
+			assert.ErrorIs(t, err, tt.err)
+			assert.EqualError(t, err, "--ca from MYAPP_CA_FILE: "+path+": file is larger than the limit of 4 bytes")
+		})
+	}
+}
+
+func TestVerifyFileHash(t *testing.T) {
+	data := "-----BEGIN CERTIFICATE-----\n"
+	sum := sha256.Sum256([]byte(data))
+	good := hex.EncodeToString(sum[:])
+
+	tests := []struct {
+		name string
+		sum  string
+		err  error
+	}{
+		{"match", good, nil},
+		{"prefixed", "sha256:" + good, nil},
+		{"mismatch", "sha256:" + good[1:] + "0", envy.ErrFileHashMismatch},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			os.Clearenv()
+			path := writeFile(t, "ca", data)
+			os.Setenv("MYAPP_CA_FILE", path)
+
+			fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+			ca := fs.String("ca", "", "ca bundle")
+			e := envy.New(envy.WithPrefix("MYAPP"), envy.WithFlagSet(fs))
+			e.FileSuffix("ca")
+			e.VerifyFileHash("ca", tt.sum)
+			err := e.ParseE()
+			if tt.err == nil {
+				assert.NoError(t, err)
+				assert.Equal(t, "-----BEGIN CERTIFICATE-----", *ca)
+				return
+			}
+			assert.ErrorIs(t, err, tt.err)
+			assert.Contains(t, err.Error(), "--ca from MYAPP_CA_FILE: "+path)
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package envy
+
+import (
+	"os"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+// Used to read the flag from a file named by its variable with a _FILE suffix.
+const AnnotationFileSuffix = "envy_file_suffix"
+
+// SetFileSuffix enables the Docker convention where FOO_PASSWORD_FILE names a
+// file whose contents are used as FOO_PASSWORD, for every flag. This lets
+// secrets mounted as files work without application changes. A variable set
+// directly still wins over its _FILE variant. It must be called before the
+// call to envy.Parse().
+func SetFileSuffix(on bool) {
+	std.fileSuffix = on
+}
+
+// WithFileSuffix works like SetFileSuffix for this Envy only.
+func WithFileSuffix(on bool) Option {
+	return func(e *Envy) {
+		e.fileSuffix = on
+	}
+}
+
+// FileSuffix enables the _FILE convention for a single flag in the default
+// pflag.CommandLine, see SetFileSuffix. It must be called before the call to
+// envy.Parse().
+func FileSuffix(name string) {
+	FileSuffixOnFlagSet(name, pflag.CommandLine)
+}
+
+// FileSuffixOnFlagSet enables the _FILE convention for a single flag in the
+// given FlagSet, see SetFileSuffix. It must be called before the call to
+// envy.Parse().
+func FileSuffixOnFlagSet(name string, fs *pflag.FlagSet) {
+	std.on("", fs).FileSuffix(name)
+}
+
+// FileSuffix enables the _FILE convention for a single flag, see
+// FileSuffixOnFlagSet.
+func (e *Envy) FileSuffix(name string) {
+	f := e.fs.Lookup(name)
+	if f == nil {
+		e.fail(&SetError{Flag: name, Err: ErrFlagNotExists})
+		return
+	}
+	annotate(f, AnnotationFileSuffix, "true")
+}
+
+// fileSuffixFor reports whether the flag can be read from a _FILE variable.
+func (e *Envy) fileSuffixFor(f *pflag.Flag) bool {
+	_, ok := f.Annotations[AnnotationFileSuffix]
+	return ok || e.fileSuffix
+}
+
+// lookupFlag returns the first of the flag's variables that is set, falling
+// back to its secret path, the variables' _FILE variants and the secrets dir
+// if enabled. If none are, the first name is returned for use in the flag's
+// usage.
+func (e *Envy) lookupFlag(names []string, f *pflag.Flag) (string, string, bool, error) {
+	envName, val, ok := e.lookupAny(names)
+	if ok {
+		return envName, val, true, nil
+	}
+	if path, ok := f.Annotations[AnnotationSecretPath]; ok {
+		if val, ok := e.lookuper.Lookup(path[0]); ok {
+			return path[0], val, true, nil
+		}
+	}
+	if e.fileSuffixFor(f) {
+		for _, name := range names {
+			if path, ok := e.lookuper.Lookup(name + "_FILE"); ok {
+				val, err := e.readFlagFile(f, path)
+				return name + "_FILE", val, true, err
+			}
+		}
+	}
+	if path, val, ok, err := e.lookupSecretFile(names, f); ok {
+		return path, val, true, err
+	}
+	return envName, "", false, nil
+}
+
+// readValueFile reads a value from a file, see trimNewline.
+func readValueFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return trimNewline(string(data)), nil
+}
+
+// trimNewline drops the trailing newline most editors and `echo` add.
+func trimNewline(val string) string {
+	val = strings.TrimSuffix(val, "\n")
+	return strings.TrimSuffix(val, "\r")
+}
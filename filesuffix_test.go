@@ -0,0 +1,53 @@
+package envy_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/fernferret/envy"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileSuffix(t *testing.T) {
+	os.Clearenv()
+	password := writeFile(t, "password", "hunter2\n")
+	os.Setenv("MYAPP_PASSWORD_FILE", password)
+	os.Setenv("MYAPP_USER", "direct")
+	os.Setenv("MYAPP_USER_FILE", writeFile(t, "user", "from-file"))
+	os.Setenv("MYAPP_OTHER_FILE", password)
+
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	pass := fs.String("password", "", "password")
+	user := fs.String("user", "", "user")
+	other := fs.String("other", "", "other")
+	e := envy.New(envy.WithPrefix("MYAPP"), envy.WithFlagSet(fs))
+	e.FileSuffix("password")
+	e.FileSuffix("user")
+	e.Secret("password")
+	assert.NoError(t, e.ParseE())
+
+	assert.Equal(t, "hunter2", *pass)
+	assert.Equal(t, "direct", *user)
+	assert.Equal(t, "", *other)
+	assert.Equal(t, "MYAPP_PASSWORD_FILE", envy.SourcesFlagSet(fs)[1].EnvName)
+
+	x := envy.ExplainFlagSet("password", fs)
+	assert.Equal(t, envy.Layer{Source: "env MYAPP_PASSWORD_FILE", Value: password, Set: true}, x.Layers[x.Winner])
+}
+
+func TestWithFileSuffix(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("MYAPP_NAME_FILE", writeFile(t, "name", "from-file\r\n"))
+	os.Setenv("MYAPP_COUNT_FILE", filepath.Join(t.TempDir(), "missing"))
+
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	name := fs.String("name", "", "name")
+	fs.Int("count", 1, "count")
+	e := envy.New(envy.WithPrefix("MYAPP"), envy.WithFlagSet(fs), envy.WithFileSuffix(true))
+	err := e.ParseE()
+	assert.Equal(t, "from-file", *name)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+	assert.Contains(t, err.Error(), "--count from MYAPP_COUNT_FILE: ")
+}
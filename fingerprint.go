@@ -0,0 +1,88 @@
+package envy
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+
+	"github.com/spf13/pflag"
+)
+
+// FingerprintStore is somewhere replicas of a service share the fingerprint of
+// their configuration, like a Redis hash, a ConfigMap or a database table.
+type FingerprintStore interface {
+	// Publish records the fingerprint of one instance.
+	Publish(ctx context.Context, instance, fingerprint string) error
+
+	// Fingerprints returns the last fingerprint published by every instance.
+	Fingerprints(ctx context.Context) (map[string]string, error)
+}
+
+// Fingerprint returns a fingerprint of the configuration of the default
+// pflag.CommandLine, see FingerprintFlagSet.
+func Fingerprint() string {
+	return FingerprintFlagSet(pflag.CommandLine)
+}
+
+// FingerprintFlagSet returns a SHA-256 of the value of every flag in the given
+// FlagSet as sha256:<hex>. Replicas that resolved the same configuration have
+// the same fingerprint, without sharing any values. Secrets only count as set
+// or unset unless every replica uses the same SetSecretKey. It must be called
+// after pflag.Parse().
+func FingerprintFlagSet(fs *pflag.FlagSet) string {
+	// Maps are marshaled with sorted keys, so the result is stable.
+	data, _ := json.Marshal(dump(fs, instanceFor(fs).secretKey))
+	return hashValue(string(data))
+}
+
+// Diverged returns the instances whose fingerprint differs from the one shared
+// by most instances, sorted. Ties are broken by picking the smallest
+// fingerprint so every replica reaches the same answer.
+func Diverged(fingerprints map[string]string) []string {
+	counts := map[string]int{}
+	for _, fp := range fingerprints {
+		counts[fp]++
+	}
+	var common string
+	for fp, n := range counts {
+		if n > counts[common] || (n == counts[common] && fp < common) {
+			common = fp
+		}
+	}
+
+	var diverged []string
+	for instance, fp := range fingerprints {
+		if fp != common {
+			diverged = append(diverged, instance)
+		}
+	}
+	sort.Strings(diverged)
+	return diverged
+}
+
+// CheckReplicas compares the default pflag.CommandLine with its replicas, see
+// CheckReplicasFlagSet.
+func CheckReplicas(ctx context.Context, store FingerprintStore) ([]string, error) {
+	return CheckReplicasFlagSet(ctx, store, pflag.CommandLine)
+}
+
+// CheckReplicasFlagSet publishes the fingerprint of the given FlagSet to the
+// store under the instance ID, see SetInstanceID, and returns the instances
+// that disagree with the majority of their replicas, catching the one pod with
+// a stray environment override. Each one is also logged to the output from
+// SetOutput. It must be called after pflag.Parse().
+func CheckReplicasFlagSet(ctx context.Context, store FingerprintStore, fs *pflag.FlagSet) ([]string, error) {
+	if err := store.Publish(ctx, instanceID, FingerprintFlagSet(fs)); err != nil {
+		return nil, err
+	}
+	fingerprints, err := store.Fingerprints(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	diverged := Diverged(fingerprints)
+	for _, instance := range diverged {
+		logf("instance %s has diverged from its replicas, fingerprint %s", instance, fingerprints[instance])
+	}
+	return diverged, nil
+}
@@ -0,0 +1,114 @@
+package envy_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/fernferret/envy"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+)
+
+type memoryStore struct {
+	fingerprints map[string]string
+	err          error
+}
+
+func (m *memoryStore) Publish(ctx context.Context, instance, fingerprint string) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.fingerprints[instance] = fingerprint
+	return nil
+}
+
+func (m *memoryStore) Fingerprints(ctx context.Context) (map[string]string, error) {
+	return m.fingerprints, m.err
+}
+
+func TestFingerprint(t *testing.T) {
+	envy.SetSecretKey([]byte("fleet-key"))
+	defer envy.SetSecretKey(nil)
+
+	os.Clearenv()
+	os.Setenv("FOO_TOKEN", "hunter2")
+	pflag.CommandLine = pflag.NewFlagSet("test", pflag.PanicOnError)
+	pflag.String("url", "http://localhost", "set the url")
+	pflag.String("token", "", "api token")
+	envy.Secret("token")
+	envy.Parse("FOO")
+
+	fp := envy.Fingerprint()
+	assert.Regexp(t, `^sha256:[0-9a-f]{64}$`, fp)
+	assert.Equal(t, fp, envy.Fingerprint())
+
+	pflag.Set("token", "rotated")
+	rotated := envy.Fingerprint()
+	assert.NotEqual(t, fp, rotated)
+
+	pflag.Set("url", "http://example.com")
+	assert.NotEqual(t, rotated, envy.Fingerprint())
+}
+
+func TestFingerprintWithoutKey(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("FOO_TOKEN", "hunter2")
+	pflag.CommandLine = pflag.NewFlagSet("test", pflag.PanicOnError)
+	pflag.String("token", "", "api token")
+	envy.Secret("token")
+	envy.Parse("FOO")
+
+	// Without a key only whether the secret is set counts
+	fp := envy.Fingerprint()
+	pflag.Set("token", "rotated")
+	assert.Equal(t, fp, envy.Fingerprint())
+	pflag.Set("token", "")
+	assert.NotEqual(t, fp, envy.Fingerprint())
+}
+
+func TestDiverged(t *testing.T) {
+	tests := []struct {
+		name         string
+		fingerprints map[string]string
+		expected     []string
+	}{
+		{"empty", nil, nil},
+		{"agree", map[string]string{"a": "x", "b": "x"}, nil},
+		{"one stray", map[string]string{"a": "x", "b": "y", "c": "x"}, []string{"b"}},
+		{"tie", map[string]string{"a": "y", "b": "x"}, []string{"a"}},
+		{"split", map[string]string{"a": "x", "b": "y", "c": "z", "d": "y"}, []string{"a", "c"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, envy.Diverged(test.fingerprints))
+		})
+	}
+}
+
+func TestCheckReplicas(t *testing.T) {
+	buf := &bytes.Buffer{}
+	envy.SetOutput(buf)
+	defer envy.SetOutput(os.Stderr)
+	envy.SetInstanceID("pod-a")
+	defer envy.SetInstanceID("")
+
+	os.Clearenv()
+	pflag.CommandLine = pflag.NewFlagSet("test", pflag.PanicOnError)
+	pflag.String("url", "http://localhost", "set the url")
+	envy.Parse("FOO")
+	fp := envy.Fingerprint()
+
+	store := &memoryStore{fingerprints: map[string]string{"pod-b": fp, "pod-c": "sha256:stray"}}
+	diverged, err := envy.CheckReplicas(context.Background(), store)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"pod-c"}, diverged)
+	assert.Equal(t, fp, store.fingerprints["pod-a"])
+	assert.Equal(t, "envy: instance pod-c has diverged from its replicas, fingerprint sha256:stray\n", buf.String())
+
+	store.err = errors.New("unavailable")
+	_, err = envy.CheckReplicas(context.Background(), store)
+	assert.EqualError(t, err, "unavailable")
+}
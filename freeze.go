@@ -0,0 +1,164 @@
+package envy
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+var ErrFrozen = errors.New("flag values are frozen")
+
+// FreezeMode controls what Finalize does to flag values, see SetFreeze.
+type FreezeMode int
+
+const (
+	// FreezeOff leaves flags settable after Finalize.
+	FreezeOff FreezeMode = iota
+
+	// FreezeLog rejects any later Set and logs it, see SetOutput.
+	FreezeLog
+
+	// FreezePanic panics on any later Set.
+	FreezePanic
+)
+
+// The values each FlagSet was frozen with.
+var frozen registry[*pflag.FlagSet, map[string]string]
+
+// The key secrets are frozen with. Snapshots never leave the process, so a
+// random one still catches changes without exposing them in drift logs.
+var freezeKey = func() []byte {
+	key := make([]byte, 32)
+	rand.Read(key)
+	return key
+}()
+
+// frozenValue rejects changes to the value it wraps.
+type frozenValue struct {
+	pflag.Value
+	name string
+	mode FreezeMode
+}
+
+func (v *frozenValue) Set(val string) error {
+	err := fmt.Errorf("%w: --%s can't be changed after Finalize", ErrFrozen, v.name)
+	if v.mode == FreezePanic {
+		panic(err)
+	}
+	logf("%v", err)
+	return err
+}
+
+func (v *frozenValue) unwrap() pflag.Value {
+	return v.Value
+}
+
+// guard rejects changes to slices like Set does.
+func (v *frozenValue) guard(vals []string, _ func() error) error {
+	return v.Set(strings.Join(vals, ","))
+}
+
+// SetFreeze controls whether Finalize freezes flag values so later attempts to
+// Set them are caught. It must be called before the call to envy.Finalize().
+func SetFreeze(mode FreezeMode) {
+	std.freeze = mode
+}
+
+// Finalize runs envy's checks that need the command line to have been parsed,
+// see FinalizeFlagSet.
+func Finalize() error {
+	return FinalizeFlagSet(pflag.CommandLine)
+}
+
+// FinalizeFlagSet runs envy's checks that need the command line to have been
+// parsed, so it must be called after pflag.Parse(). Flags marked with Require
+// that weren't set are returned as a ParseErrors. Flags from RuntimeFlags are
+// applied to the Go runtime. If SetFreeze was used the flag values are frozen
+// and can be checked later with VerifyFrozen.
+func FinalizeFlagSet(fs *pflag.FlagSet) error {
+	return std.on("", fs).Finalize()
+}
+
+// Finalize runs envy's checks that need the command line to have been parsed,
+// see FinalizeFlagSet.
+func (e *Envy) Finalize() error {
+	if errs := e.missing(); len(errs) > 0 {
+		return errs
+	}
+	if t, ok := tunings.take(e.fs); ok {
+		if err := t.Apply(); err != nil {
+			return err
+		}
+	}
+	if e.freeze != FreezeOff {
+		visitAll(e.fs, func(f *pflag.Flag) {
+			if _, ok := wrapped[*frozenValue](f); !ok {
+				f.Value = wrap(&frozenValue{Value: f.Value, name: f.Name, mode: e.freeze})
+			}
+		})
+		frozen.set(e.fs, dump(e.fs, freezeKey))
+	}
+	return nil
+}
+
+// VerifyFrozen compares the default pflag.CommandLine against the values it
+// was frozen with, see VerifyFrozenFlagSet.
+func VerifyFrozen() []Drift {
+	return VerifyFrozenFlagSet(pflag.CommandLine)
+}
+
+// VerifyFrozenFlagSet compares the given FlagSet against the values it was
+// frozen with by FinalizeFlagSet. Freezing only catches calls to Set, this
+// also catches code writing straight through the pointers flags are bound to.
+func VerifyFrozenFlagSet(fs *pflag.FlagSet) []Drift {
+	snapshot, ok := frozen.get(fs)
+	if !ok {
+		return nil
+	}
+	var drifts []Drift
+	for name, val := range dump(fs, freezeKey) {
+		if snapshot[name] != val {
+			drifts = append(drifts, Drift{Flag: name, Baseline: snapshot[name], Current: val})
+		}
+	}
+	sort.Slice(drifts, func(i, j int) bool {
+		return drifts[i].Flag < drifts[j].Flag
+	})
+	return drifts
+}
+
+// WatchFrozen periodically verifies the default pflag.CommandLine, see
+// WatchFrozenFlagSet.
+func WatchFrozen(ctx context.Context, interval time.Duration) {
+	WatchFrozenFlagSet(ctx, interval, pflag.CommandLine)
+}
+
+// WatchFrozenFlagSet calls VerifyFrozenFlagSet every interval until the
+// context is done, logging any differences to the output from SetOutput. It
+// blocks, so run it in a goroutine.
+func WatchFrozenFlagSet(ctx context.Context, interval time.Duration, fs *pflag.FlagSet) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			for _, d := range VerifyFrozenFlagSet(fs) {
+				logf("frozen %s", d)
+			}
+		}
+	}
+}
+
+// valueOf returns the flag's value without any wrapping added by envy, so
+// optional interfaces like Redactor can be checked.
+func valueOf(f *pflag.Flag) pflag.Value {
+	return innermost(f.Value)
+}
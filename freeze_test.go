@@ -0,0 +1,110 @@
+package envy_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/fernferret/envy"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFreeze(t *testing.T) {
+	defer envy.SetFreeze(envy.FreezeOff)
+
+	tests := []struct {
+		name string
+		mode envy.FreezeMode
+	}{
+		{name: "test freeze log", mode: envy.FreezeLog},
+		{name: "test freeze panic", mode: envy.FreezePanic},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			os.Clearenv()
+			pflag.CommandLine = pflag.NewFlagSet("test", pflag.PanicOnError)
+
+			url := pflag.String("url", "http://localhost", "set the url")
+			envy.Parse("FOO")
+			pflag.CommandLine.Parse([]string{"--url=http://example.com"})
+
+			envy.SetFreeze(tt.mode)
+			assert.NoError(t, envy.Finalize())
+
+			if tt.mode == envy.FreezePanic {
+				assert.Panics(t, func() { pflag.Set("url", "http://other") })
+			} else {
+				assert.ErrorIs(t, pflag.Lookup("url").Value.Set("http://other"), envy.ErrFrozen)
+			}
+			assert.Equal(t, "http://example.com", *url)
+			assert.Empty(t, envy.VerifyFrozen())
+
+			// Writing through the pointer can only be caught by verifying
+			*url = "http://sneaky"
+			drifts := envy.VerifyFrozen()
+			if assert.Len(t, drifts, 1) {
+				assert.Equal(t, `--url changed from "http://example.com" to "http://sneaky"`, drifts[0].String())
+			}
+		})
+	}
+}
+
+func TestFreezeKeepsRedaction(t *testing.T) {
+	defer envy.SetFreeze(envy.FreezeOff)
+
+	os.Clearenv()
+	os.Setenv("FOO_DATABASE_URL", "postgres://app:hunter2@db/app")
+	pflag.CommandLine = pflag.NewFlagSet("test", pflag.PanicOnError)
+
+	var dsn envy.DSN
+	envy.DSNVar(&dsn, "database-url", "postgres", "database")
+	envy.Parse("FOO")
+	pflag.Parse()
+
+	envy.SetFreeze(envy.FreezeLog)
+	assert.NoError(t, envy.Finalize())
+
+	env := envy.Environ()
+	assert.Contains(t, env, "FOO_DATABASE_URL=postgres://app:hunter2@db/app")
+	assert.Empty(t, envy.VerifyFrozen())
+}
+
+func TestFreezeOff(t *testing.T) {
+	os.Clearenv()
+	pflag.CommandLine = pflag.NewFlagSet("test", pflag.PanicOnError)
+
+	url := pflag.String("url", "http://localhost", "set the url")
+	envy.Parse("FOO")
+	pflag.Parse()
+
+	assert.NoError(t, envy.Finalize())
+	assert.NoError(t, pflag.Set("url", "http://other"))
+	assert.Equal(t, "http://other", *url)
+	assert.Nil(t, envy.VerifyFrozen())
+}
+
+func TestFreezeKeepsInterfaces(t *testing.T) {
+	defer envy.SetFreeze(envy.FreezeOff)
+
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	verbose := fs.Bool("verbose", false, "be verbose")
+	tags := fs.StringSlice("tags", []string{"a"}, "tags")
+	envy.ParseFlagSet("FOO", fs)
+	if err := fs.Parse(nil); err != nil {
+		t.Fatal(err)
+	}
+	envy.SetFreeze(envy.FreezeLog)
+	assert.NoError(t, envy.FinalizeFlagSet(fs))
+
+	b, ok := fs.Lookup("verbose").Value.(interface{ IsBoolFlag() bool })
+	assert.True(t, ok && b.IsBoolFlag())
+	s, ok := fs.Lookup("tags").Value.(pflag.SliceValue)
+	if !ok {
+		t.Fatal("expected a pflag.SliceValue")
+	}
+	assert.Equal(t, []string{"a"}, s.GetSlice())
+	assert.ErrorIs(t, s.Replace([]string{"b"}), envy.ErrFrozen)
+	assert.ErrorIs(t, s.Append("b"), envy.ErrFrozen)
+	assert.Equal(t, []string{"a"}, *tags)
+	assert.False(t, *verbose)
+}
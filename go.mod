@@ -1,14 +1,14 @@
 module github.com/fernferret/envy
 
-go 1.17
+go 1.20
 
 require (
 	github.com/spf13/pflag v1.0.5
 	github.com/stretchr/testify v1.7.1
+	gopkg.in/yaml.v3 v3.0.0
 )
 
 require (
 	github.com/davecgh/go-spew v1.1.0 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
-	gopkg.in/yaml.v3 v3.0.0 // indirect
 )
@@ -0,0 +1,95 @@
+package envy
+
+import (
+	"flag"
+	"os"
+
+	"github.com/spf13/pflag"
+)
+
+// AsGoFlagSet returns a stdlib flag.FlagSet view of the given FlagSet for
+// libraries and test binaries that only understand the flag package. Every
+// flag is shared rather than copied, so values envy read from the environment
+// show through and anything set through the view is marked as changed in the
+// FlagSet. Shorthands aren't carried over.
+func AsGoFlagSet(fs *pflag.FlagSet) *flag.FlagSet {
+	// pflag doesn't expose the FlagSet's name, so use the program's like
+	// flag.CommandLine does.
+	gfs := flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
+	visitAll(fs, func(f *pflag.Flag) {
+		gfs.Var(&goFlagValue{fs: fs, f: f}, f.Name, f.Usage)
+		gfs.Lookup(f.Name).DefValue = f.DefValue
+	})
+	return gfs
+}
+
+// goFlagValue forwards a stdlib flag to a pflag flag.
+type goFlagValue struct {
+	fs *pflag.FlagSet
+	f  *pflag.Flag
+}
+
+// String may be called on a zero goFlagValue by flag.PrintDefaults.
+func (v *goFlagValue) String() string {
+	if v.f == nil {
+		return ""
+	}
+	return v.f.Value.String()
+}
+
+func (v *goFlagValue) Set(val string) error {
+	return v.fs.Set(v.f.Name, val)
+}
+
+// IsBoolFlag lets bool flags be given without a value, like -verbose.
+func (v *goFlagValue) IsBoolFlag() bool {
+	return v.f != nil && v.f.NoOptDefVal == "true"
+}
+
+// The pflag views envy keeps of each stdlib flag.FlagSet, holding the
+// annotations the flag package has no room for.
+var stdFlagSets registry[*flag.FlagSet, *pflag.FlagSet]
+
+// StdFlagSet returns the pflag view envy keeps of a stdlib flag.FlagSet, so
+// DisableOnFlagSet, SetEnvNameOnFlagSet, SourcesFlagSet and friends can be
+// used on it. Every flag is shared, setting one through the view sets the
+// stdlib flag. Flags defined after the first call are added on the next one.
+func StdFlagSet(gfs *flag.FlagSet) *pflag.FlagSet {
+	fs := stdFlagSets.update(gfs, func(fs *pflag.FlagSet) *pflag.FlagSet {
+		if fs == nil {
+			fs = pflag.NewFlagSet(gfs.Name(), pflag.ContinueOnError)
+		}
+		return fs
+	})
+	fs.AddGoFlagSet(gfs)
+	return fs
+}
+
+// ParseStdFlagSet binds every flag of a stdlib flag.FlagSet to the
+// environment like ParseFlagSet, for projects that can't adopt pflag. The
+// usage of each flag is decorated just the same. It must be called before
+// gfs.Parse().
+func ParseStdFlagSet(pfx string, gfs *flag.FlagSet) {
+	fs := StdFlagSet(gfs)
+	defer copyUsage(fs, gfs)
+	ParseFlagSet(pfx, fs)
+}
+
+// ParseStdFlagSetE works like ParseStdFlagSet but returns every value that
+// couldn't be set instead of panicking, see ParseFlagSetE.
+func ParseStdFlagSetE(pfx string, gfs *flag.FlagSet) error {
+	fs := StdFlagSet(gfs)
+	defer copyUsage(fs, gfs)
+	return ParseFlagSetE(pfx, fs)
+}
+
+// copyUsage copies the decorated usage and defaults from the view back to
+// the stdlib flags.
+func copyUsage(fs *pflag.FlagSet, gfs *flag.FlagSet) {
+	gfs.VisitAll(func(gf *flag.Flag) {
+		if f := fs.Lookup(gf.Name); f != nil {
+			gf.Usage = f.Usage
+			gf.DefValue = f.DefValue
+		}
+	})
+}
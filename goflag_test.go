@@ -0,0 +1,73 @@
+package envy_test
+
+import (
+	"flag"
+	"os"
+	"testing"
+
+	"github.com/fernferret/envy"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAsGoFlagSet(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("FOO_URL", "http://example.com")
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	url := fs.String("url", "http://localhost", "set the url")
+	verbose := fs.BoolP("verbose", "v", false, "verbose output")
+	workers := fs.Int("workers", 4, "number of workers")
+	envy.ParseFlagSet("FOO", fs)
+
+	gfs := envy.AsGoFlagSet(fs)
+	assert.Equal(t, os.Args[0], gfs.Name())
+	assert.Equal(t, "http://example.com", gfs.Lookup("url").Value.String())
+	assert.Equal(t, "http://localhost", gfs.Lookup("url").DefValue)
+	assert.Equal(t, "set the url [FOO_URL http://example.com]", gfs.Lookup("url").Usage)
+	assert.Nil(t, gfs.Lookup("v"))
+
+	assert.NoError(t, gfs.Parse([]string{"-verbose", "-workers=8", "rest"}))
+	assert.True(t, *verbose)
+	assert.Equal(t, 8, *workers)
+	assert.Equal(t, "http://example.com", *url)
+	assert.True(t, fs.Lookup("workers").Changed)
+	assert.False(t, fs.Lookup("url").Changed)
+	assert.Equal(t, []string{"rest"}, gfs.Args())
+
+	assert.Error(t, gfs.Parse([]string{"-workers=many"}))
+}
+
+func TestParseStdFlagSet(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("FOO_URL", "http://example.com")
+	os.Setenv("FOO_VERBOSE", "true")
+	os.Setenv("FOO_WORKERS", "8")
+	os.Setenv("FOO_ONCE", "true")
+	os.Setenv("KUBECONFIG", "/etc/kube")
+
+	gfs := flag.NewFlagSet("test", flag.ContinueOnError)
+	url := gfs.String("url", "http://localhost", "set the url")
+	verbose := gfs.Bool("verbose", false, "verbose output")
+	workers := gfs.Int("workers", 4, "number of workers")
+	once := gfs.Bool("once", false, "only once")
+	kube := gfs.String("kube-config", "", "kube config")
+	envy.DisableOnFlagSet("once", envy.StdFlagSet(gfs))
+	envy.SetEnvNameOnFlagSet("kube-config", "KUBECONFIG", envy.StdFlagSet(gfs))
+	envy.ParseStdFlagSet("FOO", gfs)
+
+	assert.Equal(t, "http://example.com", *url)
+	assert.True(t, *verbose)
+	assert.Equal(t, 8, *workers)
+	assert.False(t, *once)
+	assert.Equal(t, "/etc/kube", *kube)
+	assert.Equal(t, "set the url [FOO_URL http://example.com]", gfs.Lookup("url").Usage)
+	assert.Equal(t, "FOO_URL", envy.SourcesFlagSet(envy.StdFlagSet(gfs))[2].EnvName)
+
+	assert.NoError(t, gfs.Parse([]string{"--workers=2"}))
+	assert.Equal(t, 2, *workers)
+
+	os.Setenv("BAR_WORKERS", "many")
+	gfs = flag.NewFlagSet("test", flag.ContinueOnError)
+	gfs.Int("workers", 4, "number of workers")
+	assert.Error(t, envy.ParseStdFlagSetE("BAR", gfs))
+}
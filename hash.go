@@ -0,0 +1,69 @@
+package envy
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+
+	"github.com/spf13/pflag"
+)
+
+// Used to show a SHA-256 of the flag's value instead of the value itself.
+const AnnotationHash = "envy_hash"
+
+// Hashed marks the given flag as holding a large blob, see HashedOnFlagSet.
+func Hashed(name string) {
+	HashedOnFlagSet(name, pflag.CommandLine)
+}
+
+// HashedOnFlagSet marks the given flag as holding a large blob, like a
+// certificate bundle or a schema, so usage, summaries and every other report
+// show a SHA-256 of its value as sha256:<hex> instead of dumping it. Operators
+// can still verify the right blob was loaded by comparing the hash. Secrets
+// stay redacted. It must be called before the call to envy.Parse().
+func HashedOnFlagSet(name string, fs *pflag.FlagSet) {
+	std.on("", fs).Hashed(name)
+}
+
+// Hashed marks the given flag as holding a large blob, see HashedOnFlagSet.
+func (e *Envy) Hashed(name string) {
+	f := e.fs.Lookup(name)
+	if f == nil {
+		e.fail(&SetError{Flag: name, Err: ErrFlagNotExists})
+		return
+	}
+	annotate(f, AnnotationHash, "true")
+}
+
+// isHashed reports whether the flag was marked with Hashed.
+func isHashed(f *pflag.Flag) bool {
+	_, ok := f.Annotations[AnnotationHash]
+	return ok
+}
+
+// hashValue returns the SHA-256 of the value as sha256:<hex>.
+func hashValue(val string) string {
+	sum := sha256.Sum256([]byte(val))
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// hmacValue returns the HMAC-SHA256 of the value under the key as
+// hmac-sha256:<hex>.
+func hmacValue(key []byte, val string) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(val))
+	return "hmac-sha256:" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// rawDisplayValue hides a value read straight from a source, before it went
+// through the flag, the way displayValue hides the flag's value.
+func rawDisplayValue(f *pflag.Flag, val string) string {
+	switch {
+	// The raw value can't go through a Redactor, so hide it entirely.
+	case isSecret(f) || isRedactor(f):
+		return redacted
+	case isHashed(f):
+		return hashValue(val)
+	}
+	return val
+}
@@ -0,0 +1,42 @@
+package envy_test
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"testing"
+
+	"github.com/fernferret/envy"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHashed(t *testing.T) {
+	blob := "-----BEGIN CERTIFICATE-----\nMIIB...\n-----END CERTIFICATE-----\n"
+	sum := sha256.Sum256([]byte(blob))
+	want := "sha256:" + hex.EncodeToString(sum[:])
+
+	os.Clearenv()
+	os.Setenv("MYAPP_CA_BUNDLE", blob)
+	pflag.CommandLine = pflag.NewFlagSet("test", pflag.PanicOnError)
+
+	ca := pflag.String("ca-bundle", "", "ca bundle")
+	envy.Hashed("ca-bundle")
+	envy.Parse("MYAPP")
+
+	assert.Equal(t, blob, *ca)
+	assert.Equal(t, "ca bundle [MYAPP_CA_BUNDLE "+want+"]", pflag.Lookup("ca-bundle").Usage)
+
+	buf := &bytes.Buffer{}
+	envy.PrintSummary(buf)
+	assert.Contains(t, buf.String(), want)
+	assert.NotContains(t, buf.String(), "BEGIN")
+
+	assert.Equal(t, want, envy.Sources()[0].Value)
+	x := envy.Explain("ca-bundle")
+	assert.Equal(t, want, x.Layers[0].Value)
+	assert.Equal(t, want, x.Layers[1].Value)
+
+	assert.Panics(t, func() { envy.Hashed("missing") })
+}
@@ -0,0 +1,202 @@
+package envy
+
+import "github.com/spf13/pflag"
+
+// Envy binds one FlagSet to the environment under one prefix with its own
+// settings, so several independent configurations, like plugins each with
+// their own prefix, can coexist in one process. The package level functions
+// share a default Envy configured with SetNameFunc, SetDecoration and friends.
+type Envy struct {
+	prefix        string
+	fs            *pflag.FlagSet
+	nameFunc      NameFunc
+	decoration    Decoration
+	catalog       Catalog
+	strictness    Strictness
+	international bool
+	freeze        FreezeMode
+	envAsDefault  bool
+	deprecated    DeprecationHandler
+	lookuper      Lookuper
+	fileSuffix    bool
+	maxFileSize   int64
+	layered       bool
+	secretsDir    string
+	boolValues    map[string]string
+	atomic        bool
+	strict        bool
+	strictAllow   []string
+	secretKey     []byte
+
+	// Old prefixes still read, see MigratePrefix.
+	migrations []*PrefixMigration
+
+	// Config files checked when no environment variable is set, later ones
+	// win.
+	files []configFile
+
+	// Sources from lowest to highest priority, see WithPrecedence.
+	precedence []Origin
+
+	// Checked after every Parse, see WithNamePolicy.
+	namePolicy *NamePolicy
+	telemetry  TelemetryFunc
+
+	// The variables of the gates checked by Parse, see SetStrictPrefix.
+	gates []string
+
+	// The flags of gates that are off, see Schema.
+	gated []*pflag.Flag
+
+	// Mistakes found while applying options, reported by Parse.
+	optErrs ParseErrors
+}
+
+// Option configures an Envy created with New.
+type Option func(*Envy)
+
+// The Envy used by the package level functions.
+var std = New()
+
+// The Envy each FlagSet was last parsed with, used by BindLate and anything
+// that needs to know the names a flag was bound to.
+var parsed registry[*pflag.FlagSet, *Envy]
+
+// New returns an Envy for the default pflag.CommandLine with no prefix and
+// envy's default settings, regardless of any package level Set calls.
+func New(opts ...Option) *Envy {
+	e := &Envy{
+		fs:         pflag.CommandLine,
+		nameFunc:   DefaultNameFunc,
+		catalog:    defaultCatalog{},
+		deprecated: defaultDeprecationHandler,
+		lookuper:   EnvLookuper{},
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	e.prefix = normalizePrefix(e.prefix)
+	return e
+}
+
+// WithPrefix sets the prefix of every environment variable.
+func WithPrefix(pfx string) Option {
+	return func(e *Envy) {
+		e.prefix = pfx
+	}
+}
+
+// WithFlagSet binds the given FlagSet instead of pflag.CommandLine.
+func WithFlagSet(fs *pflag.FlagSet) Option {
+	return func(e *Envy) {
+		e.fs = fs
+	}
+}
+
+// WithNameFunc works like SetNameFunc for this Envy only.
+func WithNameFunc(fn NameFunc) Option {
+	return func(e *Envy) {
+		if fn == nil {
+			fn = DefaultNameFunc
+		}
+		e.nameFunc = fn
+	}
+}
+
+// WithDecoration works like SetDecoration for this Envy only.
+func WithDecoration(d Decoration) Option {
+	return func(e *Envy) {
+		e.decoration = d
+	}
+}
+
+// WithCatalog works like SetCatalog for this Envy only.
+func WithCatalog(c Catalog) Option {
+	return func(e *Envy) {
+		if c == nil {
+			c = defaultCatalog{}
+		}
+		e.catalog = c
+	}
+}
+
+// WithStrictness works like SetStrictness for this Envy only.
+func WithStrictness(s Strictness) Option {
+	return func(e *Envy) {
+		e.strictness = s
+	}
+}
+
+// WithInternational works like SetInternational for this Envy only.
+func WithInternational(on bool) Option {
+	return func(e *Envy) {
+		e.international = on
+	}
+}
+
+// WithFreeze works like SetFreeze for this Envy only.
+func WithFreeze(mode FreezeMode) Option {
+	return func(e *Envy) {
+		e.freeze = mode
+	}
+}
+
+// WithSecretKey works like SetSecretKey for this Envy only.
+func WithSecretKey(key []byte) Option {
+	return func(e *Envy) {
+		e.secretKey = key
+	}
+}
+
+// WithEnvAsDefault works like SetEnvAsDefault for this Envy only.
+func WithEnvAsDefault(on bool) Option {
+	return func(e *Envy) {
+		e.envAsDefault = on
+	}
+}
+
+// WithDeprecationHandler works like SetDeprecationHandler for this Envy only.
+func WithDeprecationHandler(fn DeprecationHandler) Option {
+	return func(e *Envy) {
+		if fn == nil {
+			fn = defaultDeprecationHandler
+		}
+		e.deprecated = fn
+	}
+}
+
+// FlagSet returns the FlagSet this Envy binds.
+func (e *Envy) FlagSet() *pflag.FlagSet {
+	return e.fs
+}
+
+// Parse binds every flag of the FlagSet to the environment, see ParseFlagSet.
+func (e *Envy) Parse() {
+	e.report(e.parse())
+}
+
+// ParseE binds every flag of the FlagSet to the environment, returning any
+// values that couldn't be set, see ParseFlagSetE.
+func (e *Envy) ParseE() error {
+	if errs := e.parse(); len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// on returns a copy of the Envy for the given prefix and FlagSet, which is how
+// the package level functions use the default Envy.
+func (e *Envy) on(pfx string, fs *pflag.FlagSet) *Envy {
+	c := *e
+	c.prefix = normalizePrefix(pfx)
+	c.fs = fs
+	return &c
+}
+
+// instanceFor returns the Envy the FlagSet was parsed with, or the default.
+func instanceFor(fs *pflag.FlagSet) *Envy {
+	if e, ok := parsed.get(fs); ok {
+		return e
+	}
+	return std
+}
@@ -0,0 +1,110 @@
+package envy_test
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/fernferret/envy"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNew(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("REDIS_ADDR", "redis:6379")
+	os.Setenv("CACHE_ADDR", "memcached:11211")
+	os.Setenv("CACHE_SERVER_NAME", "cache-1")
+	os.Setenv("CACHE_TTL", "soon")
+
+	redisFS := pflag.NewFlagSet("redis", pflag.ContinueOnError)
+	redisAddr := redisFS.String("addr", "", "redis address")
+	redis := envy.New(envy.WithPrefix("redis"), envy.WithFlagSet(redisFS), envy.WithDecoration(envy.DecorationNone))
+
+	cacheFS := pflag.NewFlagSet("cache", pflag.ContinueOnError)
+	cacheAddr := cacheFS.String("addr", "", "cache address")
+	cacheName := cacheFS.String("server.name", "", "cache server name")
+	cacheFS.Duration("ttl", 0, "cache ttl")
+	cache := envy.New(
+		envy.WithPrefix("CACHE"),
+		envy.WithFlagSet(cacheFS),
+		envy.WithNameFunc(envy.FFNameFunc),
+		envy.WithStrictness(envy.ReturnErrors),
+	)
+
+	redis.Parse()
+	cache.Disable("missing")
+	assert.EqualError(t, cache.ParseE(), `--missing: flag does not exist
+--ttl from CACHE_TTL: duration flag got value that was't parsable as a golang duration, example: 1m30s`)
+
+	assert.Equal(t, redisFS, redis.FlagSet())
+	assert.Equal(t, "redis:6379", *redisAddr)
+	assert.Equal(t, "redis address", redisFS.Lookup("addr").Usage)
+	assert.Equal(t, "memcached:11211", *cacheAddr)
+	assert.Equal(t, "cache-1", *cacheName)
+	assert.Equal(t, "cache address [CACHE_ADDR memcached:11211]", cacheFS.Lookup("addr").Usage)
+
+	// Anything that needs the names a flag was bound to uses the right Envy
+	assert.Equal(t, "env CACHE_SERVER_NAME", envy.ExplainFlagSet("server.name", cacheFS).Layers[1].Source)
+
+	// Late flags use the same settings
+	cacheFS.String("late.addr", "", "late address")
+	os.Setenv("CACHE_LATE_ADDR", "late:1")
+	envy.BindLate(cacheFS, "late.addr")
+	assert.Equal(t, "late:1", cacheFS.Lookup("late.addr").Value.String())
+}
+
+func TestNewIgnoresPackageSettings(t *testing.T) {
+	envy.SetDecoration(envy.DecorationNone)
+	defer envy.SetDecoration(envy.DecorationFull)
+
+	os.Clearenv()
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	fs.String("url", "", "set the url")
+	envy.New(envy.WithFlagSet(fs), envy.WithPrefix("FOO")).Parse()
+	assert.Equal(t, "set the url [FOO_URL]", fs.Lookup("url").Usage)
+}
+
+func ExampleNew() {
+	// Reset CommandLine flags for example, you don't need this in your code!
+	pflag.CommandLine = pflag.NewFlagSet("test", pflag.PanicOnError)
+	os.Clearenv()
+
+	// Each plugin gets its own FlagSet and prefix
+	fs := pflag.NewFlagSet("metrics", pflag.ExitOnError)
+	fs.String("addr", ":9090", "metrics listen address")
+
+	// Simulate METRICS_ADDR being set
+	os.Setenv("METRICS_ADDR", ":9100")
+
+	metrics := envy.New(envy.WithPrefix("METRICS"), envy.WithFlagSet(fs))
+	metrics.Parse()
+	fs.Parse(nil)
+
+	addr, _ := fs.GetString("addr")
+	fmt.Println(addr)
+	// Output: :9100
+}
+
+func TestNewConcurrent(t *testing.T) {
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+			envy.ModuleOnFlagSet("redis", func(fs *pflag.FlagSet) {
+				fs.String("addr", "", "redis address")
+			}, fs)
+			envy.RuntimeFlagsOnFlagSet(fs)
+			env := envy.MapLookuper{"FOO_REDIS_ADDR": fmt.Sprintf("redis-%d:6379", i)}
+			e := envy.New(envy.WithFlagSet(fs), envy.WithPrefix("FOO"), envy.WithLookuper(env), envy.WithStrictness(envy.ReturnErrors))
+			assert.NoError(t, e.ParseE())
+			assert.NoError(t, fs.Parse(nil))
+			envy.BindLate(fs)
+			assert.Equal(t, fmt.Sprintf("redis-%d:6379", i), fs.Lookup("addr").Value.String())
+		}(i)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,90 @@
+package envy
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SetInternational allows float flags to be read from locale formatted numbers
+// like 1.234,5 and duration flags from ISO-8601 durations like PT15M, for
+// values that come from systems outside of Go. It only affects environment
+// variables and must be called before the call to envy.Parse().
+func SetInternational(on bool) {
+	std.international = on
+}
+
+// Matches the time based ISO-8601 durations, years and months are left out
+// since they don't have a fixed length.
+var isoDuration = regexp.MustCompile(`^([-+])?P(?:([\d.,]+)W)?(?:([\d.,]+)D)?(?:T(?:([\d.,]+)H)?(?:([\d.,]+)M)?(?:([\d.,]+)S)?)?$`)
+
+// parseISODuration parses an ISO-8601 duration such as P1DT2H or PT0,5S.
+func parseISODuration(val string) (time.Duration, bool) {
+	val = strings.ToUpper(val)
+	m := isoDuration.FindStringSubmatch(val)
+	if m == nil || strings.HasSuffix(val, "P") || strings.HasSuffix(val, "T") {
+		return 0, false
+	}
+
+	units := []time.Duration{7 * 24 * time.Hour, 24 * time.Hour, time.Hour, time.Minute, time.Second}
+	total := 0.0
+	for i, unit := range units {
+		part := m[i+2]
+		if part == "" {
+			continue
+		}
+		n, err := strconv.ParseFloat(strings.Replace(part, ",", ".", 1), 64)
+		if err != nil {
+			return 0, false
+		}
+		total += n * float64(unit)
+	}
+	if total > math.MaxInt64 {
+		return 0, false
+	}
+	if m[1] == "-" {
+		total = -total
+	}
+	return time.Duration(total), true
+}
+
+// parseLocaleNumber parses numbers using either a comma or a dot as the decimal
+// separator, along with dot, comma, space or apostrophe grouping. When both
+// separators are present the last one is the decimal, a lone comma is always
+// a decimal separator.
+func parseLocaleNumber(val string) (float64, bool) {
+	val = strings.NewReplacer(" ", "", "\u00a0", "", "\u202f", "", "'", "").Replace(val)
+
+	decimal := ""
+	commas, dots := strings.Count(val, ","), strings.Count(val, ".")
+	switch {
+	case commas > 0 && dots > 0:
+		decimal = ","
+		if strings.LastIndex(val, ".") > strings.LastIndex(val, ",") {
+			decimal = "."
+		}
+	case commas == 1:
+		decimal = ","
+	case dots == 1:
+		decimal = "."
+	}
+
+	// Remove the grouping separators, then switch the decimal to a dot.
+	for _, sep := range []string{",", "."} {
+		if sep == decimal {
+			continue
+		}
+		val = strings.ReplaceAll(val, sep, "")
+	}
+	if decimal != "" {
+		if strings.Count(val, decimal) > 1 {
+			return 0, false
+		}
+		val = strings.Replace(val, decimal, ".", 1)
+	}
+
+	n, err := strconv.ParseFloat(val, 64)
+	return n, err == nil
+}
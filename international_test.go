@@ -0,0 +1,76 @@
+package envy_test
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/fernferret/envy"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetInternational(t *testing.T) {
+	tests := []struct {
+		name  string
+		value interface{}
+		env   string
+		exp   string
+		panic bool
+	}{
+		{name: "test iso minutes", value: time.Second, env: "PT15M", exp: "15m0s"},
+		{name: "test iso days and hours", value: time.Second, env: "P1DT2H", exp: "26h0m0s"},
+		{name: "test iso weeks", value: time.Second, env: "P1W", exp: "168h0m0s"},
+		{name: "test iso fraction", value: time.Second, env: "pt0,5s", exp: "500ms"},
+		{name: "test iso negative", value: time.Second, env: "-PT1H30M", exp: "-1h30m0s"},
+		{name: "test go duration", value: time.Second, env: "90s", exp: "1m30s"},
+		{name: "test iso months", value: time.Second, env: "P1M", panic: true},
+		{name: "test iso empty time", value: time.Second, env: "PT", panic: true},
+		{name: "test comma decimal", value: 0.0, env: "1.234,5", exp: "1234.5"},
+		{name: "test dot decimal", value: 0.0, env: "1,234.5", exp: "1234.5"},
+		{name: "test lone comma", value: 0.0, env: "0,25", exp: "0.25"},
+		{name: "test grouped commas", value: 0.0, env: "1,234,567", exp: "1.234567e+06"},
+		{name: "test space grouping", value: 0.0, env: "1 234,5", exp: "1234.5"},
+		{name: "test go float", value: 0.0, env: "1.234", exp: "1.234"},
+		{name: "test garbage float", value: 0.0, env: "1,2,3.4.5", panic: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv("FOO_VAL", tt.env)
+			fs := pflag.NewFlagSet("test", pflag.PanicOnError)
+			switch v := tt.value.(type) {
+			case time.Duration:
+				fs.Duration("val", v, "the value")
+			case float64:
+				fs.Float64("val", v, "the value")
+			}
+
+			envy.SetInternational(true)
+			defer envy.SetInternational(false)
+
+			if tt.panic {
+				assert.Panics(t, func() { envy.ParseFlagSet("FOO", fs) })
+				return
+			}
+			envy.ParseFlagSet("FOO", fs)
+			assert.Equal(t, tt.exp, fs.Lookup("val").Value.String())
+		})
+	}
+}
+
+func TestSetInternationalOff(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("FOO_INTERVAL", "PT15M")
+	os.Setenv("FOO_RATIO", "0,25")
+	fs := pflag.NewFlagSet("test", pflag.PanicOnError)
+	fs.Duration("interval", time.Second, "the interval")
+	fs.Float64("ratio", 0, "the ratio")
+	envy.DisableOnFlagSet("ratio", fs)
+
+	assert.PanicsWithError(t, "--interval from FOO_INTERVAL: "+envy.ErrInvalidDurationFlagValue.Error(), func() { envy.ParseFlagSet("FOO", fs) })
+
+	fs = pflag.NewFlagSet("test", pflag.PanicOnError)
+	fs.Float64("ratio", 0, "the ratio")
+	assert.Panics(t, func() { envy.ParseFlagSet("FOO", fs) })
+}
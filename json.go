@@ -0,0 +1,20 @@
+package envy
+
+// SetJSONFiles replaces the JSON config files of the default Envy, see
+// WithJSONFile. Passing none removes them. Files set with SetYAMLFiles are
+// kept, and the files set by the latest call win. If a file can't be read
+// nothing is changed. It must be called before the call to envy.Parse().
+func SetJSONFiles(paths ...string) error {
+	return setFiles(formatJSON, paths)
+}
+
+// WithJSONFile reads flag values from a JSON file, like a mounted ConfigMap,
+// whose keys are flag names. It works exactly like WithYAMLFile, nested
+// objects are joined with dots or dashes and arrays with commas:
+//
+//	{"count-fancy": 3, "server": {"port": 8080}, "tags": ["a", "b"]}
+//
+// Disabled flags ignore the file just like they ignore the environment.
+func WithJSONFile(path string) Option {
+	return withFile(path, formatJSON)
+}
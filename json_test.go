@@ -0,0 +1,63 @@
+package envy_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/fernferret/envy"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithJSONFile(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("MYAPP_NAME", "from-env")
+	path := writeFile(t, "config.json", `{
+	"count-fancy": 3,
+	"name": "from-file",
+	"limit": 1000000,
+	"server": {"port": 8080},
+	"tags": ["a", "b"],
+	"verbose": true,
+	"once": true
+}`)
+
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	count := fs.Int("count-fancy", 1, "count")
+	name := fs.String("name", "", "name")
+	limit := fs.Int64("limit", 0, "limit")
+	port := fs.Int("server-port", 80, "port")
+	tags := fs.StringSlice("tags", nil, "tags")
+	verbose := fs.Bool("verbose", false, "verbose")
+	once := fs.Bool("once", false, "once")
+
+	e := envy.New(envy.WithPrefix("MYAPP"), envy.WithFlagSet(fs), envy.WithJSONFile(path))
+	e.Disable("once")
+	assert.NoError(t, e.ParseE())
+
+	assert.Equal(t, 3, *count)
+	assert.Equal(t, "from-env", *name)
+	assert.Equal(t, int64(1000000), *limit)
+	assert.Equal(t, 8080, *port)
+	assert.Equal(t, []string{"a", "b"}, *tags)
+	assert.True(t, *verbose)
+	assert.False(t, *once)
+}
+
+func TestSetJSONFiles(t *testing.T) {
+	os.Clearenv()
+	pflag.CommandLine = pflag.NewFlagSet("test", pflag.PanicOnError)
+	defer envy.SetJSONFiles()
+	defer envy.SetYAMLFiles()
+
+	yml := writeFile(t, "config.yaml", "name: yaml\nurl: http://yaml\n")
+	jsn := writeFile(t, "config.json", `{"name": "json"}`)
+	name := pflag.String("name", "", "name")
+	url := pflag.String("url", "", "url")
+	assert.NoError(t, envy.SetYAMLFiles(yml))
+	assert.NoError(t, envy.SetJSONFiles(jsn))
+	assert.Error(t, envy.SetJSONFiles(writeFile(t, "bad.json", "{")))
+	envy.Parse("FOO")
+	assert.Equal(t, "json", *name)
+	assert.Equal(t, "http://yaml", *url)
+}
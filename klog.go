@@ -0,0 +1,38 @@
+package envy
+
+import (
+	"flag"
+
+	"github.com/spf13/pflag"
+)
+
+// The klog flags worth setting from the environment, the rest are rarely
+// changed and are disabled by BindKlog.
+var klogEnvFlags = map[string]bool{
+	"v":           true,
+	"vmodule":     true,
+	"logtostderr": true,
+}
+
+// BindKlog adopts the flags klog (or glog) registered on a stdlib FlagSet into
+// the given pflag FlagSet, without envy depending on klog:
+//
+//	gfs := flag.NewFlagSet("klog", flag.ExitOnError)
+//	klog.InitFlags(gfs)
+//	envy.BindKlog(pflag.CommandLine, gfs)
+//
+// With a prefix of MYAPP, -v and -logtostderr are read from MYAPP_V and
+// MYAPP_LOGTOSTDERR. Every other klog flag is disabled so only the ones people
+// actually set show up with environment variables in --help. It must be called
+// before the call to envy.Parse().
+func BindKlog(fs *pflag.FlagSet, gfs *flag.FlagSet) {
+	gfs.VisitAll(func(gf *flag.Flag) {
+		if fs.Lookup(gf.Name) != nil {
+			return
+		}
+		fs.AddGoFlag(gf)
+		if !klogEnvFlags[gf.Name] {
+			annotate(fs.Lookup(gf.Name), AnnotationDisable, "true")
+		}
+	})
+}
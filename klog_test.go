@@ -0,0 +1,38 @@
+package envy_test
+
+import (
+	"flag"
+	"os"
+	"testing"
+
+	"github.com/fernferret/envy"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBindKlog(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("MYAPP_V", "4")
+	os.Setenv("MYAPP_LOGTOSTDERR", "false")
+	os.Setenv("MYAPP_LOG_DIR", "/var/log")
+
+	// A stand in for klog.InitFlags(gfs)
+	gfs := flag.NewFlagSet("klog", flag.ContinueOnError)
+	v := gfs.Int("v", 0, "number for the log level verbosity")
+	toStderr := gfs.Bool("logtostderr", true, "log to standard error instead of files")
+	logDir := gfs.String("log_dir", "", "if non-empty, write log files in this directory")
+
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	fs.Int("workers", 4, "number of workers")
+	envy.BindKlog(fs, gfs)
+	envy.ParseFlagSet("MYAPP", fs)
+
+	assert.Equal(t, 4, *v)
+	assert.False(t, *toStderr)
+	assert.Empty(t, *logDir)
+	assert.Equal(t, "number for the log level verbosity [MYAPP_V 4]", fs.Lookup("v").Usage)
+	assert.Equal(t, "if non-empty, write log files in this directory", fs.Lookup("log_dir").Usage)
+
+	assert.NoError(t, fs.Parse([]string{"--log_dir=/tmp"}))
+	assert.Equal(t, "/tmp", *logDir)
+}
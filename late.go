@@ -0,0 +1,47 @@
+package envy
+
+import "github.com/spf13/pflag"
+
+// BindLate binds flags that were defined after the call to envy.Parse() or
+// envy.ParseFlagSet(), such as those registered by plugins, using the prefix
+// the FlagSet was originally parsed with. If no names are given, every flag
+// envy hasn't seen yet is bound. Like Parse, it must be called before the call
+// to pflag.Parse().
+func BindLate(fs *pflag.FlagSet, names ...string) {
+	e, ok := parsed.get(fs)
+	if !ok {
+		err := &SetError{Err: ErrNotParsed}
+		if len(names) > 0 {
+			err.Flag = names[0]
+		}
+		panic(err)
+	}
+	e.BindLate(names...)
+}
+
+// BindLate binds flags that were defined after the call to Parse, see the
+// package level BindLate.
+func (e *Envy) BindLate(names ...string) {
+	var errs ParseErrors
+	if len(names) == 0 {
+		visitAll(e.fs, func(f *pflag.Flag) {
+			if _, ok := f.Annotations[AnnotationBound]; !ok {
+				if err := e.bind(f); err != nil {
+					errs = append(errs, err)
+				}
+			}
+		})
+		e.report(errs)
+		return
+	}
+
+	for _, name := range names {
+		f := e.fs.Lookup(name)
+		if f == nil {
+			errs = append(errs, &SetError{Flag: name, Err: ErrFlagNotExists})
+		} else if err := e.bind(f); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	e.report(errs)
+}
@@ -0,0 +1,56 @@
+package envy_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/fernferret/envy"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBindLate(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("FOO_URL", "http://127.0.0.1")
+	os.Setenv("FOO_PLUGIN_NAME", "late")
+	pflag.CommandLine = pflag.NewFlagSet("test", pflag.PanicOnError)
+
+	pflag.String("url", "", "set the url")
+	envy.Parse("FOO")
+
+	// Simulate a plugin registering a flag after envy has run
+	pflag.String("plugin-name", "", "name of the plugin")
+
+	envy.BindLate(pflag.CommandLine)
+
+	// The already bound flag must not be decorated twice
+	assert.Equal(t, "set the url [FOO_URL http://127.0.0.1]", pflag.Lookup("url").Usage)
+	assert.Equal(t, "name of the plugin [FOO_PLUGIN_NAME late]", pflag.Lookup("plugin-name").Usage)
+	assert.Equal(t, "late", pflag.Lookup("plugin-name").Value.String())
+}
+
+func TestBindLateNames(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("FOO_PLUGIN_NAME", "late")
+	pflag.CommandLine = pflag.NewFlagSet("test", pflag.PanicOnError)
+
+	envy.Parse("FOO")
+
+	pflag.String("plugin-name", "", "name of the plugin")
+	pflag.String("other", "", "not bound")
+
+	envy.BindLate(pflag.CommandLine, "plugin-name")
+
+	assert.Equal(t, "late", pflag.Lookup("plugin-name").Value.String())
+	assert.Equal(t, "not bound", pflag.Lookup("other").Usage)
+
+	assert.Panics(t, func() { envy.BindLate(pflag.CommandLine, "missing") })
+}
+
+func TestBindLateNotParsed(t *testing.T) {
+	fs := pflag.NewFlagSet("test", pflag.PanicOnError)
+	fs.String("url", "", "set the url")
+
+	assert.PanicsWithError(t, envy.ErrNotParsed.Error(), func() { envy.BindLate(fs) })
+	assert.PanicsWithError(t, "--url: "+envy.ErrNotParsed.Error(), func() { envy.BindLate(fs, "url") })
+}
@@ -0,0 +1,124 @@
+package envy
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+var ErrNameViolation = errors.New("name violates the naming policy")
+
+// NameError is the Err of a SetError for a name that violates a NamePolicy.
+// It matches ErrNameViolation with errors.Is.
+type NameError struct {
+	EnvName string
+
+	// Why the name was rejected, like "contains a double underscore".
+	Reason string
+}
+
+func (e *NameError) Error() string {
+	return fmt.Sprintf("%v: %s", ErrNameViolation, e.Reason)
+}
+
+func (e *NameError) Unwrap() error {
+	return ErrNameViolation
+}
+
+// NamePolicy is an organization's convention for environment variable names,
+// see LintNames.
+type NamePolicy struct {
+	// RequirePrefix rejects names that don't start with the prefix, like
+	// custom names from SetEnvName.
+	RequirePrefix bool
+
+	// MaxLength rejects longer names, zero means no limit.
+	MaxLength int
+
+	// NoDoubleUnderscore rejects names containing __.
+	NoDoubleUnderscore bool
+
+	// Reserved rejects names that are, or contain as an underscore separated
+	// word, any of these, ignoring case. PATH would reject MYAPP_PATH.
+	Reserved []string
+
+	// Allow exempts names from every rule, like well known names such as
+	// KUBECONFIG.
+	Allow []string
+}
+
+// LintNames checks the names of every flag bound in the default
+// pflag.CommandLine, see LintNamesFlagSet.
+func LintNames(policy NamePolicy) error {
+	return LintNamesFlagSet(policy, pflag.CommandLine)
+}
+
+// LintNamesFlagSet checks the names envy generated or was given for every
+// flag bound in the given FlagSet against the policy, returning a ParseErrors
+// with every violation. Calling it from a test fails CI before inconsistent
+// names ship. It must be called after the call to envy.Parse().
+func LintNamesFlagSet(policy NamePolicy, fs *pflag.FlagSet) error {
+	if errs := instanceFor(fs).lint(policy); len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// SetNamePolicy makes Parse report names that violate the policy like any
+// other mistake, passing nil turns it off. Use it in dev and test builds. It
+// must be called before the call to envy.Parse().
+func SetNamePolicy(policy *NamePolicy) {
+	std.namePolicy = policy
+}
+
+// WithNamePolicy works like SetNamePolicy for this Envy only.
+func WithNamePolicy(policy *NamePolicy) Option {
+	return func(e *Envy) {
+		e.namePolicy = policy
+	}
+}
+
+// lint checks the names of every bound flag against the policy.
+func (e *Envy) lint(p NamePolicy) ParseErrors {
+	var errs ParseErrors
+	visitAll(e.fs, func(f *pflag.Flag) {
+		for _, pfx := range f.Annotations[AnnotationBound] {
+			for _, name := range e.envNamesFor(pfx, f) {
+				if reason := p.check(pfx, name); reason != "" {
+					errs = append(errs, &SetError{Flag: f.Name, EnvName: name, Err: &NameError{EnvName: name, Reason: reason}})
+				}
+			}
+		}
+	})
+	return errs
+}
+
+// check returns why the name violates the policy, or nothing.
+func (p NamePolicy) check(pfx, name string) string {
+	for _, allowed := range p.Allow {
+		if strings.EqualFold(allowed, name) {
+			return ""
+		}
+	}
+	switch {
+	case p.RequirePrefix && pfx != "" && !strings.HasPrefix(name, pfx):
+		return fmt.Sprintf("missing prefix %s", pfx)
+	case p.MaxLength > 0 && len(name) > p.MaxLength:
+		return fmt.Sprintf("longer than %d characters", p.MaxLength)
+	case p.NoDoubleUnderscore && strings.Contains(name, "__"):
+		return "contains a double underscore"
+	}
+	for _, reserved := range p.Reserved {
+		if strings.EqualFold(reserved, name) {
+			return fmt.Sprintf("%s is reserved", reserved)
+		}
+		for _, word := range strings.Split(name, "_") {
+			if strings.EqualFold(reserved, word) {
+				return fmt.Sprintf("%s is reserved", reserved)
+			}
+		}
+	}
+	return ""
+}
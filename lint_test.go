@@ -0,0 +1,70 @@
+package envy_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/fernferret/envy"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLintNames(t *testing.T) {
+	policy := envy.NamePolicy{
+		RequirePrefix:      true,
+		MaxLength:          20,
+		NoDoubleUnderscore: true,
+		Reserved:           []string{"PATH", "TMP"},
+		Allow:              []string{"KUBECONFIG"},
+	}
+
+	os.Clearenv()
+	pflag.CommandLine = pflag.NewFlagSet("test", pflag.PanicOnError)
+	pflag.String("url", "", "set the url")
+	pflag.String("kube-config", "", "kube config")
+	pflag.String("home", "", "home")
+	pflag.String("very-long-setting-name", "", "too long")
+	pflag.String("cache--dir", "", "double underscore")
+	pflag.String("search-path", "", "reserved word")
+	pflag.Bool("once", false, "disabled")
+	envy.SetEnvName("kube-config", "KUBECONFIG")
+	envy.SetEnvName("home", "HOME")
+	envy.Disable("once")
+	envy.Parse("MYAPP")
+
+	err := envy.LintNames(policy)
+	var errs envy.ParseErrors
+	if !assert.ErrorAs(t, err, &errs) {
+		return
+	}
+	assert.ErrorIs(t, err, envy.ErrNameViolation)
+	var got []string
+	for _, e := range errs {
+		got = append(got, e.Error())
+	}
+	assert.Equal(t, []string{
+		"--cache--dir from MYAPP_CACHE__DIR: name violates the naming policy: contains a double underscore",
+		"--home from HOME: name violates the naming policy: missing prefix MYAPP_",
+		"--search-path from MYAPP_SEARCH_PATH: name violates the naming policy: PATH is reserved",
+		"--very-long-setting-name from MYAPP_VERY_LONG_SETTING_NAME: name violates the naming policy: longer than 20 characters",
+	}, got)
+
+	assert.NoError(t, envy.LintNames(envy.NamePolicy{}))
+}
+
+func TestWithNamePolicy(t *testing.T) {
+	os.Clearenv()
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	fs.String("url", "", "set the url")
+	fs.String("tmp", "", "temp dir")
+
+	policy := &envy.NamePolicy{Reserved: []string{"TMP"}}
+	err := envy.New(envy.WithPrefix("MYAPP"), envy.WithFlagSet(fs), envy.WithNamePolicy(policy)).ParseE()
+	assert.EqualError(t, err, "--tmp from MYAPP_TMP: name violates the naming policy: TMP is reserved")
+	var nameErr *envy.NameError
+	if assert.ErrorAs(t, err, &nameErr) {
+		assert.Equal(t, envy.NameError{EnvName: "MYAPP_TMP", Reason: "TMP is reserved"}, *nameErr)
+	}
+
+	assert.NoError(t, envy.New(envy.WithPrefix("MYAPP"), envy.WithFlagSet(fs)).ParseE())
+}
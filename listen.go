@@ -0,0 +1,96 @@
+package envy
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+// Prefix for listen addresses referring to an inherited file descriptor, as
+// handed out by systemd socket activation.
+const fdScheme = "fd://"
+
+var ErrInvalidListenAddr = errors.New("listen address must be host:port, [ipv6]:port or fd://N")
+
+// listenAddrValue is a string flag that only accepts valid listen addresses.
+type listenAddrValue string
+
+func (v *listenAddrValue) Set(val string) error {
+	if err := validateListenAddr(val); err != nil {
+		return err
+	}
+	*v = listenAddrValue(val)
+	return nil
+}
+
+func (v *listenAddrValue) String() string {
+	return string(*v)
+}
+
+func (v *listenAddrValue) Type() string {
+	return "addr"
+}
+
+// ListenAddrVar defines a listen address flag on the default pflag.CommandLine,
+// see ListenAddrVarOnFlagSet.
+func ListenAddrVar(p *string, name, value, usage string) {
+	ListenAddrVarOnFlagSet(p, name, value, usage, pflag.CommandLine)
+}
+
+// ListenAddrVarOnFlagSet defines a listen address flag on the given FlagSet.
+// Values must be host:port (the host may be empty and the port may be 0),
+// [ipv6]:port or fd://N to use a socket inherited from systemd. Use Listen to
+// open the resulting address. The default value is not validated.
+func ListenAddrVarOnFlagSet(p *string, name, value, usage string, fs *pflag.FlagSet) {
+	*p = value
+	fs.Var((*listenAddrValue)(p), name, usage)
+}
+
+// Listen opens a TCP listener for an address accepted by ListenAddrVar,
+// including inherited file descriptors given as fd://N.
+func Listen(addr string) (net.Listener, error) {
+	if !strings.HasPrefix(addr, fdScheme) {
+		return net.Listen("tcp", addr)
+	}
+	fd, err := parseFD(addr)
+	if err != nil {
+		return nil, err
+	}
+	f := os.NewFile(uintptr(fd), addr)
+	defer f.Close()
+	return net.FileListener(f)
+}
+
+func validateListenAddr(addr string) error {
+	if strings.HasPrefix(addr, fdScheme) {
+		_, err := parseFD(addr)
+		return err
+	}
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidListenAddr, err)
+	}
+	if n, err := strconv.ParseUint(port, 10, 16); err != nil || port != strconv.FormatUint(n, 10) {
+		return fmt.Errorf("%w: invalid port %q", ErrInvalidListenAddr, port)
+	}
+
+	// SplitHostPort strips the brackets, anything with a colon left must be
+	// an IPv6 address.
+	if strings.Contains(host, ":") && net.ParseIP(host) == nil {
+		return fmt.Errorf("%w: invalid host %q", ErrInvalidListenAddr, host)
+	}
+	return nil
+}
+
+func parseFD(addr string) (int, error) {
+	fd, err := strconv.Atoi(strings.TrimPrefix(addr, fdScheme))
+	if err != nil || fd < 0 {
+		return 0, fmt.Errorf("%w: invalid file descriptor in %q", ErrInvalidListenAddr, addr)
+	}
+	return fd, nil
+}
@@ -0,0 +1,73 @@
+package envy_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/fernferret/envy"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListenAddrVar(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		valid bool
+	}{
+		{name: "test port only", value: ":8080", valid: true},
+		{name: "test random port", value: ":0", valid: true},
+		{name: "test host and port", value: "localhost:8080", valid: true},
+		{name: "test ipv4", value: "127.0.0.1:8080", valid: true},
+		{name: "test ipv6", value: "[::1]:8080", valid: true},
+		{name: "test systemd fd", value: "fd://3", valid: true},
+		{name: "test missing port", value: "localhost"},
+		{name: "test bare ipv6", value: "::1:8080"},
+		{name: "test bracketed hostname", value: "[foo:bar]:80"},
+		{name: "test named port", value: ":http"},
+		{name: "test port too large", value: ":65536"},
+		{name: "test leading zero port", value: ":080"},
+		{name: "test invalid fd", value: "fd://three"},
+		{name: "test negative fd", value: "fd://-1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+
+			var addr string
+			envy.ListenAddrVarOnFlagSet(&addr, "listen", ":8080", "address to listen on", fs)
+
+			err := fs.Set("listen", tt.value)
+			if tt.valid {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.value, addr)
+			} else {
+				// pflag doesn't wrap the error, so check the message
+				assert.ErrorContains(t, err, envy.ErrInvalidListenAddr.Error())
+				assert.Equal(t, ":8080", addr)
+			}
+		})
+	}
+}
+
+func TestListenAddrVarEnv(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("FOO_LISTEN", "[::]:9090")
+	pflag.CommandLine = pflag.NewFlagSet("test", pflag.PanicOnError)
+
+	var addr string
+	envy.ListenAddrVar(&addr, "listen", ":8080", "address to listen on")
+	envy.Parse("FOO")
+
+	assert.Equal(t, "[::]:9090", addr)
+	assert.Equal(t, "address to listen on [FOO_LISTEN [::]:9090]", pflag.Lookup("listen").Usage)
+}
+
+func TestListen(t *testing.T) {
+	l, err := envy.Listen("127.0.0.1:0")
+	assert.NoError(t, err)
+	l.Close()
+
+	_, err = envy.Listen("fd://nope")
+	assert.ErrorIs(t, err, envy.ErrInvalidListenAddr)
+}
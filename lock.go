@@ -0,0 +1,115 @@
+package envy
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+var ErrLockMismatch = errors.New("configuration does not match the lock file")
+
+// lockFile is the format WriteLock writes.
+type lockFile struct {
+	// SHA-256 of the Schema, which changes whenever a flag, its type, default
+	// or variables do.
+	Schema string            `json:"schema"`
+	Values map[string]string `json:"values"`
+}
+
+// LockError is returned by VerifyLock when the configuration doesn't match
+// the lock file. It matches ErrLockMismatch with errors.Is.
+type LockError struct {
+	// SchemaChanged is set if the flags themselves changed.
+	SchemaChanged bool
+	Drifts        []Drift
+}
+
+func (e *LockError) Error() string {
+	var reasons []string
+	if e.SchemaChanged {
+		reasons = append(reasons, "schema changed")
+	}
+	for _, d := range e.Drifts {
+		reasons = append(reasons, d.String())
+	}
+	return fmt.Sprintf("%v: %s", ErrLockMismatch, strings.Join(reasons, ", "))
+}
+
+func (e *LockError) Unwrap() error {
+	return ErrLockMismatch
+}
+
+// WriteLock writes a lock file for the default pflag.CommandLine, see
+// WriteLockFlagSet.
+func WriteLock(path string) error {
+	return WriteLockFlagSet(path, pflag.CommandLine)
+}
+
+// WriteLockFlagSet writes the resolved value of every flag in the given
+// FlagSet, except secrets, together with a hash of its Schema, so the
+// reviewed configuration can be checked in next to a deployment and verified
+// with VerifyLock during the rollout. It must be called after pflag.Parse().
+func WriteLockFlagSet(path string, fs *pflag.FlagSet) error {
+	lock, err := lockOf(fs)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(lock, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// VerifyLock checks the default pflag.CommandLine against a lock file, see
+// VerifyLockFlagSet.
+func VerifyLock(path string) error {
+	return VerifyLockFlagSet(path, pflag.CommandLine)
+}
+
+// VerifyLockFlagSet checks that the given FlagSet resolved to exactly the
+// configuration in a lock file written by WriteLock, returning a LockError
+// describing every difference if it didn't. It must be called after
+// pflag.Parse().
+func VerifyLockFlagSet(path string, fs *pflag.FlagSet) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var want lockFile
+	if err := json.Unmarshal(data, &want); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	got, err := lockOf(fs)
+	if err != nil {
+		return err
+	}
+
+	lockErr := &LockError{SchemaChanged: want.Schema != got.Schema, Drifts: diff(want.Values, got.Values)}
+	if lockErr.SchemaChanged || len(lockErr.Drifts) > 0 {
+		return lockErr
+	}
+	return nil
+}
+
+// lockOf returns the lock file contents for the FlagSet.
+func lockOf(fs *pflag.FlagSet) (lockFile, error) {
+	schema, err := json.Marshal(SchemaFlagSet(fs))
+	if err != nil {
+		return lockFile{}, err
+	}
+	sum := sha256.Sum256(schema)
+	lock := lockFile{Schema: hex.EncodeToString(sum[:]), Values: map[string]string{}}
+	visitAll(fs, func(f *pflag.Flag) {
+		if !isSecret(f) {
+			lock.Values[f.Name] = displayValue(f)
+		}
+	})
+	return lock, nil
+}
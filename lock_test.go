@@ -0,0 +1,52 @@
+package envy_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/fernferret/envy"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteLock(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("FOO_URL", "http://example.com")
+	os.Setenv("FOO_TOKEN", "hunter2")
+	pflag.CommandLine = pflag.NewFlagSet("test", pflag.PanicOnError)
+	pflag.String("url", "http://localhost", "set the url")
+	pflag.String("token", "", "api token")
+	pflag.Int("workers", 4, "number of workers")
+	envy.Secret("token")
+	envy.Parse("FOO")
+
+	path := filepath.Join(t.TempDir(), "envy.lock")
+	assert.NoError(t, envy.WriteLock(path))
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.NotContains(t, string(data), "hunter2")
+	assert.NotContains(t, string(data), `"token"`)
+	assert.NoError(t, envy.VerifyLock(path))
+
+	// Secrets can rotate without breaking the lock.
+	pflag.Set("token", "rotated")
+	assert.NoError(t, envy.VerifyLock(path))
+
+	pflag.Set("workers", "8")
+	err = envy.VerifyLock(path)
+	assert.ErrorIs(t, err, envy.ErrLockMismatch)
+	assert.EqualError(t, err, `configuration does not match the lock file: --workers changed from "4" to "8"`)
+
+	pflag.Bool("debug", false, "debug")
+	err = envy.VerifyLock(path)
+	var lockErr *envy.LockError
+	if assert.ErrorAs(t, err, &lockErr) {
+		assert.True(t, lockErr.SchemaChanged)
+		assert.Len(t, lockErr.Drifts, 2)
+	}
+
+	assert.ErrorIs(t, envy.VerifyLock(filepath.Join(t.TempDir(), "missing")), os.ErrNotExist)
+}
@@ -0,0 +1,47 @@
+package envy
+
+import (
+	"encoding"
+
+	"github.com/spf13/pflag"
+)
+
+// LevelVar is satisfied by the level holders of the common logging libraries:
+// *slog.LevelVar, *zap.AtomicLevel and *logrus.Level.
+type LevelVar interface {
+	encoding.TextUnmarshaler
+	String() string
+}
+
+// levelValue adapts a LevelVar to a pflag.Value.
+type levelValue struct {
+	level LevelVar
+}
+
+func (v *levelValue) Set(val string) error {
+	return v.level.UnmarshalText([]byte(val))
+}
+
+func (v *levelValue) String() string {
+	return v.level.String()
+}
+
+func (v *levelValue) Type() string {
+	return "level"
+}
+
+// LogLevelVar defines a flag on the default pflag.CommandLine that sets the
+// given log level, see LogLevelVarOnFlagSet.
+func LogLevelVar(level LevelVar, name, usage string) {
+	LogLevelVarOnFlagSet(level, name, usage, pflag.CommandLine)
+}
+
+// LogLevelVarOnFlagSet defines a flag on the given FlagSet that sets the given
+// log level, the level's current value is used as the default. Since slog, zap
+// and logrus levels can be changed while the program runs, setting the flag
+// again later (for example from a reload handler) takes effect immediately, so
+// the flag is marked with Reloadable.
+func LogLevelVarOnFlagSet(level LevelVar, name, usage string, fs *pflag.FlagSet) {
+	fs.Var(&levelValue{level: level}, name, usage)
+	annotate(fs.Lookup(name), AnnotationReloadable, "true")
+}
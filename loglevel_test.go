@@ -0,0 +1,49 @@
+package envy_test
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/fernferret/envy"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+)
+
+// testLevel mimics the levels of logrus and friends without the dependency.
+type testLevel int
+
+func (l *testLevel) UnmarshalText(text []byte) error {
+	for i, name := range []string{"debug", "info", "warn"} {
+		if strings.EqualFold(string(text), name) {
+			*l = testLevel(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown level %q", text)
+}
+
+func (l testLevel) String() string {
+	return []string{"debug", "info", "warn"}[l]
+}
+
+func TestLogLevelVar(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("FOO_LOG_LEVEL", "WARN")
+	pflag.CommandLine = pflag.NewFlagSet("test", pflag.PanicOnError)
+
+	level := testLevel(1)
+	envy.LogLevelVar(&level, "log-level", "set the log level")
+
+	flag := pflag.Lookup("log-level")
+	assert.Equal(t, "info", flag.DefValue)
+
+	envy.Parse("FOO")
+	assert.Equal(t, testLevel(2), level)
+
+	pflag.CommandLine.Parse([]string{"--log-level=debug"})
+	assert.Equal(t, testLevel(0), level)
+
+	assert.Error(t, pflag.Set("log-level", "loud"))
+}
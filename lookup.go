@@ -0,0 +1,79 @@
+package envy
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Lookuper is where envy reads variables from, the process environment unless
+// replaced with SetLookuper or WithLookuper.
+type Lookuper interface {
+	Lookup(key string) (string, bool)
+}
+
+// EnvLookuper reads the process environment.
+type EnvLookuper struct{}
+
+func (EnvLookuper) Lookup(key string) (string, bool) {
+	return os.LookupEnv(key)
+}
+
+// MapLookuper reads variables from a map, handy for tests and fixtures.
+type MapLookuper map[string]string
+
+func (m MapLookuper) Lookup(key string) (string, bool) {
+	val, ok := m[key]
+	return val, ok
+}
+
+// SetLookuper replaces where envy reads variables from, passing nil restores
+// the process environment. It must be called before the call to envy.Parse().
+func SetLookuper(l Lookuper) {
+	if l == nil {
+		l = EnvLookuper{}
+	}
+	std.lookuper = l
+}
+
+// WithLookuper works like SetLookuper for this Envy only.
+func WithLookuper(l Lookuper) Option {
+	return func(e *Envy) {
+		if l == nil {
+			l = EnvLookuper{}
+		}
+		e.lookuper = l
+	}
+}
+
+// lookupAny returns the first of the given variables that is set. If none are,
+// the first name is returned for use in the flag's usage.
+func (e *Envy) lookupAny(names []string) (string, string, bool) {
+	for _, name := range names {
+		if val, ok := e.lookuper.Lookup(name); ok {
+			return name, val, true
+		}
+	}
+	return names[0], "", false
+}
+
+// DirLookuper reads each variable from the file of the same name in a
+// directory, matching how Kubernetes mounts ConfigMaps and Secrets, so
+// /etc/config/MYAPP_URL holds MYAPP_URL. A trailing newline is dropped.
+// Chain it behind the environment to let real variables win:
+//
+//	envy.SetLookuper(envy.Chain{envy.EnvLookuper{}, envy.DirLookuper("/etc/config")})
+//
+// Files that can't be read are treated as unset.
+type DirLookuper string
+
+func (d DirLookuper) Lookup(key string) (string, bool) {
+	if key == "" || strings.ContainsAny(key, `/\`) || strings.HasPrefix(key, ".") {
+		return "", false
+	}
+	val, err := readValueFile(filepath.Join(string(d), key))
+	if err != nil {
+		return "", false
+	}
+	return val, true
+}
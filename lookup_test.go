@@ -0,0 +1,86 @@
+package envy_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/fernferret/envy"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithLookuper(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("FOO_URL", "http://from-env")
+
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	url := fs.String("url", "", "set the url")
+	fs.Int("workers", 1, "number of workers")
+	fs.Bool("debug-mode", false, "debug mode")
+	envy.GateOnFlagSet("debug", func(fs *pflag.FlagSet) {
+		fs.Bool("trace", false, "trace everything")
+	}, fs)
+
+	l := envy.MapLookuper{
+		"FOO_URL":     "http://from-map",
+		"FOO_WORKERS": "3",
+		"FOO_DEBUG":   "true",
+	}
+	envy.New(envy.WithPrefix("FOO"), envy.WithFlagSet(fs), envy.WithLookuper(l)).Parse()
+
+	assert.Equal(t, "http://from-map", *url)
+	assert.Equal(t, "3", fs.Lookup("workers").Value.String())
+	assert.NotNil(t, fs.Lookup("trace"))
+	assert.Equal(t, "env FOO_URL", envy.ExplainFlagSet("url", fs).Layers[1].Source)
+	assert.Equal(t, "http://from-map", envy.ExplainFlagSet("url", fs).Layers[1].Value)
+}
+
+func TestSetLookuper(t *testing.T) {
+	defer envy.SetLookuper(nil)
+
+	os.Clearenv()
+	os.Setenv("FOO_URL", "http://from-env")
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	url := fs.String("url", "", "set the url")
+
+	envy.SetLookuper(envy.MapLookuper{})
+	envy.ParseFlagSet("FOO", fs)
+	assert.Empty(t, *url)
+
+	envy.SetLookuper(nil)
+	envy.ParseFlagSet("FOO", fs)
+	assert.Equal(t, "http://from-env", *url)
+}
+
+func TestDirLookuper(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("FOO_NAME", "from-env")
+	dir := t.TempDir()
+	for key, val := range map[string]string{"FOO_NAME": "from-dir", "FOO_URL": "http://from-dir\n", "FOO_WORKERS": "3"} {
+		if err := os.WriteFile(filepath.Join(dir, key), []byte(val), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(dir, "FOO_DEBUG"), 0o700); err != nil {
+		t.Fatal(err)
+	}
+
+	l := envy.DirLookuper(dir)
+	val, ok := l.Lookup("FOO_URL")
+	assert.True(t, ok)
+	assert.Equal(t, "http://from-dir", val)
+	for _, key := range []string{"FOO_MISSING", "FOO_DEBUG", "", "../FOO_URL", ".."} {
+		_, ok := l.Lookup(key)
+		assert.False(t, ok, key)
+	}
+
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	name := fs.String("name", "", "the name")
+	url := fs.String("url", "", "set the url")
+	workers := fs.Int("workers", 1, "number of workers")
+	envy.New(envy.WithPrefix("FOO"), envy.WithFlagSet(fs), envy.WithLookuper(envy.Chain{envy.EnvLookuper{}, l})).Parse()
+	assert.Equal(t, "from-env", *name)
+	assert.Equal(t, "http://from-dir", *url)
+	assert.Equal(t, 3, *workers)
+}
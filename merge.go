@@ -0,0 +1,165 @@
+package envy
+
+import (
+	"encoding/csv"
+	"errors"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+var (
+	ErrNotSlice     = errors.New("flag is not a slice")
+	ErrUnknownMerge = errors.New("slice merge must be env-then-flags or flags-then-env")
+)
+
+// Used to record how a slice flag combines values from the environment with
+// ones from the command line.
+const AnnotationMerge = "envy_merge"
+
+// SliceMerge controls how a slice flag set by envy combines with values given
+// on the command line, see MergeSlice.
+type SliceMerge int
+
+const (
+	// FlagsReplaceEnv drops the values from envy as soon as the flag is given
+	// on the command line, like any other flag. This is the default.
+	FlagsReplaceEnv SliceMerge = iota
+
+	// EnvThenFlags keeps the values from envy and appends the ones from the
+	// command line, for a base list in the environment with extras per run.
+	EnvThenFlags
+
+	// FlagsThenEnv puts the values from the command line first.
+	FlagsThenEnv
+)
+
+var mergeNames = map[SliceMerge]string{
+	EnvThenFlags: "env-then-flags",
+	FlagsThenEnv: "flags-then-env",
+}
+
+// MergeSlice sets how a slice flag in the default pflag.CommandLine combines
+// values, see MergeSliceOnFlagSet.
+func MergeSlice(name string, mode SliceMerge) {
+	MergeSliceOnFlagSet(name, mode, pflag.CommandLine)
+}
+
+// MergeSliceOnFlagSet sets how a slice flag combines the values envy read
+// from the environment or a config file with ones given on the command line.
+// By default the command line replaces them, see SliceMerge for the
+// alternatives. It must be called before the call to envy.Parse().
+func MergeSliceOnFlagSet(name string, mode SliceMerge, fs *pflag.FlagSet) {
+	std.on("", fs).MergeSlice(name, mode)
+}
+
+// MergeSlice sets how a slice flag combines values, see MergeSliceOnFlagSet.
+func (e *Envy) MergeSlice(name string, mode SliceMerge) {
+	f := e.fs.Lookup(name)
+	if f == nil {
+		e.fail(&SetError{Flag: name, Err: ErrFlagNotExists})
+		return
+	}
+	if err := setMerge(f, mode); err != nil {
+		e.fail(&SetError{Flag: name, Err: err})
+	}
+}
+
+// setMerge sets how the slice flag combines values.
+func setMerge(f *pflag.Flag, mode SliceMerge) error {
+	if _, ok := valueOf(f).(pflag.SliceValue); !ok {
+		return ErrNotSlice
+	}
+	if mode == FlagsReplaceEnv {
+		delete(f.Annotations, AnnotationMerge)
+		return nil
+	}
+	annotate(f, AnnotationMerge, mergeNames[mode])
+	if _, ok := wrapped[*mergedValue](f); !ok {
+		f.Value = wrap(&mergedValue{Value: f.Value, flag: f})
+	}
+	return nil
+}
+
+// mergeOf returns how the flag combines values.
+func mergeOf(f *pflag.Flag) SliceMerge {
+	if val, ok := f.Annotations[AnnotationMerge]; ok {
+		for mode, name := range mergeNames {
+			if name == val[0] {
+				return mode
+			}
+		}
+	}
+	return FlagsReplaceEnv
+}
+
+// mergedValue sits in front of a slice flag with a SliceMerge, keeping the
+// values from envy apart from the ones given on the command line.
+type mergedValue struct {
+	pflag.Value
+	flag *pflag.Flag
+
+	env   []string
+	flags []string
+}
+
+func (v *mergedValue) Set(val string) error {
+	// Once Set the slice appends to whatever it holds, so parse the value
+	// on its own.
+	s := innermost(v.Value).(pflag.SliceValue)
+	s.Replace(nil)
+	if err := v.Value.Set(val); err != nil {
+		s.Replace(v.merged())
+		return err
+	}
+	v.flags = append(v.flags, s.GetSlice()...)
+	return s.Replace(v.merged())
+}
+
+func (v *mergedValue) unwrap() pflag.Value {
+	return v.Value
+}
+
+func (v *mergedValue) guard(_ []string, change func() error) error {
+	return change()
+}
+
+// merged returns the flag's values according to its SliceMerge.
+func (v *mergedValue) merged() []string {
+	switch mergeOf(v.flag) {
+	case EnvThenFlags:
+		return append(append([]string{}, v.env...), v.flags...)
+	case FlagsThenEnv:
+		return append(append([]string{}, v.flags...), v.env...)
+	}
+	return v.flags
+}
+
+// setSlice replaces a slice flag's values with the ones from envy. Unlike Set,
+// Replace leaves the slice looking untouched to pflag, so the command line
+// replaces the values in turn unless the flag has a SliceMerge.
+func setSlice(f *pflag.Flag, s pflag.SliceValue, val string) error {
+	items, err := sliceItems(f, val)
+	if err != nil {
+		return err
+	}
+	if err := guarded(f.Value, items, func() error { return s.Replace(items) }); err != nil {
+		return err
+	}
+	if m, ok := wrapped[*mergedValue](f); ok {
+		m.env = s.GetSlice()
+	}
+	return nil
+}
+
+// sliceItems splits a value for a slice flag the way pflag does, as comma
+// separated values, except for string arrays which take it whole.
+func sliceItems(f *pflag.Flag, val string) ([]string, error) {
+	if f.Value.Type() == "stringArray" {
+		return []string{val}, nil
+	}
+	if val == "" {
+		return []string{}, nil
+	}
+	return csv.NewReader(strings.NewReader(val)).Read()
+}
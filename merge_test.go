@@ -0,0 +1,88 @@
+package envy_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/fernferret/envy"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMergeSlice(t *testing.T) {
+	tests := []struct {
+		name string
+		mode envy.SliceMerge
+		args []string
+		want []string
+	}{
+		{"replace", envy.FlagsReplaceEnv, []string{"--tags=c", "--tags=d"}, []string{"c", "d"}},
+		{"env then flags", envy.EnvThenFlags, []string{"--tags=c", "--tags=d"}, []string{"a", "b", "c", "d"}},
+		{"flags then env", envy.FlagsThenEnv, []string{"--tags=c,d"}, []string{"c", "d", "a", "b"}},
+		{"no flags", envy.EnvThenFlags, nil, []string{"a", "b"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+			tags := fs.StringSlice("tags", []string{"x"}, "tags")
+			ports := fs.IntSlice("ports", nil, "ports")
+			e := envy.New(envy.WithFlagSet(fs), envy.WithPrefix("FOO"),
+				envy.WithLookuper(envy.MapLookuper{"FOO_TAGS": "a,b", "FOO_PORTS": "80"}))
+			e.MergeSlice("tags", tt.mode)
+			e.MergeSlice("ports", tt.mode)
+			assert.NoError(t, e.ParseE())
+			if err := fs.Parse(tt.args); err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, tt.want, *tags)
+			assert.Equal(t, []int{80}, *ports)
+
+			// The flag is still a slice to anything inspecting it.
+			_, ok := fs.Lookup("tags").Value.(pflag.SliceValue)
+			assert.True(t, ok)
+			got, err := fs.GetStringSlice("tags")
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestMergeSliceInvalid(t *testing.T) {
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	ports := fs.IntSlice("ports", nil, "ports")
+	e := envy.New(envy.WithFlagSet(fs), envy.WithPrefix("FOO"),
+		envy.WithLookuper(envy.MapLookuper{"FOO_PORTS": "80"}))
+	e.MergeSlice("ports", envy.EnvThenFlags)
+	assert.NoError(t, e.ParseE())
+
+	assert.Error(t, fs.Parse([]string{"--ports=http"}))
+	assert.Equal(t, []int{80}, *ports)
+	if err := fs.Parse([]string{"--ports=443"}); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []int{80, 443}, *ports)
+}
+
+func TestMergeSliceDefault(t *testing.T) {
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	tags := fs.StringSlice("tags", nil, "tags")
+	names := fs.StringArray("names", nil, "names")
+	env := envy.MapLookuper{"FOO_TAGS": `a,"b,c"`, "FOO_NAMES": "x,y"}
+	assert.NoError(t, envy.New(envy.WithFlagSet(fs), envy.WithPrefix("FOO"), envy.WithLookuper(env)).ParseE())
+	assert.Equal(t, []string{"a", "b,c"}, *tags)
+	assert.Equal(t, []string{"x,y"}, *names)
+
+	// Nothing is put in front of the flag's own value.
+	assert.Equal(t, "*pflag.stringSliceValue", fmt.Sprintf("%T", fs.Lookup("tags").Value))
+}
+
+func TestMergeSliceNotSlice(t *testing.T) {
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	fs.String("url", "", "set the url")
+	e := envy.New(envy.WithFlagSet(fs))
+	assert.PanicsWithError(t, "--url: "+envy.ErrNotSlice.Error(), func() { e.MergeSlice("url", envy.EnvThenFlags) })
+}
+
+func TestMergeSliceMissing(t *testing.T) {
+	assert.PanicsWithError(t, "--missing: "+envy.ErrFlagNotExists.Error(), func() { envy.MergeSlice("missing", envy.EnvThenFlags) })
+}
@@ -0,0 +1,124 @@
+package envy
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+var ErrPrefixRetired = errors.New("environment variable uses a retired prefix")
+
+// PrefixMigration tracks a rename of the prefix, see MigratePrefix.
+type PrefixMigration struct {
+	Old, New string
+
+	mu       sync.Mutex
+	deadline time.Time
+	uses     map[string]int
+}
+
+// MigratePrefix keeps reading flags in the default pflag.CommandLine under an
+// old prefix, see the Envy method.
+func MigratePrefix(oldPfx, newPfx string) *PrefixMigration {
+	return std.MigratePrefix(oldPfx, newPfx)
+}
+
+// MigratePrefix keeps reading every flag parsed under newPfx from its variable
+// under oldPfx as well, so a prefix can be renamed without breaking existing
+// deployments:
+//
+//	m := envy.MigratePrefix("OLDAPP", "MYAPP").FailAfter(cutover)
+//
+// Like a deprecated variable, the new name wins if both are set and the
+// DeprecationHandler is called whenever the old one supplies a value. Flags
+// with custom names from SetEnvName aren't affected. Uses of the old prefix are
+// counted for metrics, see Uses. It must be called before the call to
+// envy.Parse().
+func (e *Envy) MigratePrefix(oldPfx, newPfx string) *PrefixMigration {
+	m := &PrefixMigration{Old: normalizePrefix(oldPfx), New: normalizePrefix(newPfx), uses: map[string]int{}}
+	e.migrations = append(e.migrations, m)
+	return m
+}
+
+// FailAfter makes Parse report a SetError wrapping ErrPrefixRetired for any
+// variable still using the old prefix after the deadline, instead of calling
+// the DeprecationHandler. It returns the migration so it can be chained.
+func (m *PrefixMigration) FailAfter(deadline time.Time) *PrefixMigration {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.deadline = deadline
+	return m
+}
+
+// Uses returns how many times each variable under the old prefix supplied a
+// flag's value, across every Parse so far. It's safe to call while parsing,
+// like from a metrics collector.
+func (m *PrefixMigration) Uses() map[string]int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	uses := make(map[string]int, len(m.uses))
+	for name, n := range m.uses {
+		uses[name] = n
+	}
+	return uses
+}
+
+// Total returns how many times the old prefix supplied a flag's value.
+func (m *PrefixMigration) Total() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	total := 0
+	for _, n := range m.uses {
+		total += n
+	}
+	return total
+}
+
+// use records a value read from oldName, failing past the deadline.
+func (m *PrefixMigration) use(oldName, newName string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.uses[oldName]++
+	if !m.deadline.IsZero() && time.Now().After(m.deadline) {
+		return fmt.Errorf("%w %s since %s, set %s instead", ErrPrefixRetired, m.Old, m.deadline.Format(time.DateOnly), newName)
+	}
+	return nil
+}
+
+// migrationNames returns the flag's variable under the old prefix of each
+// migration to the current one, checked after its current variables.
+func (e *Envy) migrationNames(f *pflag.Flag) []string {
+	if _, ok := f.Annotations[AnnotationCustom]; ok {
+		return nil
+	}
+	var names []string
+	for _, m := range e.migrations {
+		if m.New == e.prefix {
+			names = append(names, e.envNameFor(m.Old, f))
+		}
+	}
+	return names
+}
+
+// migrated reports whether the flag's value came from a variable under an old
+// prefix, returning the variable replacing it.
+func (e *Envy) migrated(f *pflag.Flag, envName string) (string, bool, error) {
+	if _, ok := f.Annotations[AnnotationCustom]; ok {
+		return "", false, nil
+	}
+	for _, m := range e.migrations {
+		if m.New != e.prefix {
+			continue
+		}
+		oldName := e.envNameFor(m.Old, f)
+		if envName == oldName || envName == oldName+"_FILE" {
+			newName := e.envNameFor(m.New, f) + strings.TrimPrefix(envName, oldName)
+			return newName, true, m.use(envName, newName)
+		}
+	}
+	return "", false, nil
+}
@@ -0,0 +1,71 @@
+package envy_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/fernferret/envy"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMigratePrefix(t *testing.T) {
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	url := fs.String("url", "http://localhost", "set the url")
+	workers := fs.Int("workers", 4, "number of workers")
+	token := fs.String("token", "", "api token")
+	fs.SetAnnotation("token", envy.AnnotationCustom, []string{"API_TOKEN"})
+
+	var deprecated [][]string
+	env := envy.MapLookuper{
+		"OLDAPP_URL":     "http://old",
+		"OLDAPP_WORKERS": "2",
+		"MYAPP_WORKERS":  "8",
+		"OLDAPP_TOKEN":   "ignored",
+	}
+	e := envy.New(envy.WithFlagSet(fs), envy.WithPrefix("MYAPP"), envy.WithLookuper(env),
+		envy.WithDeprecationHandler(func(flagName, oldName, newName string) {
+			deprecated = append(deprecated, []string{flagName, oldName, newName})
+		}))
+	m := e.MigratePrefix("oldapp", "myapp")
+	assert.NoError(t, e.ParseE())
+
+	assert.Equal(t, "http://old", *url)
+	assert.Equal(t, 8, *workers)
+	assert.Equal(t, "", *token)
+	assert.Equal(t, [][]string{{"url", "OLDAPP_URL", "MYAPP_URL"}}, deprecated)
+	assert.Equal(t, map[string]int{"OLDAPP_URL": 1}, m.Uses())
+	assert.Equal(t, 1, m.Total())
+}
+
+func TestMigratePrefixFailAfter(t *testing.T) {
+	tests := []struct {
+		name     string
+		deadline time.Time
+		err      bool
+	}{
+		{"before", time.Now().Add(time.Hour), false},
+		{"after", time.Now().Add(-time.Hour), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+			url := fs.String("url", "http://localhost", "set the url")
+			e := envy.New(envy.WithFlagSet(fs), envy.WithPrefix("MYAPP"),
+				envy.WithLookuper(envy.MapLookuper{"OLDAPP_URL": "http://old"}),
+				envy.WithDeprecationHandler(func(string, string, string) {}))
+			m := e.MigratePrefix("OLDAPP", "MYAPP").FailAfter(tt.deadline)
+
+			err := e.ParseE()
+			assert.Equal(t, 1, m.Total())
+			if !tt.err {
+				assert.NoError(t, err)
+				assert.Equal(t, "http://old", *url)
+				return
+			}
+			assert.ErrorIs(t, err, envy.ErrPrefixRetired)
+			assert.ErrorContains(t, err, "set MYAPP_URL instead")
+			assert.Equal(t, "http://localhost", *url)
+		})
+	}
+}
@@ -0,0 +1,180 @@
+package envy
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+const (
+	// Used to record the module that registered a flag, its name becomes part
+	// of the flag's environment variable.
+	AnnotationModule = "envy_module"
+
+	// Used to record the environment variable that gated a flag's existence.
+	AnnotationGate = "envy_gate"
+)
+
+type module struct {
+	name     string
+	register func(fs *pflag.FlagSet)
+
+	// Gates are enabled by their own environment variable and don't add their
+	// name to the flags' environment variables.
+	gate bool
+}
+
+// Modules waiting to be registered by the next ParseFlagSet of each FlagSet.
+var modules registry[*pflag.FlagSet, []module]
+
+// Module declares a named group of flags that are registered on the default
+// pflag.CommandLine when envy.Parse() runs. See ModuleOnFlagSet.
+func Module(name string, register func(fs *pflag.FlagSet)) {
+	ModuleOnFlagSet(name, register, pflag.CommandLine)
+}
+
+// ModuleOnFlagSet declares a named group of flags that are registered on the
+// given FlagSet when envy.ParseFlagSet() runs. Environment variables for the
+// module's flags include the module name, so --addr in module "redis" with a
+// prefix of MYAPP is read from MYAPP_REDIS_ADDR.
+//
+// If the MYAPP_MODULES environment variable is set, only the modules listed in
+// it (comma separated) are registered, the rest don't exist on the command
+// line at all. It must be called before the call to envy.Parse().
+func ModuleOnFlagSet(name string, register func(fs *pflag.FlagSet), fs *pflag.FlagSet) {
+	modules.update(fs, func(ms []module) []module {
+		return append(ms, module{name: name, register: register})
+	})
+}
+
+// Gate declares a group of flags that are only registered on the default
+// pflag.CommandLine when the gate's environment variable is true. See
+// GateOnFlagSet.
+func Gate(name string, register func(fs *pflag.FlagSet)) {
+	GateOnFlagSet(name, register, pflag.CommandLine)
+}
+
+// GateOnFlagSet declares a group of flags that are only registered on the given
+// FlagSet when the gate's environment variable is true, keeping the help output
+// clean for normal users. A gate named "experimental" with a prefix of MYAPP is
+// enabled by MYAPP_EXPERIMENTAL=true. Like bool flags, values other than true
+// or false panic, or are returned by ParseE. It must be called before the call
+// to envy.Parse().
+func GateOnFlagSet(name string, register func(fs *pflag.FlagSet), fs *pflag.FlagSet) {
+	modules.update(fs, func(ms []module) []module {
+		return append(ms, module{name: name, register: register, gate: true})
+	})
+}
+
+// registerModules registers every pending module of the FlagSet that is
+// enabled for the prefix, returning any invalid gates.
+func (e *Envy) registerModules() ParseErrors {
+	pending, _ := modules.take(e.fs)
+
+	var errs ParseErrors
+	enabled, filtered := e.lookuper.Lookup(e.prefix + "MODULES")
+	for _, m := range pending {
+		key, name, on := AnnotationModule, m.name, true
+		if m.gate {
+			key, name = AnnotationGate, e.nameFunc(e.prefix, m.name)
+			e.gates = append(e.gates, name)
+			var err error
+			if on, err = e.gateEnabled(name); err != nil {
+				errs = append(errs, &SetError{EnvName: name, Err: err})
+			}
+		} else if filtered && !listContains(enabled, m.name) {
+			continue
+		}
+
+		mfs := pflag.NewFlagSet(m.name, pflag.ContinueOnError)
+		m.register(mfs)
+		mfs.VisitAll(func(f *pflag.Flag) {
+			annotate(f, key, name)
+		})
+		if !on {
+			// Kept out of the FlagSet, but still documented by Schema.
+			mfs.VisitAll(func(f *pflag.Flag) {
+				e.gated = append(e.gated, f)
+			})
+			continue
+		}
+		e.fs.AddFlagSet(mfs)
+	}
+	return errs
+}
+
+// gateEnabled reports whether the gate's environment variable is set to true.
+func (e *Envy) gateEnabled(envName string) (bool, error) {
+	val, ok := e.lookuper.Lookup(envName)
+	if !ok {
+		return false, nil
+	}
+	return e.parseBool(val)
+}
+
+// GroupedUsages returns the usage of every flag in the default
+// pflag.CommandLine with a section per module, see GroupedUsagesFlagSet.
+func GroupedUsages() string {
+	return GroupedUsagesFlagSet(pflag.CommandLine)
+}
+
+// GroupedUsagesFlagSet returns the usage of every flag in the given FlagSet
+// like FlagUsages, except flags registered by a Module are listed after the
+// rest in their own section per module, sorted by module name. Use it in place
+// of the FlagSet's usage function:
+//
+//	pflag.Usage = func() { fmt.Fprint(os.Stderr, envy.GroupedUsages()) }
+func GroupedUsagesFlagSet(fs *pflag.FlagSet) string {
+	top := pflag.NewFlagSet("", pflag.ContinueOnError)
+	top.SortFlags = fs.SortFlags
+	groups := map[string]*pflag.FlagSet{}
+	fs.VisitAll(func(f *pflag.Flag) {
+		name, ok := f.Annotations[AnnotationModule]
+		if !ok {
+			top.AddFlag(f)
+			return
+		}
+		group, ok := groups[name[0]]
+		if !ok {
+			group = pflag.NewFlagSet(name[0], pflag.ContinueOnError)
+			group.SortFlags = fs.SortFlags
+			groups[name[0]] = group
+		}
+		group.AddFlag(f)
+	})
+
+	names := make([]string, 0, len(groups))
+	for name := range groups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	b := &strings.Builder{}
+	b.WriteString(top.FlagUsages())
+	for _, name := range names {
+		fmt.Fprintf(b, "\n%s flags:\n%s", name, groups[name].FlagUsages())
+	}
+	return b.String()
+}
+
+// modulePrefix returns the portion of the environment variable contributed by
+// the module the flag belongs to, if any.
+func modulePrefix(f *pflag.Flag) string {
+	if val, ok := f.Annotations[AnnotationModule]; ok {
+		return normalizePrefix(strings.ReplaceAll(val[0], "-", "_"))
+	}
+	return ""
+}
+
+// listContains reports whether the comma separated list contains the item,
+// ignoring case and whitespace.
+func listContains(list, item string) bool {
+	for _, v := range strings.Split(list, ",") {
+		if strings.EqualFold(strings.TrimSpace(v), item) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,171 @@
+package envy_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/fernferret/envy"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestModule(t *testing.T) {
+	tests := []struct {
+		name    string
+		modules string
+		exp     []string
+		missing []string
+	}{
+		{
+			name: "test all modules enabled by default",
+			exp:  []string{"addr", "bucket"},
+		},
+		{
+			name:    "test only listed modules",
+			modules: "Redis",
+			exp:     []string{"addr"},
+			missing: []string{"bucket"},
+		},
+		{
+			name:    "test empty list disables all",
+			modules: " ",
+			missing: []string{"addr", "bucket"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv("FOO_REDIS_ADDR", "127.0.0.1:6379")
+			if tt.modules != "" {
+				os.Setenv("FOO_MODULES", tt.modules)
+			}
+			pflag.CommandLine = pflag.NewFlagSet("test", pflag.PanicOnError)
+
+			envy.Module("redis", func(fs *pflag.FlagSet) {
+				fs.String("addr", "", "redis address")
+			})
+			envy.Module("s3", func(fs *pflag.FlagSet) {
+				fs.String("bucket", "", "bucket name")
+			})
+
+			envy.Parse("FOO")
+
+			for _, name := range tt.exp {
+				assert.NotNil(t, pflag.Lookup(name), name)
+			}
+			for _, name := range tt.missing {
+				assert.Nil(t, pflag.Lookup(name), name)
+			}
+			if f := pflag.Lookup("addr"); f != nil {
+				assert.Equal(t, "redis address [FOO_REDIS_ADDR 127.0.0.1:6379]", f.Usage)
+				assert.Equal(t, "127.0.0.1:6379", f.Value.String())
+			}
+			if f := pflag.Lookup("bucket"); f != nil {
+				assert.Equal(t, "bucket name [FOO_S3_BUCKET]", f.Usage)
+			}
+		})
+	}
+}
+
+func TestModuleDocs(t *testing.T) {
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	fs.String("url", "http://localhost", "set the url")
+	envy.ModuleOnFlagSet("s3", func(fs *pflag.FlagSet) {
+		fs.String("bucket", "", "bucket name")
+	}, fs)
+	envy.ModuleOnFlagSet("redis", func(fs *pflag.FlagSet) {
+		fs.String("addr", "", "redis address")
+	}, fs)
+	assert.NoError(t, envy.New(envy.WithFlagSet(fs), envy.WithPrefix("FOO"), envy.WithLookuper(envy.MapLookuper{})).ParseE())
+
+	modules := map[string]string{}
+	for _, b := range envy.SchemaFlagSet(fs) {
+		modules[b.Flag] = b.Module
+	}
+	assert.Equal(t, map[string]string{"addr": "redis", "bucket": "s3", "url": ""}, modules)
+
+	assert.Equal(t, `      --url string   set the url [FOO_URL] (default "http://localhost")
+
+redis flags:
+      --addr string   redis address [FOO_REDIS_ADDR]
+
+s3 flags:
+      --bucket string   bucket name [FOO_S3_BUCKET]
+`, envy.GroupedUsagesFlagSet(fs))
+}
+
+func ExampleModule() {
+	// Reset CommandLine flags for example, you don't need this in your code!
+	pflag.CommandLine = pflag.NewFlagSet("test", pflag.PanicOnError)
+	os.Clearenv()
+
+	pflag.String("url", "http://localhost:8080", "set the url")
+
+	// Flags in the cache module get a COOL_APP_CACHE_ prefix
+	envy.Module("cache", func(fs *pflag.FlagSet) {
+		fs.Int("size", 64, "cache size in MB")
+	})
+
+	envy.Parse("COOL_APP")
+
+	pflag.CommandLine.SortFlags = false
+	pflag.CommandLine.SetOutput(os.Stdout)
+	pflag.PrintDefaults()
+	// Output: --url string   set the url [COOL_APP_URL] (default "http://localhost:8080")
+	//       --size int     cache size in MB [COOL_APP_CACHE_SIZE] (default 64)
+}
+
+func TestGate(t *testing.T) {
+	tests := []struct {
+		name  string
+		env   string
+		exp   bool
+		panic bool
+	}{
+		{
+			name: "test gate unset",
+		},
+		{
+			name: "test gate disabled",
+			env:  "false",
+		},
+		{
+			name: "test gate enabled",
+			env:  "true",
+			exp:  true,
+		},
+		{
+			name:  "test invalid gate",
+			env:   "maybe",
+			panic: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			os.Clearenv()
+			if tt.env != "" {
+				os.Setenv("FOO_EXPERIMENTAL", tt.env)
+			}
+			os.Setenv("FOO_TURBO", "true")
+			pflag.CommandLine = pflag.NewFlagSet("test", pflag.PanicOnError)
+
+			envy.Gate("experimental", func(fs *pflag.FlagSet) {
+				fs.Bool("turbo", false, "go faster")
+			})
+
+			if tt.panic {
+				assert.Panics(t, func() { envy.Parse("FOO") })
+				return
+			}
+			envy.Parse("FOO")
+
+			f := pflag.Lookup("turbo")
+			if !tt.exp {
+				assert.Nil(t, f)
+				return
+			}
+			assert.Equal(t, "go faster [FOO_TURBO true]", f.Usage)
+			assert.Equal(t, "true", f.Value.String())
+		})
+	}
+}
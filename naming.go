@@ -0,0 +1,71 @@
+package envy
+
+import (
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+// NameFunc derives the environment variable name for a flag. The prefix has
+// already been normalized to uppercase with a single trailing underscore, or
+// is empty.
+type NameFunc func(pfx, flagName string) string
+
+// ffReplacer matches the replacer peterbourgon/ff uses for env var keys.
+var ffReplacer = strings.NewReplacer("-", "_", ".", "_", "/", "_")
+
+// DefaultNameFunc is envy's standard naming: the uppercased flag name with
+// dashes turned into underscores, so --kube-config becomes PFX_KUBE_CONFIG.
+func DefaultNameFunc(pfx, flagName string) string {
+	return pfx + strings.ReplaceAll(strings.ToUpper(flagName), "-", "_")
+}
+
+// FFNameFunc derives names the same way peterbourgon/ff does, additionally
+// replacing dots and slashes with underscores. Projects migrating from ff can
+// use it to keep their existing variable names.
+func FFNameFunc(pfx, flagName string) string {
+	return pfx + ffReplacer.Replace(strings.ToUpper(flagName))
+}
+
+// SetNameFunc replaces the function used to derive environment variable names
+// from flag names, passing nil restores DefaultNameFunc. Names set with
+// SetEnvName are never passed through it. It must be called before the call to
+// envy.Parse().
+func SetNameFunc(fn NameFunc) {
+	if fn == nil {
+		fn = DefaultNameFunc
+	}
+	std.nameFunc = fn
+}
+
+// normalizePrefix transforms the pfx to uppercase and removes trailing _s, this
+// allows many different uses without producing weird results.
+func normalizePrefix(pfx string) string {
+	if pfx == "" {
+		return ""
+	}
+	return strings.TrimSuffix(strings.ToUpper(pfx), "_") + "_"
+}
+
+// envKey cleans up a custom environment variable name, uppercasing it and
+// replacing dashes with underscores.
+func envKey(envName string) string {
+	return strings.ToUpper(strings.ReplaceAll(envName, "-", "_"))
+}
+
+// envNameFor returns the primary environment variable bound to the flag given
+// an already normalized prefix.
+func (e *Envy) envNameFor(pfx string, f *pflag.Flag) string {
+	return e.envNamesFor(pfx, f)[0]
+}
+
+// envNamesFor returns every environment variable bound to the flag in the order
+// they're checked, there is always at least one.
+func (e *Envy) envNamesFor(pfx string, f *pflag.Flag) []string {
+	if val, ok := f.Annotations[AnnotationCustom]; ok {
+		// Envy will panic if duplicate custom overrides are defined, so these
+		// always come from a single call.
+		return val
+	}
+	return []string{e.nameFunc(pfx+modulePrefix(f), f.Name)}
+}
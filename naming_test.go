@@ -0,0 +1,79 @@
+package envy_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/fernferret/envy"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetNameFunc(t *testing.T) {
+	defer envy.SetNameFunc(nil)
+
+	tests := []struct {
+		name     string
+		nameFunc envy.NameFunc
+		flag     string
+		exp      string
+	}{
+		{
+			name:     "test default naming",
+			nameFunc: envy.DefaultNameFunc,
+			flag:     "kube-config",
+			exp:      "FOO_KUBE_CONFIG",
+		},
+		{
+			name:     "test default naming keeps dots",
+			nameFunc: envy.DefaultNameFunc,
+			flag:     "db.host",
+			exp:      "FOO_DB.HOST",
+		},
+		{
+			name:     "test ff naming",
+			nameFunc: envy.FFNameFunc,
+			flag:     "db.host/primary-name",
+			exp:      "FOO_DB_HOST_PRIMARY_NAME",
+		},
+		{
+			name: "test custom naming",
+			nameFunc: func(pfx, flagName string) string {
+				return pfx + "X_" + flagName
+			},
+			flag: "url",
+			exp:  "FOO_X_url",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv(tt.exp, "set")
+			pflag.CommandLine = pflag.NewFlagSet("test", pflag.PanicOnError)
+
+			pflag.String(tt.flag, "", "usage")
+
+			envy.SetNameFunc(tt.nameFunc)
+			envy.Parse("foo")
+
+			flag := pflag.Lookup(tt.flag)
+			assert.Equal(t, "usage ["+tt.exp+" set]", flag.Usage)
+			assert.Equal(t, "set", flag.Value.String())
+		})
+	}
+}
+
+func TestSetNameFuncIgnoresCustom(t *testing.T) {
+	defer envy.SetNameFunc(nil)
+
+	os.Clearenv()
+	pflag.CommandLine = pflag.NewFlagSet("test", pflag.PanicOnError)
+
+	pflag.String("kube-config", "", "usage")
+
+	envy.SetNameFunc(envy.FFNameFunc)
+	envy.SetEnvName("kube-config", "KUBECONFIG")
+	envy.Parse("foo")
+
+	assert.Equal(t, "usage [KUBECONFIG]", pflag.Lookup("kube-config").Usage)
+}
@@ -0,0 +1,112 @@
+package envy
+
+import (
+	"strconv"
+	"sync"
+
+	"github.com/spf13/pflag"
+)
+
+// The name of the flag added by AddNoEnvFlag.
+const NoEnvFlag = "no-env"
+
+// Used to mark the flag added by AddNoEnvFlag.
+const AnnotationNoEnv = "envy_no_env"
+
+// noEnvValue undoes everything envy set in the FlagSet when it's set to true.
+type noEnvValue struct {
+	fs *pflag.FlagSet
+	on bool
+
+	// The way to put back each flag envy set, dropped once used.
+	mu     sync.Mutex
+	priors map[*pflag.Flag]func()
+}
+
+func (v *noEnvValue) Set(val string) error {
+	on, err := strconv.ParseBool(val)
+	if err != nil {
+		return err
+	}
+	v.on = on
+	if on {
+		v.ignoreEnv()
+	}
+	return nil
+}
+
+func (v *noEnvValue) String() string {
+	return strconv.FormatBool(v.on)
+}
+
+func (v *noEnvValue) Type() string {
+	return "bool"
+}
+
+// AddNoEnvFlag defines --no-env on the default pflag.CommandLine, see
+// AddNoEnvFlagOnFlagSet.
+func AddNoEnvFlag() {
+	AddNoEnvFlagOnFlagSet(pflag.CommandLine)
+}
+
+// AddNoEnvFlagOnFlagSet defines --no-env on the given FlagSet, which puts
+// every flag envy set from an environment variable or config file back to how
+// it was, so operators can check how the program behaves with only its
+// defaults and the command line while debugging. Flags given on the command
+// line keep their values. The flag itself is never read from the environment.
+// It must be called before the call to envy.Parse().
+func AddNoEnvFlagOnFlagSet(fs *pflag.FlagSet) {
+	fs.Var(&noEnvValue{fs: fs}, NoEnvFlag, "ignore environment variables and config files")
+	f := fs.Lookup(NoEnvFlag)
+	f.NoOptDefVal = "true"
+	annotate(f, AnnotationDisable, "true")
+	annotate(f, AnnotationNoEnv, "true")
+}
+
+// remember keeps a way to put the flag back before envy first sets it, if the
+// FlagSet has a --no-env flag.
+func (e *Envy) remember(f *pflag.Flag) {
+	nf := e.fs.Lookup(NoEnvFlag)
+	if nf == nil || nf.Annotations[AnnotationNoEnv] == nil {
+		return
+	}
+	v, ok := nf.Value.(*noEnvValue)
+	if !ok {
+		return
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if _, ok := v.priors[f]; ok {
+		return
+	}
+	if v.priors == nil {
+		v.priors = map[*pflag.Flag]func(){}
+	}
+	def := f.DefValue
+	restore := snapshot(f)
+	v.priors[f] = func() {
+		restore()
+		f.DefValue = def
+	}
+}
+
+// ignoreEnv puts back every flag envy set in the FlagSet that wasn't also
+// given on the command line.
+func (v *noEnvValue) ignoreEnv() {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	visitAll(v.fs, func(f *pflag.Flag) {
+		restore, ok := v.priors[f]
+		if !ok || f.Changed {
+			return
+		}
+		restore()
+		if m, ok := wrapped[*mergedValue](f); ok {
+			m.env = nil
+		}
+		delete(v.priors, f)
+		delete(f.Annotations, AnnotationSource)
+		delete(f.Annotations, AnnotationFile)
+	})
+}
@@ -0,0 +1,60 @@
+package envy_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/fernferret/envy"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAddNoEnvFlag(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(`{"name": "file"}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		args    []string
+		url     string
+		workers int
+		tags    []string
+		file    string
+	}{
+		{"env", nil, "http://example.com", 2, []string{"a", "b"}, "file"},
+		{"no env", []string{"--no-env"}, "http://localhost", 4, []string{"x"}, "default"},
+		{"cli wins", []string{"--workers=8", "--no-env", "--tags=c"}, "http://localhost", 8, []string{"c"}, "default"},
+		{"off", []string{"--no-env=false"}, "http://example.com", 2, []string{"a", "b"}, "file"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+			url := fs.String("url", "http://localhost", "set the url")
+			workers := fs.Int("workers", 4, "number of workers")
+			tags := fs.StringSlice("tags", []string{"x"}, "tags")
+			name := fs.String("name", "default", "name")
+			envy.AddNoEnvFlagOnFlagSet(fs)
+
+			env := envy.MapLookuper{"FOO_URL": "http://example.com", "FOO_WORKERS": "2", "FOO_TAGS": "a,b", "FOO_NO_ENV": "true"}
+			e := envy.New(envy.WithFlagSet(fs), envy.WithPrefix("FOO"), envy.WithLookuper(env),
+				envy.WithEnvAsDefault(true), envy.WithJSONFile(path))
+			e.MergeSlice("tags", envy.EnvThenFlags)
+			assert.NoError(t, e.ParseE())
+			if err := fs.Parse(tt.args); err != nil {
+				t.Fatal(err)
+			}
+
+			assert.Equal(t, tt.url, *url)
+			assert.Equal(t, tt.workers, *workers)
+			assert.Equal(t, tt.tags, *tags)
+			assert.Equal(t, tt.file, *name)
+			if tt.url == "http://localhost" {
+				assert.Equal(t, "http://localhost", fs.Lookup("url").DefValue)
+				assert.Equal(t, envy.FromDefault, envy.SourcesFlagSet(fs)[3].Origin)
+			}
+		})
+	}
+}
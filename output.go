@@ -0,0 +1,25 @@
+package envy
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+// Where envy writes its warnings, see SetOutput.
+var output io.Writer = os.Stderr
+
+// SetOutput sets where envy writes warnings, like frozen flags being changed or
+// drift from a baseline. It defaults to stderr, passing nil discards them so
+// libraries embedding envy don't write to a stream the program doesn't own.
+func SetOutput(w io.Writer) {
+	if w == nil {
+		w = io.Discard
+	}
+	output = w
+}
+
+// logf writes a single warning line to the configured output.
+func logf(format string, args ...interface{}) {
+	fmt.Fprintf(output, "envy: "+format+"\n", args...)
+}
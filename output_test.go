@@ -0,0 +1,34 @@
+package envy_test
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/fernferret/envy"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetOutput(t *testing.T) {
+	defer envy.SetOutput(os.Stderr)
+	defer envy.SetFreeze(envy.FreezeOff)
+
+	os.Clearenv()
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	fs.String("url", "http://localhost", "set the url")
+	envy.ParseFlagSet("FOO", fs)
+	envy.SetFreeze(envy.FreezeLog)
+	assert.NoError(t, envy.FinalizeFlagSet(fs))
+
+	buf := &bytes.Buffer{}
+	envy.SetOutput(buf)
+	assert.Error(t, fs.Set("url", "http://other"))
+	assert.Equal(t, "envy: flag values are frozen: --url can't be changed after Finalize\n", buf.String())
+
+	// Nil discards everything
+	buf.Reset()
+	envy.SetOutput(nil)
+	assert.Error(t, fs.Set("url", "http://other"))
+	assert.Empty(t, buf.String())
+}
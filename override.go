@@ -0,0 +1,79 @@
+package envy
+
+import (
+	"sort"
+
+	"github.com/spf13/pflag"
+)
+
+// Override sets flags in the default pflag.CommandLine for the length of a
+// test, see OverrideOnFlagSet.
+func Override(values map[string]string) func() {
+	return OverrideOnFlagSet(values, pflag.CommandLine)
+}
+
+// OverrideOnFlagSet sets the value of each named flag in the given FlagSet
+// directly, bypassing the environment, and returns a function restoring every
+// flag to how it was. It gives tests and REPLs a supported way to tweak the
+// configuration without touching the process environment:
+//
+//	t.Cleanup(envy.Override(map[string]string{"url": "http://test"}))
+//
+// While overridden, a flag reports no environment variable or config file as
+// its source. It panics with a SetError if a flag doesn't exist or rejects
+// its value, after restoring any flags it already changed.
+func OverrideOnFlagSet(values map[string]string, fs *pflag.FlagSet) func() {
+	var restores []func()
+	restore := func() {
+		for i := len(restores) - 1; i >= 0; i-- {
+			restores[i]()
+		}
+	}
+
+	// Flags are set in lexical order so mistakes are reported consistently.
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		f := fs.Lookup(name)
+		if f == nil {
+			restore()
+			panic(&SetError{Flag: name, Err: ErrFlagNotExists})
+		}
+		restores = append(restores, snapshot(f))
+		if err := f.Value.Set(values[name]); err != nil {
+			restore()
+			panic(&SetError{Flag: name, Err: err})
+		}
+		delete(f.Annotations, AnnotationSource)
+		delete(f.Annotations, AnnotationFile)
+	}
+	return restore
+}
+
+// snapshot returns a function putting the flag's value and sources back.
+func snapshot(f *pflag.Flag) func() {
+	val := f.Value.String()
+	var slice []string
+	if s, ok := valueOf(f).(pflag.SliceValue); ok {
+		slice = s.GetSlice()
+	}
+	src, fromEnv := f.Annotations[AnnotationSource]
+	file, fromFile := f.Annotations[AnnotationFile]
+	return func() {
+		// Setting a slice appends to it, so replace it whole.
+		if s, ok := valueOf(f).(pflag.SliceValue); ok {
+			s.Replace(slice)
+		} else {
+			f.Value.Set(val)
+		}
+		if fromEnv {
+			annotate(f, AnnotationSource, src...)
+		}
+		if fromFile {
+			annotate(f, AnnotationFile, file...)
+		}
+	}
+}
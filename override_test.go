@@ -0,0 +1,45 @@
+package envy_test
+
+import (
+	"testing"
+
+	"github.com/fernferret/envy"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOverride(t *testing.T) {
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	url := fs.String("url", "http://localhost", "set the url")
+	tags := fs.StringSlice("tags", []string{"a"}, "tags")
+	workers := fs.Int("workers", 4, "number of workers")
+	env := envy.MapLookuper{"FOO_URL": "http://example.com", "FOO_TAGS": "b,c"}
+	assert.NoError(t, envy.New(envy.WithFlagSet(fs), envy.WithPrefix("FOO"), envy.WithLookuper(env)).ParseE())
+
+	restore := envy.OverrideOnFlagSet(map[string]string{"url": "http://test", "tags": "d", "workers": "1"}, fs)
+	assert.Equal(t, "http://test", *url)
+	assert.Equal(t, []string{"d"}, *tags)
+	assert.Equal(t, 1, *workers)
+	assert.Equal(t, envy.FromOther, envy.SourcesFlagSet(fs)[1].Origin)
+
+	restore()
+	assert.Equal(t, "http://example.com", *url)
+	assert.Equal(t, []string{"b", "c"}, *tags)
+	assert.Equal(t, 4, *workers)
+	assert.Equal(t, envy.FromEnv, envy.SourcesFlagSet(fs)[1].Origin)
+}
+
+func TestOverrideInvalid(t *testing.T) {
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	url := fs.String("url", "http://localhost", "set the url")
+	fs.Int("workers", 4, "number of workers")
+
+	assert.PanicsWithError(t, `--workers: strconv.ParseInt: parsing "many": invalid syntax`, func() {
+		envy.OverrideOnFlagSet(map[string]string{"url": "http://test", "workers": "many"}, fs)
+	})
+	assert.Equal(t, "http://localhost", *url)
+
+	assert.PanicsWithError(t, "--missing: flag does not exist", func() {
+		envy.OverrideOnFlagSet(map[string]string{"missing": "x"}, fs)
+	})
+}
@@ -0,0 +1,105 @@
+package envy
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/spf13/pflag"
+)
+
+var ErrEnvCollision = errors.New("environment variable is read by flags in more than one flag set")
+
+// CollisionError is the Err of a SetError from ParseAll for a variable read by
+// flags in different FlagSets. It matches ErrEnvCollision with errors.Is.
+type CollisionError struct {
+	EnvName string
+	Flag    string
+
+	// The flag in an earlier FlagSet that also reads EnvName.
+	Other string
+}
+
+func (e *CollisionError) Error() string {
+	return fmt.Sprintf("%v, also --%s", ErrEnvCollision, e.Other)
+}
+
+func (e *CollisionError) Unwrap() error {
+	return ErrEnvCollision
+}
+
+// ParseAll binds several FlagSets under one prefix, see ParseAllE. Like Parse,
+// it panics on the first problem.
+func ParseAll(pfx string, fss ...*pflag.FlagSet) {
+	std.report(parseAll(std.forEach(pfx, fss)))
+}
+
+// ParseAllE binds several FlagSets under one prefix, for programs composed of
+// subsystems that each expose their own FlagSet. Before anything is bound it
+// checks that no environment variable would be read by flags in two different
+// FlagSets, which would silently set both, and returns a ParseErrors naming
+// every collision if there are any. Otherwise it works like calling
+// ParseFlagSetE on each FlagSet.
+func ParseAllE(pfx string, fss ...*pflag.FlagSet) error {
+	if errs := parseAll(std.forEach(pfx, fss)); len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// forEach returns a copy of the Envy for each FlagSet.
+func (e *Envy) forEach(pfx string, fss []*pflag.FlagSet) []*Envy {
+	envs := make([]*Envy, len(fss))
+	for i, fs := range fss {
+		envs[i] = e.on(pfx, fs)
+	}
+	return envs
+}
+
+// parseAll prepares every FlagSet, checks for collisions and only then binds.
+func parseAll(envs []*Envy) ParseErrors {
+	var errs ParseErrors
+	for _, e := range envs {
+		errs = append(errs, e.prepare()...)
+	}
+	errs = append(errs, collisions(envs)...)
+	if len(errs) > 0 {
+		return errs
+	}
+	for _, e := range envs {
+		errs = append(errs, e.bindAll()...)
+	}
+	return errs
+}
+
+// collisions finds environment variables read by flags in different FlagSets.
+// A flag shared between FlagSets with AddFlagSet isn't a collision.
+func collisions(envs []*Envy) ParseErrors {
+	type owner struct {
+		set  int
+		flag *pflag.Flag
+	}
+	owners := map[string]owner{}
+
+	var errs ParseErrors
+	for i, e := range envs {
+		visitAll(e.fs, func(f *pflag.Flag) {
+			if _, ok := f.Annotations[AnnotationDisable]; ok {
+				return
+			}
+			for _, envName := range e.envNamesFor(e.prefix, f) {
+				o, ok := owners[envName]
+				switch {
+				case !ok:
+					owners[envName] = owner{set: i, flag: f}
+				case o.set != i && o.flag != f:
+					errs = append(errs, &SetError{
+						Flag:    f.Name,
+						EnvName: envName,
+						Err:     &CollisionError{EnvName: envName, Flag: f.Name, Other: o.flag.Name},
+					})
+				}
+			}
+		})
+	}
+	return errs
+}
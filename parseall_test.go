@@ -0,0 +1,59 @@
+package envy_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/fernferret/envy"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseAll(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("APP_HTTP_ADDR", ":8080")
+	os.Setenv("APP_GRPC_ADDR", ":9090")
+
+	httpFS := pflag.NewFlagSet("http", pflag.ContinueOnError)
+	httpAddr := httpFS.String("http-addr", "", "http address")
+	grpcFS := pflag.NewFlagSet("grpc", pflag.ContinueOnError)
+	grpcAddr := grpcFS.String("grpc-addr", "", "grpc address")
+
+	// Shared flags aren't collisions
+	shared := pflag.NewFlagSet("shared", pflag.ContinueOnError)
+	shared.Bool("verbose", false, "verbose output")
+	httpFS.AddFlagSet(shared)
+	grpcFS.AddFlagSet(shared)
+
+	envy.ParseAll("APP", httpFS, grpcFS)
+	assert.Equal(t, ":8080", *httpAddr)
+	assert.Equal(t, ":9090", *grpcAddr)
+}
+
+func TestParseAllCollision(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("APP_ADDR", ":8080")
+
+	httpFS := pflag.NewFlagSet("http", pflag.ContinueOnError)
+	httpAddr := httpFS.String("addr", "", "http address")
+	grpcFS := pflag.NewFlagSet("grpc", pflag.ContinueOnError)
+	grpcFS.String("addr", "", "grpc address")
+	grpcFS.String("listen", "", "grpc listen address")
+	envy.SetEnvNameOnFlagSet("listen", "APP_ADDR", grpcFS)
+	adminFS := pflag.NewFlagSet("admin", pflag.ContinueOnError)
+	adminFS.String("addr", "", "admin address")
+	envy.DisableOnFlagSet("addr", adminFS)
+
+	err := envy.ParseAllE("APP", httpFS, grpcFS, adminFS)
+	assert.ErrorIs(t, err, envy.ErrEnvCollision)
+	assert.EqualError(t, err, `--addr from APP_ADDR: environment variable is read by flags in more than one flag set, also --addr
+--listen from APP_ADDR: environment variable is read by flags in more than one flag set, also --addr`)
+	var collision *envy.CollisionError
+	if assert.ErrorAs(t, err, &collision) {
+		assert.Equal(t, envy.CollisionError{EnvName: "APP_ADDR", Flag: "addr", Other: "addr"}, *collision)
+	}
+
+	// Nothing is bound when there are collisions
+	assert.Empty(t, *httpAddr)
+	assert.Panics(t, func() { envy.ParseAll("APP", httpFS, grpcFS) })
+}
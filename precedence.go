@@ -0,0 +1,67 @@
+package envy
+
+import (
+	"errors"
+	"fmt"
+)
+
+var ErrInvalidPrecedence = errors.New("precedence may only list FromFile, FromEnv and FromFlag once each, with FromFlag last")
+
+// WithPrecedence chooses which sources override which, listed from lowest to
+// highest priority, so the default is:
+//
+//	envy.WithPrecedence(envy.FromFile, envy.FromEnv, envy.FromFlag)
+//
+// Swapping FromFile and FromEnv makes config files override environment
+// variables. The command line is parsed after envy by pflag.Parse and always
+// wins, so FromFlag may be left out but can only be listed last. Defaults are
+// always the lowest. An invalid order is reported by Parse.
+func WithPrecedence(origins ...Origin) Option {
+	return func(e *Envy) {
+		if err := validatePrecedence(origins); err != nil {
+			e.optErrs = append(e.optErrs, &SetError{Err: err})
+			return
+		}
+		e.precedence = origins
+	}
+}
+
+// SetPrecedence chooses which sources override which for the default Envy,
+// see WithPrecedence. It must be called before the call to envy.Parse().
+func SetPrecedence(origins ...Origin) error {
+	if err := validatePrecedence(origins); err != nil {
+		return err
+	}
+	std.precedence = origins
+	return nil
+}
+
+// validatePrecedence checks an order given to WithPrecedence.
+func validatePrecedence(origins []Origin) error {
+	seen := map[Origin]bool{}
+	for i, o := range origins {
+		switch {
+		case o != FromFile && o != FromEnv && o != FromFlag:
+			return fmt.Errorf("%w, got %s", ErrInvalidPrecedence, o)
+		case seen[o]:
+			return fmt.Errorf("%w, got %s twice", ErrInvalidPrecedence, o)
+		case o == FromFlag && i != len(origins)-1:
+			return ErrInvalidPrecedence
+		}
+		seen[o] = true
+	}
+	return nil
+}
+
+// filesFirst reports whether config files override environment variables.
+func (e *Envy) filesFirst() bool {
+	for _, o := range e.precedence {
+		switch o {
+		case FromEnv:
+			return true
+		case FromFile:
+			return false
+		}
+	}
+	return false
+}
@@ -0,0 +1,61 @@
+package envy_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/fernferret/envy"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithPrecedence(t *testing.T) {
+	tests := []struct {
+		name       string
+		precedence []envy.Origin
+		want       string
+		winner     string
+	}{
+		{"default", nil, "from-env", "env MYAPP_NAME"},
+		{"env over file", []envy.Origin{envy.FromFile, envy.FromEnv, envy.FromFlag}, "from-env", "env MYAPP_NAME"},
+		{"file over env", []envy.Origin{envy.FromEnv, envy.FromFile, envy.FromFlag}, "from-file", "file "},
+		{"no flag", []envy.Origin{envy.FromEnv, envy.FromFile}, "from-file", "file "},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv("MYAPP_NAME", "from-env")
+			path := writeFile(t, "config.yaml", "name: from-file\n")
+
+			fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+			name := fs.String("name", "", "name")
+			e := envy.New(envy.WithPrefix("MYAPP"), envy.WithFlagSet(fs), envy.WithYAMLFile(path), envy.WithPrecedence(tt.precedence...))
+			assert.NoError(t, e.ParseE())
+			assert.Equal(t, tt.want, *name)
+
+			x := envy.ExplainFlagSet("name", fs)
+			assert.Contains(t, x.Layers[x.Winner].Source, tt.winner)
+			assert.Equal(t, tt.want, envy.ResolveIntoFlagSet("MYAPP", fs)["name"])
+		})
+	}
+}
+
+func TestWithPrecedenceInvalid(t *testing.T) {
+	tests := []struct {
+		name       string
+		precedence []envy.Origin
+	}{
+		{"flag not last", []envy.Origin{envy.FromFlag, envy.FromEnv}},
+		{"duplicate", []envy.Origin{envy.FromEnv, envy.FromEnv}},
+		{"unknown", []envy.Origin{envy.FromDefault, envy.FromEnv}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+			e := envy.New(envy.WithFlagSet(fs), envy.WithPrecedence(tt.precedence...))
+			assert.ErrorIs(t, e.ParseE(), envy.ErrInvalidPrecedence)
+			assert.ErrorIs(t, envy.SetPrecedence(tt.precedence...), envy.ErrInvalidPrecedence)
+		})
+	}
+	assert.NoError(t, envy.SetPrecedence())
+}
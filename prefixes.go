@@ -0,0 +1,79 @@
+package envy
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/spf13/pflag"
+)
+
+var ErrPrefixConflict = errors.New("flag is already bound with a different prefix")
+
+// SetLayeredPrefixes controls what happens when a flag already bound under one
+// prefix is parsed again under another, like a FlagSet shared between two
+// programs. By default that's a mistake reported by Parse, leaving the flag as
+// it was. When on, each later prefix becomes an alias checked after the
+// earlier ones, so the first prefix keeps priority and names the flag in
+// usage. It must be called before the second call to envy.Parse().
+func SetLayeredPrefixes(on bool) {
+	std.layered = on
+}
+
+// WithLayeredPrefixes works like SetLayeredPrefixes for this Envy only.
+func WithLayeredPrefixes(on bool) Option {
+	return func(e *Envy) {
+		e.layered = on
+	}
+}
+
+// bindPrefix records the prefix the flag is bound with, checking it against
+// any earlier Parse. Parsing again with the same prefix, or one that yields
+// the same variables, like for a flag with SetEnvName, is always fine.
+func (e *Envy) bindPrefix(f *pflag.Flag) *SetError {
+	pfxs, ok := f.Annotations[AnnotationBound]
+	if !ok {
+		annotate(f, AnnotationBound, e.prefix)
+		return nil
+	}
+	want := e.envNamesFor(e.prefix, f)
+	for _, pfx := range pfxs {
+		if equalNames(e.envNamesFor(pfx, f), want) {
+			return nil
+		}
+	}
+	if !e.layered {
+		err := fmt.Errorf("%w, reads %s", ErrPrefixConflict, e.envNameFor(pfxs[0], f))
+		return &SetError{Flag: f.Name, EnvName: want[0], Err: err}
+	}
+	annotate(f, AnnotationBound, append(pfxs, e.prefix)...)
+	return nil
+}
+
+// boundNames returns every environment variable bound to the flag under each
+// of its prefixes, in priority order.
+func (e *Envy) boundNames(f *pflag.Flag) []string {
+	var names []string
+	seen := map[string]bool{}
+	for _, pfx := range f.Annotations[AnnotationBound] {
+		for _, name := range e.envNamesFor(pfx, f) {
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
+
+// equalNames reports whether both lists hold the same names in the same order.
+func equalNames(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
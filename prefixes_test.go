@@ -0,0 +1,92 @@
+package envy_test
+
+import (
+	"testing"
+
+	"github.com/fernferret/envy"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsePrefixConflict(t *testing.T) {
+	tests := []struct {
+		name     string
+		first    string
+		second   string
+		expected string
+		errMsg   string
+	}{
+		{"old then new", "OLD", "NEW", "from-old", "--url from NEW_URL: flag is already bound with a different prefix, reads OLD_URL"},
+		{"new then old", "NEW", "OLD", "from-new", "--url from OLD_URL: flag is already bound with a different prefix, reads NEW_URL"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			env := envy.MapLookuper{"OLD_URL": "from-old", "NEW_URL": "from-new"}
+			fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+			fs.String("url", "http://localhost", "set the url")
+			fs.String("kube-config", "", "kube config")
+			envy.SetEnvNameOnFlagSet("kube-config", "KUBECONFIG", fs)
+
+			first := envy.New(envy.WithFlagSet(fs), envy.WithPrefix(test.first), envy.WithLookuper(env))
+			assert.NoError(t, first.ParseE())
+			usage := fs.Lookup("url").Usage
+
+			second := envy.New(envy.WithFlagSet(fs), envy.WithPrefix(test.second), envy.WithLookuper(env))
+			err := second.ParseE()
+			assert.ErrorIs(t, err, envy.ErrPrefixConflict)
+			assert.EqualError(t, err, test.errMsg)
+			assert.Equal(t, test.expected, fs.Lookup("url").Value.String())
+			assert.Equal(t, usage, fs.Lookup("url").Usage)
+
+			// Parsing again with the same prefix is fine.
+			assert.NoError(t, first.ParseE())
+			assert.Equal(t, usage, fs.Lookup("url").Usage)
+		})
+	}
+}
+
+func TestParseLayeredPrefixes(t *testing.T) {
+	tests := []struct {
+		name     string
+		first    string
+		second   string
+		env      envy.MapLookuper
+		expected string
+		usage    string
+		envNames []string
+	}{
+		{
+			"first wins", "OLD", "NEW", envy.MapLookuper{"OLD_URL": "from-old", "NEW_URL": "from-new"},
+			"from-old", "set the url [OLD_URL from-old]", []string{"OLD_URL", "NEW_URL"},
+		},
+		{
+			"first wins reversed", "NEW", "OLD", envy.MapLookuper{"OLD_URL": "from-old", "NEW_URL": "from-new"},
+			"from-new", "set the url [NEW_URL from-new]", []string{"NEW_URL", "OLD_URL"},
+		},
+		{
+			"alias fills in", "OLD", "NEW", envy.MapLookuper{"NEW_URL": "from-new"},
+			"from-new", "set the url [NEW_URL from-new]", []string{"OLD_URL", "NEW_URL"},
+		},
+		{
+			"alias fills in reversed", "NEW", "OLD", envy.MapLookuper{"NEW_URL": "from-new"},
+			"from-new", "set the url [NEW_URL from-new]", []string{"NEW_URL", "OLD_URL"},
+		},
+		{
+			"unset", "OLD", "NEW", envy.MapLookuper{},
+			"http://localhost", "set the url [OLD_URL]", []string{"OLD_URL", "NEW_URL"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+			fs.String("url", "http://localhost", "set the url")
+			for _, pfx := range []string{test.first, test.second} {
+				e := envy.New(envy.WithFlagSet(fs), envy.WithPrefix(pfx), envy.WithLookuper(test.env), envy.WithLayeredPrefixes(true))
+				assert.NoError(t, e.ParseE())
+			}
+			assert.Equal(t, test.expected, fs.Lookup("url").Value.String())
+			assert.Equal(t, test.usage, fs.Lookup("url").Usage)
+			assert.Equal(t, test.envNames, envy.SchemaFlagSet(fs)[0].EnvNames)
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package envy
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+var ErrProcfsUnsupported = errors.New("reading another process's environment is only supported on Linux")
+
+// ParseEnvironOf binds the default pflag.CommandLine to the environment of
+// another process, see ParseEnvironOfFlagSet.
+func ParseEnvironOf(pfx string, pid int) error {
+	return ParseEnvironOfFlagSet(pfx, pid, pflag.CommandLine)
+}
+
+// ParseEnvironOfFlagSet works like ParseFlagSetE but reads variables from the
+// environment the process pid was started with rather than this one's. Given
+// the same flag definitions, the FlagSet then shows what that process's
+// configuration resolved to, so debugging tools can answer "why is that daemon
+// behaving this way" with Explain and Sources. It only works on Linux, reading
+// /proc/<pid>/environ, which needs the same permissions as ptrace.
+func ParseEnvironOfFlagSet(pfx string, pid int, fs *pflag.FlagSet) error {
+	return std.on(pfx, fs).ParseEnvironOf(pid)
+}
+
+// ParseEnvironOf binds the FlagSet to the environment of another process, see
+// ParseEnvironOfFlagSet.
+func (e *Envy) ParseEnvironOf(pid int) error {
+	env, err := EnvironOf(pid)
+	if err != nil {
+		return err
+	}
+	c := *e
+	c.lookuper = env
+	return c.ParseE()
+}
+
+// parseEnviron splits the NUL separated KEY=VALUE pairs of a procfs environ
+// file. The first of any duplicates wins, like it does for getenv and Go's
+// os.Getenv, so the result matches what the process itself sees.
+func parseEnviron(data []byte) MapLookuper {
+	env := MapLookuper{}
+	for _, kv := range bytes.Split(data, []byte{0}) {
+		key, val, ok := strings.Cut(string(kv), "=")
+		if !ok || key == "" {
+			continue
+		}
+		if _, dup := env[key]; !dup {
+			env[key] = val
+		}
+	}
+	return env
+}
@@ -0,0 +1,18 @@
+//go:build linux
+
+package envy
+
+import (
+	"os"
+	"strconv"
+)
+
+// EnvironOf returns the environment the process pid was started with. Changes
+// the process made to its own environment afterwards aren't visible.
+func EnvironOf(pid int) (MapLookuper, error) {
+	data, err := os.ReadFile("/proc/" + strconv.Itoa(pid) + "/environ")
+	if err != nil {
+		return nil, err
+	}
+	return parseEnviron(data), nil
+}
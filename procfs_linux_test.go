@@ -0,0 +1,65 @@
+package envy_test
+
+import (
+	"os"
+	"os/exec"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/fernferret/envy"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseEnvironOf(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("MYAPP_NAME", "parent")
+
+	cmd := exec.Command("/bin/sleep", "10")
+	cmd.Env = []string{"MYAPP_NAME=child", "MYAPP_COUNT=3", "MYAPP_URL=a=b"}
+	if err := cmd.Start(); err != nil {
+		t.Skip(err)
+	}
+	defer cmd.Wait()
+	defer cmd.Process.Kill()
+
+	env, err := envy.EnvironOf(cmd.Process.Pid)
+	assert.NoError(t, err)
+	assert.Equal(t, envy.MapLookuper{"MYAPP_NAME": "child", "MYAPP_COUNT": "3", "MYAPP_URL": "a=b"}, env)
+
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	name := fs.String("name", "", "name")
+	count := fs.Int("count", 1, "count")
+	assert.NoError(t, envy.ParseEnvironOfFlagSet("MYAPP", cmd.Process.Pid, fs))
+	assert.Equal(t, "child", *name)
+	assert.Equal(t, 3, *count)
+	assert.Equal(t, "env MYAPP_NAME", envy.ExplainFlagSet("name", fs).Layers[1].Source)
+	assert.Equal(t, "child", envy.ExplainFlagSet("name", fs).Layers[1].Value)
+
+	_, err = envy.EnvironOf(-1)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestEnvironOfDuplicates(t *testing.T) {
+	// os/exec drops duplicates itself, so start the child directly.
+	pid, err := syscall.ForkExec("/bin/sleep", []string{"sleep", "10"}, &syscall.ProcAttr{
+		Env: []string{"MYAPP_NAME=first", "MYAPP_NAME=second"},
+	})
+	if err != nil {
+		t.Skip(err)
+	}
+	defer syscall.Wait4(pid, nil, 0, nil)
+	defer syscall.Kill(pid, syscall.SIGKILL)
+
+	// Until the exec finishes the child still has our environment.
+	var env envy.MapLookuper
+	for i := 0; i < 100; i++ {
+		if env, err = envy.EnvironOf(pid); err != nil || env["MYAPP_NAME"] != "" {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.NoError(t, err)
+	assert.Equal(t, envy.MapLookuper{"MYAPP_NAME": "first"}, env)
+}
@@ -0,0 +1,9 @@
+//go:build !linux
+
+package envy
+
+// EnvironOf returns the environment the process pid was started with, which
+// is only supported on Linux.
+func EnvironOf(pid int) (MapLookuper, error) {
+	return nil, ErrProcfsUnsupported
+}
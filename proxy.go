@@ -0,0 +1,142 @@
+package envy
+
+import (
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+// ProxyConfig holds the values of the flags defined by ProxyFlags.
+type ProxyConfig struct {
+	HTTPProxy  string
+	HTTPSProxy string
+	NoProxy    string
+}
+
+// ProxyFlags defines --http-proxy, --https-proxy and --no-proxy on the default
+// pflag.CommandLine, see ProxyFlagsOnFlagSet.
+func ProxyFlags() *ProxyConfig {
+	return ProxyFlagsOnFlagSet(pflag.CommandLine)
+}
+
+// ProxyFlagsOnFlagSet defines --http-proxy, --https-proxy and --no-proxy on
+// the given FlagSet. Rather than using the envy prefix they're bound to the
+// conventional HTTP_PROXY, HTTPS_PROXY and NO_PROXY variables, falling back to
+// the lowercase spellings like curl and Go's own http.ProxyFromEnvironment.
+func ProxyFlagsOnFlagSet(fs *pflag.FlagSet) *ProxyConfig {
+	p := &ProxyConfig{}
+	fs.StringVar(&p.HTTPProxy, "http-proxy", "", "proxy to use for http requests")
+	fs.StringVar(&p.HTTPSProxy, "https-proxy", "", "proxy to use for https requests")
+	fs.StringVar(&p.NoProxy, "no-proxy", "", "comma separated hosts, domains and CIDRs that bypass the proxy")
+
+	annotate(fs.Lookup("http-proxy"), AnnotationCustom, "HTTP_PROXY", "http_proxy")
+	annotate(fs.Lookup("https-proxy"), AnnotationCustom, "HTTPS_PROXY", "https_proxy")
+	annotate(fs.Lookup("no-proxy"), AnnotationCustom, "NO_PROXY", "no_proxy")
+	return p
+}
+
+// Transport returns a clone of http.DefaultTransport using ProxyFunc.
+func (p *ProxyConfig) Transport() *http.Transport {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.Proxy = p.ProxyFunc()
+	return t
+}
+
+// ProxyFunc returns a function suitable for http.Transport.Proxy following the
+// same rules as golang.org/x/net/http/httpproxy: requests to localhost and
+// loopback addresses are never proxied, proxies without a scheme are assumed
+// to be http and NO_PROXY entries may be "*", IPs, CIDRs or domains (matching
+// subdomains too, or only subdomains with a leading "."), each with an
+// optional port.
+func (p *ProxyConfig) ProxyFunc() func(*http.Request) (*url.URL, error) {
+	return func(req *http.Request) (*url.URL, error) {
+		proxy := p.HTTPProxy
+		if req.URL.Scheme == "https" {
+			proxy = p.HTTPSProxy
+		}
+		if proxy == "" || !p.useProxy(canonicalAddr(req.URL)) {
+			return nil, nil
+		}
+
+		u, err := url.Parse(proxy)
+		if err != nil || u.Host == "" {
+			// Allow "proxy:3128" and friends, same as httpproxy.
+			if u, err = url.Parse("http://" + proxy); err != nil {
+				return nil, err
+			}
+		}
+		return u, nil
+	}
+}
+
+// useProxy reports whether requests to the host:port should be proxied.
+func (p *ProxyConfig) useProxy(addr string) bool {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return false
+	}
+	ip := net.ParseIP(host)
+	if ip != nil && ip.IsLoopback() {
+		return false
+	}
+
+	for _, entry := range strings.FieldsFunc(strings.ToLower(p.NoProxy), func(r rune) bool {
+		return r == ',' || r == ' '
+	}) {
+		if entry == "*" {
+			return false
+		}
+		if _, cidr, err := net.ParseCIDR(entry); err == nil {
+			if ip != nil && cidr.Contains(ip) {
+				return false
+			}
+			continue
+		}
+
+		entryHost, entryPort := entry, ""
+		if h, pt, err := net.SplitHostPort(entry); err == nil {
+			entryHost, entryPort = h, pt
+		}
+		if entryPort != "" && entryPort != port {
+			continue
+		}
+
+		if entryIP := net.ParseIP(entryHost); entryIP != nil {
+			if ip != nil && entryIP.Equal(ip) {
+				return false
+			}
+			continue
+		}
+
+		host := strings.ToLower(host)
+		if strings.HasPrefix(entryHost, ".") {
+			if strings.HasSuffix(host, entryHost) {
+				return false
+			}
+			continue
+		}
+		if host == entryHost || strings.HasSuffix(host, "."+entryHost) {
+			return false
+		}
+	}
+	return true
+}
+
+// canonicalAddr returns the host:port of the URL, adding the default port for
+// the scheme if needed.
+func canonicalAddr(u *url.URL) string {
+	port := u.Port()
+	if port == "" {
+		port = "80"
+		if u.Scheme == "https" {
+			port = "443"
+		}
+	}
+	return net.JoinHostPort(u.Hostname(), port)
+}
@@ -0,0 +1,87 @@
+package envy_test
+
+import (
+	"net/http"
+	"os"
+	"testing"
+
+	"github.com/fernferret/envy"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProxyFlags(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("http_proxy", "http://lower:3128")
+	os.Setenv("HTTPS_PROXY", "http://upper:3128")
+	os.Setenv("https_proxy", "http://ignored:3128")
+	pflag.CommandLine = pflag.NewFlagSet("test", pflag.PanicOnError)
+
+	cfg := envy.ProxyFlags()
+	envy.Parse("FOO")
+
+	assert.Equal(t, "http://lower:3128", cfg.HTTPProxy)
+	assert.Equal(t, "http://upper:3128", cfg.HTTPSProxy)
+	assert.Equal(t, "", cfg.NoProxy)
+	assert.Equal(t, "proxy to use for http requests [http_proxy http://lower:3128]", pflag.Lookup("http-proxy").Usage)
+	assert.Equal(t, "comma separated hosts, domains and CIDRs that bypass the proxy [NO_PROXY]", pflag.Lookup("no-proxy").Usage)
+}
+
+func TestProxyFunc(t *testing.T) {
+	cfg := &envy.ProxyConfig{
+		HTTPProxy:  "proxy:3128",
+		HTTPSProxy: "https://secure-proxy:3129",
+		NoProxy:    "internal.example.com, .corp, 10.0.0.0/8,192.168.1.1,svc:8443",
+	}
+	tests := []struct {
+		url string
+		exp string
+	}{
+		{url: "http://example.com", exp: "http://proxy:3128"},
+		{url: "https://example.com", exp: "https://secure-proxy:3129"},
+		{url: "http://localhost:8080"},
+		{url: "http://127.0.0.1"},
+		{url: "http://[::1]"},
+		{url: "http://internal.example.com"},
+		{url: "http://api.internal.example.com"},
+		{url: "http://notinternal.example.com", exp: "http://proxy:3128"},
+		{url: "http://build.corp"},
+		{url: "http://corp", exp: "http://proxy:3128"},
+		{url: "http://10.1.2.3"},
+		{url: "http://192.168.1.1"},
+		{url: "http://192.168.1.2", exp: "http://proxy:3128"},
+		{url: "https://svc:8443"},
+		{url: "https://svc", exp: "https://secure-proxy:3129"},
+	}
+	proxy := cfg.ProxyFunc()
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			req, _ := http.NewRequest("GET", tt.url, nil)
+			u, err := proxy(req)
+			assert.NoError(t, err)
+			if tt.exp == "" {
+				assert.Nil(t, u)
+			} else {
+				assert.Equal(t, tt.exp, u.String())
+			}
+		})
+	}
+}
+
+func TestProxyFuncWildcard(t *testing.T) {
+	cfg := &envy.ProxyConfig{HTTPProxy: "http://proxy:3128", NoProxy: "*"}
+	req, _ := http.NewRequest("GET", "http://example.com", nil)
+
+	u, err := cfg.Transport().Proxy(req)
+	assert.NoError(t, err)
+	assert.Nil(t, u)
+}
+
+func TestProxyFuncInvalid(t *testing.T) {
+	cfg := &envy.ProxyConfig{HTTPProxy: "proxy:%zz"}
+	req, _ := http.NewRequest("GET", "http://example.com", nil)
+
+	u, err := cfg.ProxyFunc()(req)
+	assert.Error(t, err)
+	assert.Nil(t, u)
+}
@@ -0,0 +1,182 @@
+package envy
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+var (
+	ErrInvalidRate    = errors.New("rate must be a number with an optional /s, /m or /h suffix, example: 100/s")
+	ErrInvalidPercent = errors.New("percent must be between 0 and 100 with an optional % suffix, example: 25%")
+)
+
+// Per second multipliers for the rate suffixes.
+var rateUnits = map[string]float64{
+	"":   1,
+	"/s": 1,
+	"/m": 1.0 / 60,
+	"/h": 1.0 / 3600,
+}
+
+// Byte multipliers, longest first so KiB is matched before B.
+var byteUnits = []struct {
+	suffix string
+	mult   float64
+}{
+	{"KiB", 1 << 10}, {"MiB", 1 << 20}, {"GiB", 1 << 30}, {"TiB", 1 << 40},
+	{"KB", 1e3}, {"MB", 1e6}, {"GB", 1e9}, {"TB", 1e12},
+	{"B", 1},
+}
+
+// Units used to display byte rates, largest first.
+var displayByteUnits = []struct {
+	suffix string
+	mult   float64
+}{
+	{"TB", 1e12}, {"GB", 1e9}, {"MB", 1e6}, {"KB", 1e3},
+}
+
+// rateValue holds a rate in events per second.
+type rateValue float64
+
+func (v *rateValue) Set(val string) error {
+	num, perSec, err := splitRate(val)
+	if err != nil {
+		return err
+	}
+	n, err := strconv.ParseFloat(num, 64)
+	if err != nil || n < 0 {
+		return fmt.Errorf("%w: %q", ErrInvalidRate, val)
+	}
+	*v = rateValue(n * perSec)
+	return nil
+}
+
+func (v *rateValue) String() string {
+	return strconv.FormatFloat(float64(*v), 'f', -1, 64) + "/s"
+}
+
+func (v *rateValue) Type() string {
+	return "rate"
+}
+
+// byteRateValue holds a rate in bytes per second.
+type byteRateValue float64
+
+func (v *byteRateValue) Set(val string) error {
+	num, perSec, err := splitRate(val)
+	if err != nil {
+		return err
+	}
+	mult := 1.0
+	for _, unit := range byteUnits {
+		if strings.HasSuffix(num, unit.suffix) {
+			num, mult = strings.TrimSpace(strings.TrimSuffix(num, unit.suffix)), unit.mult
+			break
+		}
+	}
+	n, err := strconv.ParseFloat(num, 64)
+	if err != nil || n < 0 {
+		return fmt.Errorf("%w: %q", ErrInvalidRate, val)
+	}
+	*v = byteRateValue(n * mult * perSec)
+	return nil
+}
+
+func (v *byteRateValue) String() string {
+	n := float64(*v)
+	suffix := "B"
+	for _, unit := range displayByteUnits {
+		if n >= unit.mult {
+			n, suffix = n/unit.mult, unit.suffix
+			break
+		}
+	}
+	return strconv.FormatFloat(n, 'f', -1, 64) + suffix + "/s"
+}
+
+func (v *byteRateValue) Type() string {
+	return "byteRate"
+}
+
+// percentValue holds a percentage as a fraction between 0 and 1.
+type percentValue float64
+
+func (v *percentValue) Set(val string) error {
+	n, err := strconv.ParseFloat(strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(val), "%")), 64)
+	if err != nil || n < 0 || n > 100 || math.IsNaN(n) {
+		return fmt.Errorf("%w: %q", ErrInvalidPercent, val)
+	}
+	*v = percentValue(n / 100)
+	return nil
+}
+
+func (v *percentValue) String() string {
+	return strconv.FormatFloat(float64(*v)*100, 'f', -1, 64) + "%"
+}
+
+func (v *percentValue) Type() string {
+	return "percent"
+}
+
+// splitRate splits the per-time suffix from the value, returning the
+// multiplier needed to turn it into a per second rate.
+func splitRate(val string) (string, float64, error) {
+	val = strings.TrimSpace(val)
+	suffix := ""
+	if i := strings.LastIndex(val, "/"); i >= 0 {
+		val, suffix = val[:i], val[i:]
+	}
+	perSec, ok := rateUnits[suffix]
+	if !ok {
+		return "", 0, fmt.Errorf("%w: unknown unit %q", ErrInvalidRate, suffix)
+	}
+	return strings.TrimSpace(val), perSec, nil
+}
+
+// RateVar defines a rate flag on the default pflag.CommandLine, see
+// RateVarOnFlagSet.
+func RateVar(p *float64, name string, value float64, usage string) {
+	RateVarOnFlagSet(p, name, value, usage, pflag.CommandLine)
+}
+
+// RateVarOnFlagSet defines a flag holding a rate in events per second, like
+// requests per second. Values may use a /s, /m or /h suffix, so 600/m is
+// stored as 10.
+func RateVarOnFlagSet(p *float64, name string, value float64, usage string, fs *pflag.FlagSet) {
+	*p = value
+	fs.Var((*rateValue)(p), name, usage)
+}
+
+// ByteRateVar defines a byte rate flag on the default pflag.CommandLine, see
+// ByteRateVarOnFlagSet.
+func ByteRateVar(p *float64, name string, value float64, usage string) {
+	ByteRateVarOnFlagSet(p, name, value, usage, pflag.CommandLine)
+}
+
+// ByteRateVarOnFlagSet defines a flag holding a rate in bytes per second.
+// Values may use decimal (KB, MB, GB, TB) or binary (KiB, MiB, GiB, TiB) units
+// and a /s, /m or /h suffix, like 10MB/s.
+func ByteRateVarOnFlagSet(p *float64, name string, value float64, usage string, fs *pflag.FlagSet) {
+	*p = value
+	fs.Var((*byteRateValue)(p), name, usage)
+}
+
+// PercentVar defines a percent flag on the default pflag.CommandLine, see
+// PercentVarOnFlagSet.
+func PercentVar(p *float64, name string, value float64, usage string) {
+	PercentVarOnFlagSet(p, name, value, usage, pflag.CommandLine)
+}
+
+// PercentVarOnFlagSet defines a flag holding a percentage between 0 and 100,
+// with or without a % suffix. It's stored as a fraction, so 25% is stored as
+// 0.25, and the default value must be given the same way.
+func PercentVarOnFlagSet(p *float64, name string, value float64, usage string, fs *pflag.FlagSet) {
+	*p = value
+	fs.Var((*percentValue)(p), name, usage)
+}
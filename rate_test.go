@@ -0,0 +1,69 @@
+package envy_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/fernferret/envy"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateVars(t *testing.T) {
+	tests := []struct {
+		name  string
+		flag  string
+		value string
+		exp   float64
+		str   string
+		err   bool
+	}{
+		{name: "test plain rate", flag: "rate", value: "100", exp: 100, str: "100/s"},
+		{name: "test per minute rate", flag: "rate", value: "600/m", exp: 10, str: "10/s"},
+		{name: "test per hour rate", flag: "rate", value: "7200 /h", exp: 2, str: "2/s"},
+		{name: "test unknown rate unit", flag: "rate", value: "10/d", err: true},
+		{name: "test negative rate", flag: "rate", value: "-1", err: true},
+		{name: "test byte rate", flag: "byte-rate", value: "10MB/s", exp: 10e6, str: "10MB/s"},
+		{name: "test binary byte rate", flag: "byte-rate", value: "1KiB", exp: 1024, str: "1.024KB/s"},
+		{name: "test byte rate per minute", flag: "byte-rate", value: "60GB/m", exp: 1e9, str: "1GB/s"},
+		{name: "test plain bytes", flag: "byte-rate", value: "512B/s", exp: 512, str: "512B/s"},
+		{name: "test invalid byte rate", flag: "byte-rate", value: "fast", err: true},
+		{name: "test percent", flag: "percent", value: "25%", exp: 0.25, str: "25%"},
+		{name: "test percent without suffix", flag: "percent", value: "50", exp: 0.5, str: "50%"},
+		{name: "test percent too large", flag: "percent", value: "101%", err: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+
+			var rate, byteRate, percent float64
+			envy.RateVarOnFlagSet(&rate, "rate", 1, "rate", fs)
+			envy.ByteRateVarOnFlagSet(&byteRate, "byte-rate", 1, "byte rate", fs)
+			envy.PercentVarOnFlagSet(&percent, "percent", 1, "percent", fs)
+
+			err := fs.Set(tt.flag, tt.value)
+			if tt.err {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+
+			got := map[string]float64{"rate": rate, "byte-rate": byteRate, "percent": percent}[tt.flag]
+			assert.InDelta(t, tt.exp, got, 1e-9)
+			assert.Equal(t, tt.str, fs.Lookup(tt.flag).Value.String())
+		})
+	}
+}
+
+func TestRateVarEnv(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("FOO_UPLOAD_LIMIT", "5MiB/s")
+	pflag.CommandLine = pflag.NewFlagSet("test", pflag.PanicOnError)
+
+	var limit float64
+	envy.ByteRateVar(&limit, "upload-limit", 1e6, "maximum upload rate")
+	envy.Parse("FOO")
+
+	assert.Equal(t, float64(5<<20), limit)
+	assert.Equal(t, "1MB/s", pflag.Lookup("upload-limit").DefValue)
+}
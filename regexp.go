@@ -0,0 +1,56 @@
+package envy
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/spf13/pflag"
+)
+
+// regexpValue compiles its value into a regexp.Regexp.
+type regexpValue struct {
+	p **regexp.Regexp
+}
+
+func (v *regexpValue) Set(val string) error {
+	if val == "" {
+		*v.p = nil
+		return nil
+	}
+	re, err := regexp.Compile(val)
+	if err != nil {
+		return fmt.Errorf("invalid pattern %q: %w", val, err)
+	}
+	*v.p = re
+	return nil
+}
+
+func (v *regexpValue) String() string {
+	if *v.p == nil {
+		return ""
+	}
+	return (*v.p).String()
+}
+
+func (v *regexpValue) Type() string {
+	return "regexp"
+}
+
+// RegexpVar defines a regular expression flag on the default
+// pflag.CommandLine, see RegexpVarOnFlagSet.
+func RegexpVar(p **regexp.Regexp, name, value, usage string) {
+	RegexpVarOnFlagSet(p, name, value, usage, pflag.CommandLine)
+}
+
+// RegexpVarOnFlagSet defines a flag whose value is compiled as a regular
+// expression. Patterns from the environment are compiled when envy parses the
+// FlagSet, so mistakes are reported against the variable and the offending
+// pattern. An empty value leaves p nil rather than matching everything. It
+// panics if the default doesn't compile.
+func RegexpVarOnFlagSet(p **regexp.Regexp, name, value, usage string, fs *pflag.FlagSet) {
+	v := &regexpValue{p: p}
+	if err := v.Set(value); err != nil {
+		panic(err)
+	}
+	fs.Var(v, name, usage)
+}
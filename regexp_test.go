@@ -0,0 +1,49 @@
+package envy_test
+
+import (
+	"os"
+	"regexp"
+	"testing"
+
+	"github.com/fernferret/envy"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegexpVar(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("FOO_INCLUDE", "^api-[0-9]+$")
+	pflag.CommandLine = pflag.NewFlagSet("test", pflag.PanicOnError)
+
+	var include, exclude *regexp.Regexp
+	envy.RegexpVar(&include, "include", ".*", "names to include")
+	envy.RegexpVar(&exclude, "exclude", "", "names to exclude")
+	envy.Parse("FOO")
+
+	assert.True(t, include.MatchString("api-12"))
+	assert.False(t, include.MatchString("web-12"))
+	assert.Nil(t, exclude)
+	assert.Equal(t, "names to include [FOO_INCLUDE ^api-[0-9]+$]", pflag.Lookup("include").Usage)
+}
+
+func TestRegexpVarInvalidEnv(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("FOO_INCLUDE", "api-(")
+	pflag.CommandLine = pflag.NewFlagSet("test", pflag.PanicOnError)
+
+	var include *regexp.Regexp
+	envy.RegexpVar(&include, "include", "", "names to include")
+
+	defer func() {
+		err, _ := recover().(error)
+		assert.ErrorContains(t, err, `FOO_INCLUDE: invalid pattern "api-("`)
+	}()
+	envy.Parse("FOO")
+}
+
+func TestRegexpVarInvalidDefault(t *testing.T) {
+	var re *regexp.Regexp
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+
+	assert.Panics(t, func() { envy.RegexpVarOnFlagSet(&re, "include", "(", "names", fs) })
+}
@@ -0,0 +1,51 @@
+package envy
+
+import "sync"
+
+// registry is a map safe for use by several goroutines, holding the state envy
+// keeps between calls for each FlagSet, since FlagSets may be parsed by
+// different instances on different goroutines.
+type registry[K comparable, V any] struct {
+	mu sync.Mutex
+	m  map[K]V
+}
+
+// get returns the value stored for the key.
+func (r *registry[K, V]) get(k K) (V, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	v, ok := r.m[k]
+	return v, ok
+}
+
+// set stores the value for the key.
+func (r *registry[K, V]) set(k K, v V) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.m == nil {
+		r.m = map[K]V{}
+	}
+	r.m[k] = v
+}
+
+// update stores the result of fn, which is given the value currently stored
+// for the key, if any.
+func (r *registry[K, V]) update(k K, fn func(V) V) V {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.m == nil {
+		r.m = map[K]V{}
+	}
+	v := fn(r.m[k])
+	r.m[k] = v
+	return v
+}
+
+// take removes the value stored for the key and returns it.
+func (r *registry[K, V]) take(k K) (V, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	v, ok := r.m[k]
+	delete(r.m, k)
+	return v, ok
+}
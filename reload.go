@@ -0,0 +1,36 @@
+package envy
+
+import "github.com/spf13/pflag"
+
+// Used to mark flags that take effect when set again while the program runs.
+const AnnotationReloadable = "envy_reloadable"
+
+// Reloadable marks a flag in the default pflag.CommandLine as reloadable, see
+// ReloadableOnFlagSet.
+func Reloadable(name string) {
+	ReloadableOnFlagSet(name, pflag.CommandLine)
+}
+
+// ReloadableOnFlagSet marks the given flag as taking effect when it's set again
+// while the program runs, like a log level, so reload handlers can tell which
+// changes apply without a restart. Flags from LogLevelVar are marked already.
+// The mark is shown in the schema from SchemaFlagSet.
+func ReloadableOnFlagSet(name string, fs *pflag.FlagSet) {
+	std.on("", fs).Reloadable(name)
+}
+
+// Reloadable marks the given flag as reloadable, see ReloadableOnFlagSet.
+func (e *Envy) Reloadable(name string) {
+	f := e.fs.Lookup(name)
+	if f == nil {
+		e.fail(&SetError{Flag: name, Err: ErrFlagNotExists})
+		return
+	}
+	annotate(f, AnnotationReloadable, "true")
+}
+
+// isReloadable reports whether the flag was marked with Reloadable.
+func isReloadable(f *pflag.Flag) bool {
+	_, ok := f.Annotations[AnnotationReloadable]
+	return ok
+}
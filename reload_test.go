@@ -0,0 +1,29 @@
+package envy_test
+
+import (
+	"testing"
+
+	"github.com/fernferret/envy"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReloadable(t *testing.T) {
+	pflag.CommandLine = pflag.NewFlagSet("test", pflag.PanicOnError)
+
+	level := testLevel(1)
+	envy.LogLevelVar(&level, "log-level", "set the log level")
+	pflag.Int("rate", 10, "requests per second")
+	pflag.String("addr", ":8080", "listen address")
+	envy.Reloadable("rate")
+	envy.Parse("FOO")
+
+	assert.Equal(t, []string{"true"}, pflag.Lookup("log-level").Annotations[envy.AnnotationReloadable])
+	reloadable := map[string]bool{}
+	for _, b := range envy.Schema() {
+		reloadable[b.Flag] = b.Reloadable
+	}
+	assert.Equal(t, map[string]bool{"addr": false, "log-level": true, "rate": true}, reloadable)
+
+	assert.PanicsWithError(t, "--missing: "+envy.ErrFlagNotExists.Error(), func() { envy.Reloadable("missing") })
+}
@@ -0,0 +1,61 @@
+package envy
+
+import (
+	"errors"
+
+	"github.com/spf13/pflag"
+)
+
+// Used to mark flags that must be given on the command line or in the
+// environment.
+const AnnotationRequired = "envy_required"
+
+var ErrRequired = errors.New("flag is required, set it on the command line or in the environment")
+
+// Require marks a flag in the default pflag.CommandLine as required, see
+// RequireOnFlagSet.
+func Require(name string) {
+	RequireOnFlagSet(name, pflag.CommandLine)
+}
+
+// RequireOnFlagSet marks the given flag as required, so Finalize fails unless
+// it was given on the command line or read from its environment variable.
+// Since the command line is parsed after envy.Parse(), the check is left to
+// FinalizeFlagSet. It must be called before the call to envy.Parse().
+func RequireOnFlagSet(name string, fs *pflag.FlagSet) {
+	std.on("", fs).Require(name)
+}
+
+// Require marks the given flag as required, see RequireOnFlagSet.
+func (e *Envy) Require(name string) {
+	f := e.fs.Lookup(name)
+	if f == nil {
+		e.fail(&SetError{Flag: name, Err: ErrFlagNotExists})
+		return
+	}
+	annotate(f, AnnotationRequired, "true")
+}
+
+// missing returns an error for every required flag that wasn't set.
+func (e *Envy) missing() ParseErrors {
+	p := instanceFor(e.fs)
+	var errs ParseErrors
+	visitAll(e.fs, func(f *pflag.Flag) {
+		if _, ok := f.Annotations[AnnotationRequired]; !ok || f.Changed {
+			return
+		}
+		if _, ok := f.Annotations[AnnotationSource]; ok {
+			return
+		}
+		if f.DefValue != codeDefault(f) {
+			// The environment supplied the default.
+			return
+		}
+		err := &SetError{Flag: f.Name, Err: ErrRequired}
+		if _, ok := f.Annotations[AnnotationDisable]; !ok {
+			err.EnvName = p.envNameFor(p.prefix, f)
+		}
+		errs = append(errs, err)
+	})
+	return errs
+}
@@ -0,0 +1,52 @@
+package envy_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/fernferret/envy"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequire(t *testing.T) {
+	tests := []struct {
+		name string
+		env  map[string]string
+		args []string
+		err  string
+	}{
+		{name: "missing", err: "--token from FOO_TOKEN: " + envy.ErrRequired.Error()},
+		{name: "from env", env: map[string]string{"FOO_TOKEN": "abc"}},
+		{name: "from flag", args: []string{"--token=abc"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			os.Clearenv()
+			for k, v := range tt.env {
+				os.Setenv(k, v)
+			}
+			fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+			fs.String("token", "", "api token")
+			fs.String("url", "http://localhost", "set the url")
+			envy.RequireOnFlagSet("token", fs)
+			envy.ParseFlagSet("FOO", fs)
+			if err := fs.Parse(tt.args); err != nil {
+				t.Fatal(err)
+			}
+
+			err := envy.FinalizeFlagSet(fs)
+			if tt.err == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, tt.err)
+			assert.ErrorIs(t, err, envy.ErrRequired)
+		})
+	}
+}
+
+func TestRequireMissingFlag(t *testing.T) {
+	pflag.CommandLine = pflag.NewFlagSet("test", pflag.PanicOnError)
+	assert.PanicsWithError(t, "--missing: "+envy.ErrFlagNotExists.Error(), func() { envy.Require("missing") })
+}
@@ -0,0 +1,51 @@
+package envy
+
+import "github.com/spf13/pflag"
+
+// ResolveInto resolves the default pflag.CommandLine under the given prefix,
+// see ResolveIntoFlagSet.
+func ResolveInto(pfx string) map[string]string {
+	return ResolveIntoFlagSet(pfx, pflag.CommandLine)
+}
+
+// ResolveIntoFlagSet returns the value every flag in the given FlagSet would
+// have if it was parsed with the given prefix, without changing the FlagSet.
+// This allows one process to host several configurations, like per tenant
+// settings, under different prefixes. Flags from the command line still win,
+// and flags that were disabled or given a custom variable are shared by every
+// prefix. Like Parse, it panics on invalid bools and durations, or logs them
+// and keeps the flag's default when using ReturnErrors.
+func ResolveIntoFlagSet(pfx string, fs *pflag.FlagSet) map[string]string {
+	pfx = normalizePrefix(pfx)
+	e := instanceFor(fs)
+
+	values := map[string]string{}
+	var errs ParseErrors
+	visitAll(fs, func(f *pflag.Flag) {
+		values[f.Name] = f.DefValue
+		if _, ok := f.Annotations[AnnotationDisable]; ok || f.Changed {
+			values[f.Name] = f.Value.String()
+			return
+		}
+		envName, val, ok, err := e.lookupFlag(e.envNamesFor(pfx, f), f)
+		if err != nil {
+			errs = append(errs, &SetError{Flag: f.Name, EnvName: envName, Err: err})
+			return
+		}
+		if !ok || e.filesFirst() {
+			if path, fileVal, found := e.lookupFile(f); found {
+				envName, val, ok = path, fileVal, true
+			}
+		}
+		if ok {
+			norm, err := e.normalize(f, val)
+			if err != nil {
+				errs = append(errs, valueError(f, envName, val, err))
+				return
+			}
+			values[f.Name] = norm
+		}
+	})
+	e.report(errs)
+	return values
+}
@@ -0,0 +1,75 @@
+package envy_test
+
+import (
+	"bytes"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/fernferret/envy"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolveInto(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("ACME_URL", "https://acme.example.com")
+	os.Setenv("ACME_INTERVAL", "90s")
+	os.Setenv("GLOBEX_URL", "https://globex.example.com")
+	os.Setenv("KUBECONFIG", "/shared")
+	pflag.CommandLine = pflag.NewFlagSet("test", pflag.PanicOnError)
+
+	pflag.String("url", "http://localhost", "set the url")
+	pflag.Duration("interval", time.Minute, "check interval")
+	pflag.Bool("verbose", false, "verbose output")
+	pflag.String("kube-config", "", "kube config")
+	envy.SetEnvName("kube-config", "KUBECONFIG")
+	pflag.CommandLine.Parse([]string{"--verbose"})
+
+	assert.Equal(t, map[string]string{
+		"url":         "https://acme.example.com",
+		"interval":    "1m30s",
+		"verbose":     "true",
+		"kube-config": "/shared",
+	}, envy.ResolveInto("acme"))
+
+	assert.Equal(t, map[string]string{
+		"url":         "https://globex.example.com",
+		"interval":    "1m0s",
+		"verbose":     "true",
+		"kube-config": "/shared",
+	}, envy.ResolveInto("globex"))
+
+	// Nothing about the FlagSet itself changed
+	assert.Equal(t, "http://localhost", pflag.Lookup("url").Value.String())
+	assert.Equal(t, "set the url", pflag.Lookup("url").Usage)
+}
+
+func TestResolveIntoInvalid(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("ACME_VERBOSE", "yes")
+	pflag.CommandLine = pflag.NewFlagSet("test", pflag.PanicOnError)
+
+	pflag.Bool("verbose", false, "verbose output")
+
+	assert.PanicsWithError(t, "--verbose from ACME_VERBOSE: "+envy.ErrInvalidBoolFlagValue.Error(), func() { envy.ResolveInto("acme") })
+}
+
+func TestResolveIntoReturnErrors(t *testing.T) {
+	envy.SetStrictness(envy.ReturnErrors)
+	defer envy.SetStrictness(envy.PanicOnErrors)
+	buf := &bytes.Buffer{}
+	envy.SetOutput(buf)
+	defer envy.SetOutput(os.Stderr)
+
+	os.Clearenv()
+	os.Setenv("ACME_VERBOSE", "yes")
+	os.Setenv("ACME_URL", "http://acme")
+	pflag.CommandLine = pflag.NewFlagSet("test", pflag.PanicOnError)
+
+	pflag.Bool("verbose", false, "verbose output")
+	pflag.String("url", "http://localhost", "set the url")
+
+	assert.Equal(t, map[string]string{"verbose": "false", "url": "http://acme"}, envy.ResolveInto("acme"))
+	assert.Contains(t, buf.String(), "--verbose from ACME_VERBOSE: "+envy.ErrInvalidBoolFlagValue.Error())
+}
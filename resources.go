@@ -0,0 +1,179 @@
+package envy
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+var ErrUnknownResource = errors.New("system resource could not be detected")
+
+// Resources are the limits that values like 25%mem and 2xcpu are resolved
+// against.
+type Resources struct {
+	// The number of CPUs available, which may be fractional under a cgroup
+	// quota.
+	CPU float64
+
+	// The memory available in bytes, 0 if unknown.
+	Memory uint64
+}
+
+// Overrides detection when set, see SetResources.
+var resources *Resources
+
+// Where the cgroup filesystem and meminfo are read from.
+const (
+	cgroupRoot  = "/sys/fs/cgroup"
+	procMeminfo = "/proc/meminfo"
+)
+
+// Anything at or above this in a cgroup v1 memory limit means unlimited.
+const cgroupUnlimited = 1 << 62
+
+// SetResources replaces the detected system resources used to resolve relative
+// values, passing nil restores detection. It must be called before the call to
+// envy.Parse().
+func SetResources(r *Resources) {
+	resources = r
+}
+
+// DetectResources returns the CPU and memory available to this process,
+// honoring cgroup v1 and v2 limits when running in a container.
+func DetectResources() Resources {
+	r := Resources{CPU: float64(runtime.NumCPU())}
+	if quota, ok := cgroupCPU(); ok && quota < r.CPU {
+		r.CPU = quota
+	}
+	r.Memory = totalMemory()
+	if limit, ok := cgroupMemory(); ok && (r.Memory == 0 || limit < r.Memory) {
+		r.Memory = limit
+	}
+	return r
+}
+
+// Matches values like 25%mem, 0.5xcpu or 2xcpu.
+var relativeValue = regexp.MustCompile(`^(?i)([\d.]+)(%|x)(cpu|mem)$`)
+
+// resolveRelative turns a value relative to the system resources into a plain
+// number for numeric flags. Values that aren't relative are returned as is.
+func resolveRelative(f *pflag.Flag, val string) (string, error) {
+	n, ok, err := relativeAmount(val)
+	if !ok || err != nil {
+		return val, err
+	}
+	if strings.HasPrefix(f.Value.Type(), "float") {
+		return strconv.FormatFloat(n, 'f', -1, 64), nil
+	}
+
+	// Whole numbers are rounded down, but never to nothing.
+	return strconv.FormatFloat(math.Max(1, math.Floor(n)), 'f', 0, 64), nil
+}
+
+// relativeAmount resolves a value like 25%mem against the system resources,
+// ok is false if the value isn't relative at all.
+func relativeAmount(val string) (float64, bool, error) {
+	m := relativeValue.FindStringSubmatch(strings.TrimSpace(val))
+	if m == nil {
+		return 0, false, nil
+	}
+	n, err := strconv.ParseFloat(m[1], 64)
+	if err != nil {
+		return 0, false, nil
+	}
+	if m[2] == "%" {
+		n /= 100
+	}
+
+	r := resources
+	if r == nil {
+		detected := DetectResources()
+		r = &detected
+	}
+	total := r.CPU
+	if strings.ToLower(m[3]) == "mem" {
+		total = float64(r.Memory)
+	}
+	if total <= 0 {
+		return 0, true, fmt.Errorf("%w: %s", ErrUnknownResource, strings.ToLower(m[3]))
+	}
+	return n * total, true, nil
+}
+
+// cgroupCPU reads the CPU quota from cgroup v2, falling back to v1.
+func cgroupCPU() (float64, bool) {
+	if fields := strings.Fields(readFile(filepath.Join(cgroupRoot, "cpu.max"))); len(fields) == 2 {
+		return cpuQuota(fields[0], fields[1])
+	}
+	quota := readFile(filepath.Join(cgroupRoot, "cpu", "cpu.cfs_quota_us"))
+	period := readFile(filepath.Join(cgroupRoot, "cpu", "cpu.cfs_period_us"))
+	return cpuQuota(quota, period)
+}
+
+// cpuQuota divides a cgroup quota by its period, a quota of max or -1 means
+// there's no limit.
+func cpuQuota(quota, period string) (float64, bool) {
+	q, err := strconv.ParseFloat(quota, 64)
+	if err != nil || q <= 0 {
+		return 0, false
+	}
+	p, err := strconv.ParseFloat(period, 64)
+	if err != nil || p <= 0 {
+		return 0, false
+	}
+	return q / p, true
+}
+
+// cgroupMemory reads the memory limit from cgroup v2, falling back to v1.
+func cgroupMemory() (uint64, bool) {
+	for _, path := range []string{
+		filepath.Join(cgroupRoot, "memory.max"),
+		filepath.Join(cgroupRoot, "memory", "memory.limit_in_bytes"),
+	} {
+		limit, err := strconv.ParseUint(readFile(path), 10, 64)
+		if err == nil && limit > 0 && limit < cgroupUnlimited {
+			return limit, true
+		}
+	}
+	return 0, false
+}
+
+// totalMemory reads MemTotal from /proc/meminfo, 0 if it isn't available.
+func totalMemory() uint64 {
+	file, err := os.Open(procMeminfo)
+	if err != nil {
+		return 0
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) >= 2 && fields[0] == "MemTotal:" {
+			kb, err := strconv.ParseUint(fields[1], 10, 64)
+			if err != nil {
+				return 0
+			}
+			return kb * 1024
+		}
+	}
+	return 0
+}
+
+// readFile returns the trimmed contents of a small file, empty on any error.
+func readFile(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
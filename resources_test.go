@@ -0,0 +1,67 @@
+package envy_test
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/fernferret/envy"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRelativeValues(t *testing.T) {
+	tests := []struct {
+		name      string
+		value     interface{}
+		env       string
+		resources *envy.Resources
+		exp       string
+		err       error
+	}{
+		{name: "test times cpu", value: 0, env: "2xcpu", exp: "8"},
+		{name: "test percent mem", value: int64(0), env: "25%mem", exp: "1073741824"},
+		{name: "test uppercase", value: uint(0), env: "50%CPU", exp: "2"},
+		{name: "test fractional cpu", value: 0, env: "2xcpu", resources: &envy.Resources{CPU: 1.5}, exp: "3"},
+		{name: "test never zero", value: 0, env: "10%cpu", exp: "1"},
+		{name: "test float keeps fraction", value: 0.0, env: "0.5xcpu", resources: &envy.Resources{CPU: 1.5}, exp: "0.75"},
+		{name: "test plain value", value: 0, env: "12", exp: "12"},
+		{name: "test unknown memory", value: 0, env: "10%mem", resources: &envy.Resources{CPU: 1}, err: envy.ErrUnknownResource},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			os.Clearenv()
+			os.Setenv("FOO_VAL", tt.env)
+			fs := pflag.NewFlagSet("test", pflag.PanicOnError)
+			switch v := tt.value.(type) {
+			case int:
+				fs.Int("val", v, "the value")
+			case int64:
+				fs.Int64("val", v, "the value")
+			case uint:
+				fs.Uint("val", v, "the value")
+			case float64:
+				fs.Float64("val", v, "the value")
+			}
+
+			r := tt.resources
+			if r == nil {
+				r = &envy.Resources{CPU: 4, Memory: 4 << 30}
+			}
+			envy.SetResources(r)
+			defer envy.SetResources(nil)
+
+			if tt.err != nil {
+				assert.PanicsWithError(t, fmt.Sprintf("--val from FOO_VAL: %s: mem", tt.err), func() { envy.ParseFlagSet("FOO", fs) })
+				return
+			}
+			envy.ParseFlagSet("FOO", fs)
+			assert.Equal(t, tt.exp, fs.Lookup("val").Value.String())
+		})
+	}
+}
+
+func TestDetectResources(t *testing.T) {
+	r := envy.DetectResources()
+	assert.Greater(t, r.CPU, 0.0)
+}
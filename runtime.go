@@ -0,0 +1,123 @@
+package envy
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+var ErrInvalidMemLimit = errors.New("memory limit must be off, a size like 512MiB or a percent like 90%mem")
+
+// The share of a cgroup memory limit used for GOMEMLIMIT when --gomemlimit
+// isn't set, leaving headroom for memory the Go runtime doesn't manage.
+const autoMemLimit = 0.9
+
+// The RuntimeTuning registered on each FlagSet, applied by FinalizeFlagSet.
+var tunings registry[*pflag.FlagSet, *RuntimeTuning]
+
+// RuntimeTuning holds the values set by the flags from RuntimeFlags.
+type RuntimeTuning struct {
+	MaxProcs int
+	MemLimit string
+}
+
+// RuntimeFlags defines --gomaxprocs and --gomemlimit on the default
+// pflag.CommandLine, see RuntimeFlagsOnFlagSet.
+func RuntimeFlags() *RuntimeTuning {
+	return RuntimeFlagsOnFlagSet(pflag.CommandLine)
+}
+
+// RuntimeFlagsOnFlagSet defines --gomaxprocs and --gomemlimit on the given
+// FlagSet, which envy binds like any other flag so MYAPP_GOMAXPROCS=2xcpu
+// works too. They're applied to the Go runtime by FinalizeFlagSet. Left unset,
+// they follow the CPU quota and 90% of the memory limit of the process's
+// cgroup, unless GOMAXPROCS or GOMEMLIMIT are already set.
+func RuntimeFlagsOnFlagSet(fs *pflag.FlagSet) *RuntimeTuning {
+	t := &RuntimeTuning{}
+	fs.IntVar(&t.MaxProcs, "gomaxprocs", 0, "number of OS threads running Go code at once, 0 uses the cgroup CPU quota")
+	fs.StringVar(&t.MemLimit, "gomemlimit", "", "soft memory limit for the Go runtime like 512MiB, 90%mem or off, empty uses 90% of the cgroup memory limit")
+	tunings.set(fs, t)
+	return t
+}
+
+// Apply sets GOMAXPROCS and the memory limit of the Go runtime. It's called by
+// FinalizeFlagSet, so only needs calling directly when not using Finalize.
+func (t *RuntimeTuning) Apply() error {
+	limit, err := t.memLimit()
+	if err != nil {
+		return err
+	}
+
+	procs := t.MaxProcs
+	if procs <= 0 && os.Getenv("GOMAXPROCS") == "" {
+		if quota, ok := cgroupCPU(); ok {
+			procs = int(math.Max(1, math.Floor(quota)))
+		}
+	}
+	if procs > 0 {
+		runtime.GOMAXPROCS(procs)
+	}
+	if limit > 0 {
+		debug.SetMemoryLimit(limit)
+	}
+	return nil
+}
+
+// memLimit works out the memory limit to set, 0 meaning leave it alone.
+func (t *RuntimeTuning) memLimit() (int64, error) {
+	val := strings.TrimSpace(t.MemLimit)
+	switch {
+	case val == "":
+		if limit, ok := cgroupMemory(); ok && os.Getenv("GOMEMLIMIT") == "" {
+			return int64(float64(limit) * autoMemLimit), nil
+		}
+		return 0, nil
+	case strings.EqualFold(val, "off"):
+		return math.MaxInt64, nil
+	case strings.HasSuffix(strings.ToLower(val), "mem"):
+		n, ok, err := relativeAmount(val)
+		if err != nil {
+			return 0, err
+		}
+		if ok {
+			return int64(n), nil
+		}
+	}
+	return parseByteSize(val)
+}
+
+// The suffixes GOMEMLIMIT accepts, largest first.
+var byteSuffixes = []struct {
+	suffix string
+	size   float64
+}{
+	{"TiB", 1 << 40},
+	{"GiB", 1 << 30},
+	{"MiB", 1 << 20},
+	{"KiB", 1 << 10},
+	{"B", 1},
+}
+
+// parseByteSize parses a size in the format of the GOMEMLIMIT environment
+// variable, like 512MiB or a plain number of bytes.
+func parseByteSize(val string) (int64, error) {
+	size := 1.0
+	for _, s := range byteSuffixes {
+		if strings.HasSuffix(val, s.suffix) {
+			val, size = strings.TrimSuffix(val, s.suffix), s.size
+			break
+		}
+	}
+	n, err := strconv.ParseFloat(strings.TrimSpace(val), 64)
+	if err != nil || n < 0 || n*size >= math.MaxInt64 {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidMemLimit, val)
+	}
+	return int64(n * size), nil
+}
@@ -0,0 +1,62 @@
+package envy_test
+
+import (
+	"os"
+	"runtime"
+	"runtime/debug"
+	"testing"
+
+	"github.com/fernferret/envy"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRuntimeFlags(t *testing.T) {
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(0))
+	defer debug.SetMemoryLimit(debug.SetMemoryLimit(-1))
+
+	tests := []struct {
+		name  string
+		env   map[string]string
+		args  []string
+		procs int
+		limit int64
+		err   error
+	}{
+		{name: "test flags", args: []string{"--gomaxprocs=3", "--gomemlimit=512MiB"}, procs: 3, limit: 512 << 20},
+		{name: "test env", env: map[string]string{"FOO_GOMAXPROCS": "2", "FOO_GOMEMLIMIT": "1GiB"}, procs: 2, limit: 1 << 30},
+		{name: "test relative", env: map[string]string{"FOO_GOMAXPROCS": "50%cpu", "FOO_GOMEMLIMIT": "25%mem"}, procs: 2, limit: 1 << 30},
+		{name: "test plain bytes", args: []string{"--gomemlimit=1000"}, limit: 1000},
+		{name: "test off", args: []string{"--gomemlimit=off"}, limit: 1<<63 - 1},
+		{name: "test invalid", args: []string{"--gomemlimit=lots"}, err: envy.ErrInvalidMemLimit},
+		{name: "test cpu limit", args: []string{"--gomemlimit=50%cpu"}, err: envy.ErrInvalidMemLimit},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			os.Clearenv()
+			for k, v := range tt.env {
+				os.Setenv(k, v)
+			}
+			envy.SetResources(&envy.Resources{CPU: 4, Memory: 4 << 30})
+			defer envy.SetResources(nil)
+
+			fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+			envy.RuntimeFlagsOnFlagSet(fs)
+			envy.ParseFlagSet("FOO", fs)
+			assert.NoError(t, fs.Parse(tt.args))
+
+			runtime.GOMAXPROCS(1)
+			debug.SetMemoryLimit(1 << 40)
+			err := envy.FinalizeFlagSet(fs)
+			if tt.err != nil {
+				assert.ErrorIs(t, err, tt.err)
+				return
+			}
+			assert.NoError(t, err)
+			if tt.procs != 0 {
+				assert.Equal(t, tt.procs, runtime.GOMAXPROCS(0))
+			}
+			assert.Equal(t, tt.limit, debug.SetMemoryLimit(-1))
+		})
+	}
+}
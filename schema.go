@@ -0,0 +1,155 @@
+package envy
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"sort"
+
+	"github.com/spf13/pflag"
+)
+
+// Used to keep a flag's usage from before decorate added its variable.
+const AnnotationUsage = "envy_usage"
+
+// SchemaPath is the well-known path to serve the schema from so a central
+// service can find it on every deployed service.
+const SchemaPath = "/.well-known/envy/schema"
+
+// Binding describes how one flag is bound to the environment.
+type Binding struct {
+	Flag string `json:"flag"`
+	Type string `json:"type"`
+
+	// The variables checked in order, empty for flags envy didn't bind.
+	EnvNames []string `json:"env_names,omitempty"`
+
+	// The default value, hidden for secrets, Hashed flags and Redactors the
+	// same way as in usage.
+	Default string `json:"default"`
+	Usage   string `json:"usage"`
+	Secret  bool   `json:"secret,omitempty"`
+
+	// The allowed values of flags whose value implements Chooser.
+	Choices []string `json:"choices,omitempty"`
+
+	// Set for flags marked with Reloadable.
+	Reloadable bool `json:"reloadable,omitempty"`
+
+	// The module that registered the flag, see Module.
+	Module string `json:"module,omitempty"`
+
+	// The variable that enables the flag, for flags declared with Gate. Flags
+	// of gates that are off are listed too, so every setting is documented.
+	Gate string `json:"gate,omitempty"`
+
+	// Documentation metadata from Doc.
+	Example string `json:"example,omitempty"`
+	Since   string `json:"since,omitempty"`
+	Link    string `json:"link,omitempty"`
+}
+
+// Schema describes how every flag in the default pflag.CommandLine is bound,
+// see SchemaFlagSet.
+func Schema() []Binding {
+	return SchemaFlagSet(pflag.CommandLine)
+}
+
+// SchemaFlagSet describes how every flag in the given FlagSet is bound to the
+// environment, in lexical order by name. Unlike Sources it doesn't depend on
+// the current values, so it only changes when the flags do. Flags of gates
+// that are off are included with the variables they would be read from. It
+// must be called after the call to envy.Parse().
+func SchemaFlagSet(fs *pflag.FlagSet) []Binding {
+	e := instanceFor(fs)
+	var schema []Binding
+	visitAll(fs, func(f *pflag.Flag) {
+		b := bindingOf(f)
+		if _, ok := f.Annotations[AnnotationBound]; ok {
+			b.EnvNames = e.boundNames(f)
+		}
+		schema = append(schema, b)
+	})
+	for _, f := range e.gated {
+		b := bindingOf(f)
+		b.EnvNames = e.envNamesFor(e.prefix, f)
+		schema = append(schema, b)
+	}
+	sort.Slice(schema, func(i, j int) bool {
+		return schema[i].Flag < schema[j].Flag
+	})
+	return schema
+}
+
+// bindingOf describes the flag, leaving EnvNames to the caller.
+func bindingOf(f *pflag.Flag) Binding {
+	b := Binding{
+		Flag:       f.Name,
+		Type:       f.Value.Type(),
+		Default:    codeDefault(f),
+		Usage:      usageOf(f),
+		Secret:     isSecret(f),
+		Choices:    choicesOf(f),
+		Reloadable: isReloadable(f),
+		Example:    docOf(f, AnnotationDocExample),
+		Since:      docOf(f, AnnotationDocSince),
+		Link:       docOf(f, AnnotationDocLink),
+	}
+	if module, ok := f.Annotations[AnnotationModule]; ok {
+		b.Module = module[0]
+	}
+	if gate, ok := f.Annotations[AnnotationGate]; ok {
+		b.Gate = gate[0]
+	}
+	if b.Default != "" {
+		b.Default = rawDisplayValue(f, b.Default)
+	}
+	return b
+}
+
+// SchemaHandler serves the schema of the default pflag.CommandLine, see
+// SchemaHandlerFlagSet.
+func SchemaHandler() http.Handler {
+	return SchemaHandlerFlagSet(pflag.CommandLine)
+}
+
+// SchemaHandlerFlagSet serves the schema from SchemaFlagSet as JSON with an
+// ETag, so a config governance service can cheaply crawl deployed services to
+// build a catalog of every variable in use. Mount it at SchemaPath:
+//
+//	mux.Handle(envy.SchemaPath, envy.SchemaHandler())
+func SchemaHandlerFlagSet(fs *pflag.FlagSet) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+		body, err := json.Marshal(SchemaFlagSet(fs))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		sum := sha256.Sum256(body)
+		etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+
+		w.Header().Set("ETag", etag)
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == http.MethodGet {
+			w.Write(body)
+		}
+	})
+}
+
+// usageOf returns the flag's usage without envy's decoration.
+func usageOf(f *pflag.Flag) string {
+	if usage, ok := f.Annotations[AnnotationUsage]; ok {
+		return usage[0]
+	}
+	return f.Usage
+}
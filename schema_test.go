@@ -0,0 +1,119 @@
+package envy_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/fernferret/envy"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchema(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("FOO_URL", "http://example.com")
+	pflag.CommandLine = pflag.NewFlagSet("test", pflag.PanicOnError)
+
+	pflag.String("url", "http://localhost", "set the url")
+	pflag.String("token", "default-token", "api token")
+	pflag.Bool("once", false, "only once")
+	envy.Secret("token")
+	envy.SetEnvNames("url", "FOO_URL", "URL")
+	envy.Disable("once")
+	envy.Parse("FOO")
+
+	assert.Equal(t, []envy.Binding{
+		{Flag: "once", Type: "bool", Default: "false", Usage: "only once"},
+		{Flag: "token", Type: "string", EnvNames: []string{"FOO_TOKEN"}, Default: "<redacted>", Usage: "api token", Secret: true},
+		{Flag: "url", Type: "string", EnvNames: []string{"FOO_URL", "URL"}, Default: "http://localhost", Usage: "set the url"},
+	}, envy.Schema())
+}
+
+func TestSchemaDefaultsHidden(t *testing.T) {
+	os.Clearenv()
+	pflag.CommandLine = pflag.NewFlagSet("test", pflag.PanicOnError)
+
+	pflag.String("ca", "-----BEGIN CERTIFICATE-----", "ca bundle")
+	dsn := envy.DSN{URL: "postgres://app:hunter2@db/app"}
+	envy.DSNVar(&dsn, "database-url", "postgres", "database")
+	envy.Hashed("ca")
+	envy.Parse("FOO")
+
+	defaults := map[string]string{}
+	for _, b := range envy.Schema() {
+		defaults[b.Flag] = b.Default
+	}
+	assert.Regexp(t, `^sha256:[0-9a-f]{64}$`, defaults["ca"])
+	assert.Equal(t, "<redacted>", defaults["database-url"])
+}
+
+func TestSchemaGate(t *testing.T) {
+	for _, on := range []string{"false", "true"} {
+		t.Run("gate "+on, func(t *testing.T) {
+			fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+			fs.String("url", "http://localhost", "set the url")
+			envy.GateOnFlagSet("experimental", func(fs *pflag.FlagSet) {
+				fs.Bool("turbo", false, "go faster")
+			}, fs)
+			env := envy.MapLookuper{"FOO_EXPERIMENTAL": on}
+			assert.NoError(t, envy.New(envy.WithFlagSet(fs), envy.WithPrefix("FOO"), envy.WithLookuper(env)).ParseE())
+
+			assert.Equal(t, []envy.Binding{
+				{Flag: "turbo", Type: "bool", EnvNames: []string{"FOO_TURBO"}, Default: "false", Usage: "go faster", Gate: "FOO_EXPERIMENTAL"},
+				{Flag: "url", Type: "string", EnvNames: []string{"FOO_URL"}, Default: "http://localhost", Usage: "set the url"},
+			}, envy.SchemaFlagSet(fs))
+		})
+	}
+}
+
+func TestSchemaHandler(t *testing.T) {
+	os.Clearenv()
+	pflag.CommandLine = pflag.NewFlagSet("test", pflag.PanicOnError)
+	pflag.String("url", "http://localhost", "set the url")
+	envy.Parse("FOO")
+
+	srv := httptest.NewServer(envy.SchemaHandler())
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + envy.SchemaPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var schema []envy.Binding
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&schema))
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+	assert.Equal(t, envy.Schema(), schema)
+	etag := resp.Header.Get("ETag")
+	assert.NotEmpty(t, etag)
+
+	req, _ := http.NewRequest(http.MethodGet, srv.URL, nil)
+	req.Header.Set("If-None-Match", etag)
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	assert.Equal(t, http.StatusNotModified, resp.StatusCode)
+
+	pflag.Int("workers", 4, "number of workers")
+	envy.BindLate(pflag.CommandLine)
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.NotEqual(t, etag, resp.Header.Get("ETag"))
+
+	resp, err = http.Post(srv.URL, "application/json", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+}
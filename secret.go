@@ -0,0 +1,67 @@
+package envy
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"errors"
+
+	"github.com/spf13/pflag"
+)
+
+var ErrNotSecret = errors.New("flag is not marked with Secret")
+
+// Used to record where a secret manager keeps the flag's value.
+const AnnotationSecretPath = "envy_secret_path"
+
+// SetSecretPath maps a flag in the default pflag.CommandLine to a secret
+// manager path, see SetSecretPathOnFlagSet.
+func SetSecretPath(name, path string) {
+	SetSecretPathOnFlagSet(name, path, pflag.CommandLine)
+}
+
+// SetSecretPathOnFlagSet maps a flag to where a secret manager keeps its
+// value, like "secret/data/app#password" for Vault. If none of the flag's
+// environment variables are set, the path itself is looked up, which only a
+// Lookuper for that secret manager, chained behind the environment, will
+// answer. The flag is also marked with Secret. It must be called before the
+// call to envy.Parse().
+func SetSecretPathOnFlagSet(name, path string, fs *pflag.FlagSet) {
+	std.on("", fs).SetSecretPath(name, path)
+}
+
+// SetSecretPath maps a flag to where a secret manager keeps its value, see
+// SetSecretPathOnFlagSet.
+func (e *Envy) SetSecretPath(name, path string) {
+	f := e.fs.Lookup(name)
+	if f == nil {
+		e.fail(&SetError{Flag: name, Err: ErrFlagNotExists})
+		return
+	}
+	annotate(f, AnnotationSecretPath, path)
+	annotate(f, AnnotationSecret, "true")
+}
+
+// SecretEqual compares a candidate against a secret flag in the default
+// pflag.CommandLine, see SecretEqualOnFlagSet.
+func SecretEqual(name, candidate string) bool {
+	return SecretEqualOnFlagSet(name, candidate, pflag.CommandLine)
+}
+
+// SecretEqualOnFlagSet reports whether the candidate matches the value of a
+// flag marked with Secret, in constant time so auth checks built on tokens
+// from the environment don't leak how much of a guess was right. Both sides
+// are hashed first so not even the length leaks. It panics if the flag
+// doesn't exist or isn't a secret, since comparing a regular flag this way is
+// almost certainly a mistake.
+func SecretEqualOnFlagSet(name, candidate string, fs *pflag.FlagSet) bool {
+	f := fs.Lookup(name)
+	if f == nil {
+		panic(&SetError{Flag: name, Err: ErrFlagNotExists})
+	}
+	if !isSecret(f) {
+		panic(&SetError{Flag: name, Err: ErrNotSecret})
+	}
+	want := sha256.Sum256([]byte(f.Value.String()))
+	got := sha256.Sum256([]byte(candidate))
+	return subtle.ConstantTimeCompare(want[:], got[:]) == 1
+}
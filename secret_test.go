@@ -0,0 +1,47 @@
+package envy_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/fernferret/envy"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSecretEqual(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("MYAPP_API_TOKEN", "hunter2")
+	pflag.CommandLine = pflag.NewFlagSet("test", pflag.PanicOnError)
+
+	pflag.String("api-token", "", "api token")
+	pflag.String("name", "", "name")
+	envy.Secret("api-token")
+	envy.Parse("MYAPP")
+
+	assert.True(t, envy.SecretEqual("api-token", "hunter2"))
+	assert.False(t, envy.SecretEqual("api-token", "hunter"))
+	assert.False(t, envy.SecretEqual("api-token", "hunter22"))
+	assert.False(t, envy.SecretEqual("api-token", ""))
+	assert.PanicsWithError(t, "--name: "+envy.ErrNotSecret.Error(), func() { envy.SecretEqual("name", "") })
+	assert.PanicsWithError(t, "--missing: "+envy.ErrFlagNotExists.Error(), func() { envy.SecretEqual("missing", "") })
+}
+
+func TestSetSecretPath(t *testing.T) {
+	os.Clearenv()
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	password := fs.String("db-password", "", "database password")
+	user := fs.String("db-user", "", "database user")
+
+	l := envy.MapLookuper{"secret/data/db#password": "hunter2", "secret/data/db#user": "vault", "MYAPP_DB_USER": "env"}
+	e := envy.New(envy.WithPrefix("MYAPP"), envy.WithFlagSet(fs), envy.WithLookuper(l))
+	e.SetSecretPath("db-password", "secret/data/db#password")
+	e.SetSecretPath("db-user", "secret/data/db#user")
+	assert.NoError(t, e.ParseE())
+
+	assert.Equal(t, "hunter2", *password)
+	assert.Equal(t, "env", *user)
+	assert.True(t, envy.SecretEqualOnFlagSet("db-password", "hunter2", fs))
+	assert.Equal(t, "secret/data/db#password", envy.SourcesFlagSet(fs)[0].EnvName)
+	assert.Panics(t, func() { envy.SetSecretPath("missing", "secret/data/db#password") })
+}
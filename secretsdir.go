@@ -0,0 +1,56 @@
+package envy
+
+import (
+	"errors"
+	"io/fs"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+// DockerSecretsDir is where Docker Swarm and Compose mount secrets.
+const DockerSecretsDir = "/run/secrets"
+
+// SetSecretsDir enables reading flags marked with Secret from a directory of
+// files named after their lowercased environment variable, so with
+// SetSecretsDir(envy.DockerSecretsDir) a secret named myapp_password works
+// without any per-flag configuration. The file is only read if none of the
+// flag's variables are set, and passing an empty dir turns it off again. It
+// must be called before the call to envy.Parse().
+func SetSecretsDir(dir string) {
+	std.secretsDir = dir
+}
+
+// WithSecretsDir works like SetSecretsDir for this Envy only.
+func WithSecretsDir(dir string) Option {
+	return func(e *Envy) {
+		e.secretsDir = dir
+	}
+}
+
+// secretFiles returns the file in the secrets dir for each of the flag's
+// variables, or nothing if the flag can't be read from there.
+func (e *Envy) secretFiles(names []string, f *pflag.Flag) []string {
+	if e.secretsDir == "" || !isSecret(f) {
+		return nil
+	}
+	paths := make([]string, len(names))
+	for i, name := range names {
+		paths[i] = filepath.Join(e.secretsDir, strings.ToLower(name))
+	}
+	return paths
+}
+
+// lookupSecretFile reads the first of the flag's files in the secrets dir
+// that exists.
+func (e *Envy) lookupSecretFile(names []string, f *pflag.Flag) (string, string, bool, error) {
+	for _, path := range e.secretFiles(names, f) {
+		val, err := e.readFlagFile(f, path)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		return path, val, true, err
+	}
+	return "", "", false, nil
+}
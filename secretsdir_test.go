@@ -0,0 +1,59 @@
+package envy_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/fernferret/envy"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSecretsDir(t *testing.T) {
+	dir := t.TempDir()
+	for name, val := range map[string]string{"myapp_password": "hunter2\n", "myapp_user": "from-file", "myapp_token": "from-file"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(val), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	env := envy.MapLookuper{"MYAPP_TOKEN": "direct"}
+
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	password := fs.String("password", "", "password")
+	token := fs.String("token", "", "token")
+	user := fs.String("user", "", "user")
+	missing := fs.String("missing", "", "missing")
+	e := envy.New(envy.WithPrefix("MYAPP"), envy.WithFlagSet(fs), envy.WithLookuper(env), envy.WithSecretsDir(dir))
+	e.Secret("password")
+	e.Secret("token")
+	e.Secret("missing")
+	assert.NoError(t, e.ParseE())
+
+	assert.Equal(t, "hunter2", *password)
+	assert.Equal(t, "direct", *token)
+	assert.Equal(t, "", *missing)
+
+	// Only secrets are read from the directory.
+	assert.Equal(t, "", *user)
+
+	path := filepath.Join(dir, "myapp_password")
+	assert.Equal(t, path, envy.SourcesFlagSet(fs)[1].EnvName)
+	x := envy.ExplainFlagSet("password", fs)
+	assert.Equal(t, envy.Layer{Source: "file " + path, Value: "<redacted>", Set: true}, x.Layers[x.Winner])
+	x = envy.ExplainFlagSet("missing", fs)
+	assert.Equal(t, envy.Layer{Source: "file " + filepath.Join(dir, "myapp_missing"), Value: "<redacted>"}, x.Layers[2])
+}
+
+func TestSecretsDirLimits(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "myapp_password"), []byte("hunter2"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	fs.String("password", "", "password")
+	e := envy.New(envy.WithPrefix("MYAPP"), envy.WithFlagSet(fs), envy.WithLookuper(envy.MapLookuper{}),
+		envy.WithSecretsDir(dir), envy.WithMaxFileSize(4))
+	e.Secret("password")
+	assert.ErrorIs(t, e.ParseE(), envy.ErrFileTooLarge)
+}
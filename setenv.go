@@ -0,0 +1,59 @@
+package envy
+
+import (
+	"errors"
+	"os"
+
+	"github.com/spf13/pflag"
+)
+
+var ErrFlagDisabled = errors.New("flag does not read the environment")
+
+// Setenv sets the environment variable of a flag in the default
+// pflag.CommandLine, see SetenvOnFlagSet.
+func Setenv(name, value string) error {
+	return SetenvOnFlagSet(name, value, pflag.CommandLine)
+}
+
+// SetenvOnFlagSet sets the primary environment variable of a flag in the
+// given FlagSet in the process environment, honoring its prefix and any
+// custom names, so integration tests and code launching other programs never
+// hardcode variable names that drift from the flag definitions. The FlagSet
+// must have been parsed by envy, use the Envy's Setenv to set variables
+// before that.
+func SetenvOnFlagSet(name, value string, fs *pflag.FlagSet) error {
+	f := fs.Lookup(name)
+	if f == nil {
+		return &SetError{Flag: name, Err: ErrFlagNotExists}
+	}
+	if _, ok := f.Annotations[AnnotationDisable]; ok {
+		return &SetError{Flag: name, Err: ErrFlagDisabled}
+	}
+	pfx, ok := f.Annotations[AnnotationBound]
+	if !ok {
+		return &SetError{Flag: name, Err: ErrNotParsed}
+	}
+	return instanceFor(fs).setenv(f, pfx[0], value)
+}
+
+// Setenv sets the environment variable of a flag under this Envy's prefix,
+// see SetenvOnFlagSet. It works before the call to Parse.
+func (e *Envy) Setenv(name, value string) error {
+	f := e.fs.Lookup(name)
+	if f == nil {
+		return &SetError{Flag: name, Err: ErrFlagNotExists}
+	}
+	return e.setenv(f, e.prefix, value)
+}
+
+// setenv sets the flag's primary environment variable given its prefix.
+func (e *Envy) setenv(f *pflag.Flag, pfx, value string) error {
+	if _, ok := f.Annotations[AnnotationDisable]; ok {
+		return &SetError{Flag: f.Name, Err: ErrFlagDisabled}
+	}
+	envName := e.envNameFor(pfx, f)
+	if err := os.Setenv(envName, value); err != nil {
+		return &SetError{Flag: f.Name, EnvName: envName, Err: err}
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package envy_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/fernferret/envy"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetenv(t *testing.T) {
+	os.Clearenv()
+	pflag.CommandLine = pflag.NewFlagSet("test", pflag.PanicOnError)
+	url := pflag.String("url", "http://localhost", "set the url")
+	pflag.String("kube-config", "", "kube config")
+	pflag.Bool("once", false, "only once")
+	envy.SetEnvName("kube-config", "KUBECONFIG")
+	envy.Disable("once")
+
+	assert.ErrorIs(t, envy.Setenv("url", "http://example.com"), envy.ErrNotParsed)
+	envy.Parse("FOO")
+
+	assert.NoError(t, envy.Setenv("url", "http://example.com"))
+	assert.NoError(t, envy.Setenv("kube-config", "/etc/kube"))
+	assert.Equal(t, "http://example.com", os.Getenv("FOO_URL"))
+	assert.Equal(t, "/etc/kube", os.Getenv("KUBECONFIG"))
+
+	envy.Parse("FOO")
+	assert.Equal(t, "http://example.com", *url)
+
+	assert.EqualError(t, envy.Setenv("once", "true"), "--once: flag does not read the environment")
+	assert.EqualError(t, envy.Setenv("missing", "x"), "--missing: flag does not exist")
+}
+
+func TestEnvySetenv(t *testing.T) {
+	os.Clearenv()
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	url := fs.String("url", "http://localhost", "set the url")
+	e := envy.New(envy.WithFlagSet(fs), envy.WithPrefix("MYAPP"))
+
+	assert.NoError(t, e.Setenv("url", "http://example.com"))
+	assert.Equal(t, "http://example.com", os.Getenv("MYAPP_URL"))
+	assert.NoError(t, e.ParseE())
+	assert.Equal(t, "http://example.com", *url)
+}
@@ -0,0 +1,107 @@
+package envy
+
+import "github.com/spf13/pflag"
+
+// Origin is where a flag's final value came from.
+type Origin int
+
+const (
+	// FromDefault means the flag still has its default value.
+	FromDefault Origin = iota
+
+	// FromEnv means the value was read from an environment variable.
+	FromEnv
+
+	// FromFlag means the value was given on the command line.
+	FromFlag
+
+	// FromOther means the value was changed some other way, like from code.
+	FromOther
+
+	// FromExperiment means the value came from an Experiment this instance
+	// was picked for.
+	FromExperiment
+
+	// FromFile means the value was read from a config file.
+	FromFile
+)
+
+func (o Origin) String() string {
+	switch o {
+	case FromDefault:
+		return "default"
+	case FromEnv:
+		return "env"
+	case FromFlag:
+		return "flag"
+	case FromExperiment:
+		return "experiment"
+	case FromFile:
+		return "file"
+	}
+	return "other"
+}
+
+// MarshalText writes the Origin by name, so JSON shows "env" rather than 1.
+func (o Origin) MarshalText() ([]byte, error) {
+	return []byte(o.String()), nil
+}
+
+// FlagSource describes where one flag's value came from.
+type FlagSource struct {
+	Flag   string
+	Origin Origin
+
+	// The environment variable that supplied the value, or the first one envy
+	// checked if none were set. Empty for flags envy didn't bind.
+	EnvName string
+
+	// The value, redacted like in PrintSummary.
+	Value string
+
+	// Treatment or Control if the flag has an Experiment, otherwise empty.
+	Experiment string
+
+	// The config file that supplied the value, if any.
+	File string
+}
+
+// Sources reports where every flag in the default pflag.CommandLine got its
+// value, see SourcesFlagSet.
+func Sources() []FlagSource {
+	return SourcesFlagSet(pflag.CommandLine)
+}
+
+// SourcesFlagSet reports where every flag in the given FlagSet got its value,
+// in lexical order by name, answering "why is my app using this URL?" in
+// production. It must be called after pflag.Parse().
+func SourcesFlagSet(fs *pflag.FlagSet) []FlagSource {
+	e := instanceFor(fs)
+	var sources []FlagSource
+	visitAll(fs, func(f *pflag.Flag) {
+		s := FlagSource{Flag: f.Name, Value: displayValue(f), Experiment: assignment(f)}
+		if pfx, ok := f.Annotations[AnnotationBound]; ok {
+			s.EnvName = e.envNameFor(pfx[0], f)
+		}
+		src, fromEnv := f.Annotations[AnnotationSource]
+		file, fromFile := f.Annotations[AnnotationFile]
+		switch {
+		case f.Changed:
+			s.Origin = FromFlag
+		case fromEnv:
+			s.Origin = FromEnv
+		case fromFile:
+			s.Origin = FromFile
+			s.File = file[0]
+		case s.Experiment == Treatment:
+			s.Origin = FromExperiment
+		case f.Value.String() != f.DefValue:
+			s.Origin = FromOther
+		}
+		if fromEnv {
+			s.EnvName = src[0]
+		}
+		sources = append(sources, s)
+	})
+	return sources
+}
@@ -0,0 +1,46 @@
+package envy_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/fernferret/envy"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSources(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("FOO_URL", "http://example.com")
+	os.Setenv("FOO_TOKEN", "hunter2")
+	os.Setenv("KUBECONFIG", "/etc/kube")
+	pflag.CommandLine = pflag.NewFlagSet("test", pflag.PanicOnError)
+
+	pflag.String("url", "http://localhost", "set the url")
+	pflag.String("token", "", "api token")
+	pflag.String("kube-config", "", "kube config")
+	pflag.Int("workers", 4, "number of workers")
+	pflag.Bool("once", false, "only once")
+	pflag.String("name", "", "the name")
+	pflag.Int("retries", 3, "number of retries")
+	envy.Secret("token")
+	envy.SetEnvName("kube-config", "KUBECONFIG")
+	envy.Disable("once")
+	envy.Parse("FOO")
+	pflag.CommandLine.Parse([]string{"--once", "--url=http://flag"})
+	pflag.Set("name", "")
+	pflag.Lookup("workers").Value.Set("8")
+
+	assert.Equal(t, []envy.FlagSource{
+		{Flag: "kube-config", Origin: envy.FromEnv, EnvName: "KUBECONFIG", Value: "/etc/kube"},
+		{Flag: "name", Origin: envy.FromFlag, EnvName: "FOO_NAME"},
+		{Flag: "once", Origin: envy.FromFlag, Value: "true"},
+		{Flag: "retries", Origin: envy.FromDefault, EnvName: "FOO_RETRIES", Value: "3"},
+		{Flag: "token", Origin: envy.FromEnv, EnvName: "FOO_TOKEN", Value: "<redacted>"},
+		{Flag: "url", Origin: envy.FromFlag, EnvName: "FOO_URL", Value: "http://flag"},
+		{Flag: "workers", Origin: envy.FromOther, EnvName: "FOO_WORKERS", Value: "8"},
+	}, envy.Sources())
+
+	assert.Equal(t, "default", envy.FromDefault.String())
+	assert.Equal(t, "env", envy.FromEnv.String())
+}
@@ -0,0 +1,150 @@
+package envy
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+var ErrUnknownEnvName = errors.New("environment variable doesn't match any flag")
+
+// Lister is implemented by Lookupers that can list every variable they hold,
+// which SetStrictPrefix needs.
+type Lister interface {
+	Keys() []string
+}
+
+func (EnvLookuper) Keys() []string {
+	var keys []string
+	for _, kv := range os.Environ() {
+		if key, _, ok := strings.Cut(kv, "="); ok {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+func (m MapLookuper) Keys() []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+func (c Chain) Keys() []string {
+	var keys []string
+	for _, l := range c {
+		if l, ok := l.(Lister); ok {
+			keys = append(keys, l.Keys()...)
+		}
+	}
+	return keys
+}
+
+// SetStrictPrefix makes Parse report every variable starting with the prefix
+// that no flag reads, like a misspelled MYAPP_TIMEOUTT, as a SetError wrapping
+// ErrUnknownEnvName that suggests the closest known name. Like any other
+// mistake it panics, or is logged with ReturnErrors, so use ReturnErrors to
+// only warn. Variables read by something other than a flag, like the one
+// given to Environments, can be allowed by name. Lookupers that don't
+// implement Lister aren't checked. It must be called before the call to
+// envy.Parse().
+func SetStrictPrefix(on bool, allow ...string) {
+	std.strict = on
+	std.strictAllow = allow
+}
+
+// WithStrictPrefix works like SetStrictPrefix for this Envy only.
+func WithStrictPrefix(on bool, allow ...string) Option {
+	return func(e *Envy) {
+		e.strict = on
+		e.strictAllow = allow
+	}
+}
+
+// unknownNames reports every variable under the prefix that isn't known.
+func (e *Envy) unknownNames() ParseErrors {
+	l, ok := e.lookuper.(Lister)
+	if !ok || e.prefix == "" {
+		return nil
+	}
+	known := e.knownNames()
+	var errs ParseErrors
+	keys := l.Keys()
+	sort.Strings(keys)
+	for i, key := range keys {
+		if !strings.HasPrefix(key, e.prefix) || known[key] || i > 0 && keys[i-1] == key {
+			continue
+		}
+		err := ErrUnknownEnvName
+		if guess := closestName(key, known); guess != "" {
+			err = fmt.Errorf("%w, did you mean %s?", ErrUnknownEnvName, guess)
+		}
+		errs = append(errs, &SetError{EnvName: key, Err: err})
+	}
+	return errs
+}
+
+// knownNames returns every variable envy reads for the FlagSet.
+func (e *Envy) knownNames() map[string]bool {
+	known := map[string]bool{e.prefix + "MODULES": true}
+	for _, name := range append(e.strictAllow, e.gates...) {
+		known[envKey(name)] = true
+	}
+	visitAll(e.fs, func(f *pflag.Flag) {
+		if _, ok := f.Annotations[AnnotationDisable]; ok {
+			return
+		}
+		for _, name := range append(e.boundNames(f), e.envNamesFor(e.prefix, f)...) {
+			known[name] = true
+			if e.fileSuffixFor(f) {
+				known[name+"_FILE"] = true
+			}
+		}
+	})
+	return known
+}
+
+// closestName returns the known name a misspelled one was most likely meant
+// to be, or nothing if none are close.
+func closestName(name string, known map[string]bool) string {
+	best, bestDist := "", 3
+	for k := range known {
+		if d := editDistance(name, k); d < bestDist || d == bestDist && best != "" && k < best {
+			best, bestDist = k, d
+		}
+	}
+	return best
+}
+
+// editDistance returns the Levenshtein distance between two strings.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = prev[j-1] + cost
+			if d := prev[j] + 1; d < cur[j] {
+				cur[j] = d
+			}
+			if d := cur[j-1] + 1; d < cur[j] {
+				cur[j] = d
+			}
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
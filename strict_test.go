@@ -0,0 +1,50 @@
+package envy_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/fernferret/envy"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStrictPrefix(t *testing.T) {
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	timeout := fs.Duration("timeout", 0, "request timeout")
+	fs.String("password", "", "password")
+	fs.Bool("once", false, "run once")
+	envy.FileSuffixOnFlagSet("password", fs)
+	envy.DisableOnFlagSet("once", fs)
+
+	env := envy.MapLookuper{
+		"MYAPP_TIMEOUT":       "1m",
+		"MYAPP_TIMEOUTT":      "2m",
+		"MYAPP_PASSWORD_FILE": "/dev/null",
+		"MYAPP_ONCE":          "true",
+		"MYAPP_ENV":           "dev",
+		"MYAPP_SOMETHING":     "else",
+		"OTHER_TIMEOUTT":      "3m",
+	}
+	e := envy.New(envy.WithFlagSet(fs), envy.WithPrefix("MYAPP"), envy.WithLookuper(env),
+		envy.WithStrictPrefix(true, "MYAPP_ENV"))
+
+	var errs envy.ParseErrors
+	if !errors.As(e.ParseE(), &errs) {
+		t.Fatal("expected ParseErrors")
+	}
+	assert.Equal(t, "1m0s", timeout.String())
+	assert.EqualError(t, errs, "MYAPP_ONCE: "+envy.ErrUnknownEnvName.Error()+"\n"+
+		"MYAPP_SOMETHING: "+envy.ErrUnknownEnvName.Error()+"\n"+
+		"MYAPP_TIMEOUTT: "+envy.ErrUnknownEnvName.Error()+", did you mean MYAPP_TIMEOUT?")
+	for _, err := range errs {
+		assert.ErrorIs(t, err, envy.ErrUnknownEnvName)
+	}
+}
+
+func TestStrictPrefixOff(t *testing.T) {
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	fs.Duration("timeout", 0, "request timeout")
+	env := envy.MapLookuper{"MYAPP_TIMEOUTT": "2m"}
+	assert.NoError(t, envy.New(envy.WithFlagSet(fs), envy.WithPrefix("MYAPP"), envy.WithLookuper(env)).ParseE())
+}
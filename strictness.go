@@ -0,0 +1,50 @@
+package envy
+
+import "github.com/spf13/pflag"
+
+// Strictness controls how envy reports mistakes, see SetStrictness.
+type Strictness int
+
+const (
+	// PanicOnErrors panics on the first mistake, which is the default.
+	PanicOnErrors Strictness = iota
+
+	// ReturnErrors queues mistakes so they are returned by ParseE, or logged
+	// by Parse and BindLate, rather than panicking.
+	ReturnErrors
+)
+
+// The errors queued for each FlagSet's next parse.
+var queued registry[*pflag.FlagSet, ParseErrors]
+
+// SetStrictness controls whether mistakes like unknown flag names, duplicate
+// custom environment variables or invalid values panic, or are collected and
+// returned by ParseE, for long running servers embedding envy where a panic is
+// unacceptable. It must be called before any other envy call.
+func SetStrictness(s Strictness) {
+	std.strictness = s
+}
+
+// fail panics with the error, or queues it for the FlagSet's next parse when
+// using ReturnErrors.
+func (e *Envy) fail(err *SetError) {
+	if e.strictness == PanicOnErrors {
+		panic(err)
+	}
+	queued.update(e.fs, func(errs ParseErrors) ParseErrors {
+		return append(errs, err)
+	})
+}
+
+// report panics with the first error or logs them all when using ReturnErrors.
+func (e *Envy) report(errs ParseErrors) {
+	if len(errs) == 0 {
+		return
+	}
+	if e.strictness == PanicOnErrors {
+		panic(errs[0])
+	}
+	for _, err := range errs {
+		logf("%v", err)
+	}
+}
@@ -0,0 +1,60 @@
+package envy_test
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/fernferret/envy"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetStrictness(t *testing.T) {
+	defer envy.SetStrictness(envy.PanicOnErrors)
+	envy.SetStrictness(envy.ReturnErrors)
+
+	os.Clearenv()
+	os.Setenv("FOO_VERBOSE", "yes")
+	fs := pflag.NewFlagSet("test", pflag.PanicOnError)
+	fs.Bool("verbose", false, "verbose usage")
+	fs.String("kube-config", "", "kube config")
+
+	assert.NotPanics(t, func() {
+		envy.DisableOnFlagSet("missing", fs)
+		envy.SecretOnFlagSet("nope", fs)
+		envy.SetEnvNameOnFlagSet("kube-config", "KUBECONFIG", fs)
+		envy.SetEnvNameOnFlagSet("kube-config", "KUBECONFIG", fs)
+	})
+
+	err := envy.ParseFlagSetE("FOO", fs)
+	assert.EqualError(t, err, `--missing: flag does not exist
+--nope: flag does not exist
+--kube-config: custom flag already exists
+--verbose from FOO_VERBOSE: bool flag got value that was't 'true' or 'false'`)
+
+	// The queue is emptied by each parse
+	os.Clearenv()
+	assert.NoError(t, envy.ParseFlagSetE("FOO", fs))
+}
+
+func TestSetStrictnessLogs(t *testing.T) {
+	defer envy.SetStrictness(envy.PanicOnErrors)
+	defer envy.SetOutput(os.Stderr)
+	envy.SetStrictness(envy.ReturnErrors)
+	buf := &bytes.Buffer{}
+	envy.SetOutput(buf)
+
+	os.Clearenv()
+	os.Setenv("FOO_COUNT", "many")
+	fs := pflag.NewFlagSet("test", pflag.PanicOnError)
+	fs.Int("count", 1, "count usage")
+	envy.DisableOnFlagSet("missing", fs)
+
+	assert.NotPanics(t, func() { envy.ParseFlagSet("FOO", fs) })
+	assert.NotPanics(t, func() { envy.BindLate(fs, "late") })
+	assert.Equal(t, `envy: --missing: flag does not exist
+envy: --count from FOO_COUNT: strconv.ParseInt: parsing "many": invalid syntax
+envy: --late: flag does not exist
+`, buf.String())
+}
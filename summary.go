@@ -0,0 +1,77 @@
+package envy
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/spf13/pflag"
+)
+
+// Shown in place of the value of any flag marked with Secret.
+const redacted = "<redacted>"
+
+// PrintSummary writes a compact banner of every flag in the default
+// pflag.CommandLine that isn't using its default value, along with where the
+// value came from. It's meant for the first few lines of a service's logs and
+// must be called after pflag.Parse().
+func PrintSummary(w io.Writer) {
+	PrintSummaryFlagSet(w, pflag.CommandLine)
+}
+
+// PrintSummaryFlagSet writes a compact banner of every flag in the given
+// pflag.FlagSet that isn't using its default value, along with where the value
+// came from. Values of flags marked with Secret are redacted. It must be called
+// after pflag.Parse().
+func PrintSummaryFlagSet(w io.Writer, fs *pflag.FlagSet) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	visitAll(fs, func(f *pflag.Flag) {
+		if f.Value.String() == f.DefValue {
+			return
+		}
+		fmt.Fprintf(tw, "--%s\t%s\t(%s)\n", f.Name, displayValue(f), source(f))
+	})
+	tw.Flush()
+}
+
+// Redactor can be implemented by a pflag.Value holding partially sensitive
+// data, like a URL with a password, to control how envy prints it.
+type Redactor interface {
+	Redacted() string
+}
+
+// displayValue returns the flag's value, or a placeholder if it's a secret or
+// its hash if it was marked with Hashed.
+func displayValue(f *pflag.Flag) string {
+	if isSecret(f) {
+		return redacted
+	}
+	if isHashed(f) {
+		return hashValue(f.Value.String())
+	}
+	if r, ok := valueOf(f).(Redactor); ok {
+		return r.Redacted()
+	}
+	return f.Value.String()
+}
+
+// isRedactor reports whether the flag's value implements Redactor.
+func isRedactor(f *pflag.Flag) bool {
+	_, ok := valueOf(f).(Redactor)
+	return ok
+}
+
+// source describes where the flag's current value came from. A value set on
+// the command line always wins, so it's checked first.
+func source(f *pflag.Flag) string {
+	if f.Changed {
+		return "flag"
+	}
+	if val, ok := f.Annotations[AnnotationSource]; ok {
+		return fmt.Sprintf("env %s", val[0])
+	}
+	if val, ok := f.Annotations[AnnotationFile]; ok {
+		return fmt.Sprintf("file %s", val[0])
+	}
+	return "other"
+}
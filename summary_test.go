@@ -0,0 +1,76 @@
+package envy_test
+
+import (
+	"bytes"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/fernferret/envy"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrintSummarySecret(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("FOO_TOKEN", "hunter2")
+	pflag.CommandLine = pflag.NewFlagSet("test", pflag.PanicOnError)
+
+	pflag.String("token", "", "api token")
+	envy.Secret("token")
+	envy.Parse("FOO")
+
+	assert.Equal(t, "api token [FOO_TOKEN]", pflag.Lookup("token").Usage)
+	assert.Equal(t, "hunter2", pflag.Lookup("token").Value.String())
+
+	buf := &bytes.Buffer{}
+	envy.PrintSummary(buf)
+	assert.Equal(t, "--token  <redacted>  (env FOO_TOKEN)\n", buf.String())
+}
+
+func TestPrintSummaryUnsorted(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("FOO_ZONE", "us-east")
+	os.Setenv("FOO_ADDR", ":8080")
+	fs := pflag.NewFlagSet("test", pflag.PanicOnError)
+	fs.SortFlags = false
+
+	fs.String("zone", "", "the zone")
+	fs.String("addr", "", "the address")
+	envy.ParseFlagSet("FOO", fs)
+
+	buf := &bytes.Buffer{}
+	envy.PrintSummaryFlagSet(buf, fs)
+	assert.Equal(t, "--addr  :8080    (env FOO_ADDR)\n--zone  us-east  (env FOO_ZONE)\n", buf.String())
+}
+
+func TestSecretNonexistantFlag(t *testing.T) {
+	pflag.CommandLine = pflag.NewFlagSet("test", pflag.PanicOnError)
+
+	pflag.Bool("verbose", false, "test flag")
+
+	assert.Panics(t, func() { envy.Secret("foo") })
+}
+
+func ExamplePrintSummary() {
+	// Reset CommandLine flags for example, you don't need this in your code!
+	pflag.CommandLine = pflag.NewFlagSet("test", pflag.PanicOnError)
+	os.Clearenv()
+
+	// Define a few flags
+	pflag.String("url", "http://localhost:8080", "set the url")
+	pflag.Bool("once", false, "only run processing once")
+	pflag.Duration("interval", time.Minute, "interval to check widgets")
+
+	// Simulate FOO_URL being set
+	os.Setenv("FOO_URL", "https://example.com")
+
+	envy.Parse("FOO")
+
+	// Simulate passing --once on the command line
+	pflag.CommandLine.Parse([]string{"--once"})
+
+	envy.PrintSummary(os.Stdout)
+	// Output: --once  true                 (flag)
+	// --url   https://example.com  (env FOO_URL)
+}
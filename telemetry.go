@@ -0,0 +1,45 @@
+package envy
+
+import "github.com/spf13/pflag"
+
+// Overridden is one flag whose default was overridden by an environment
+// variable or config file.
+type Overridden struct {
+	Flag   string
+	Origin Origin
+}
+
+// TelemetryFunc receives the flags overridden by each Parse. It only gets flag
+// names and where the value came from, never values or variable names.
+type TelemetryFunc func(overrides []Overridden)
+
+// SetTelemetry opts in to reporting which flags are overridden, so product
+// teams can count across a fleet which defaults everybody changes and should
+// be changed instead. Envy has no network code, fn decides where the counts
+// go. It's called at the end of every Parse with the overridden flags in
+// lexical order, and passing nil turns it off. It must be called before the
+// call to envy.Parse().
+func SetTelemetry(fn TelemetryFunc) {
+	std.telemetry = fn
+}
+
+// WithTelemetry works like SetTelemetry for this Envy only.
+func WithTelemetry(fn TelemetryFunc) Option {
+	return func(e *Envy) {
+		e.telemetry = fn
+	}
+}
+
+// overrides returns every flag envy set from an environment variable or
+// config file.
+func (e *Envy) overrides() []Overridden {
+	overrides := []Overridden{}
+	visitAll(e.fs, func(f *pflag.Flag) {
+		if _, ok := f.Annotations[AnnotationSource]; ok {
+			overrides = append(overrides, Overridden{Flag: f.Name, Origin: FromEnv})
+		} else if _, ok := f.Annotations[AnnotationFile]; ok {
+			overrides = append(overrides, Overridden{Flag: f.Name, Origin: FromFile})
+		}
+	})
+	return overrides
+}
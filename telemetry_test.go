@@ -0,0 +1,47 @@
+package envy_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/fernferret/envy"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithTelemetry(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("MYAPP_URL", "http://example.com")
+	os.Setenv("MYAPP_TOKEN", "hunter2")
+	path := writeFile(t, "config.yaml", "workers: 8\n")
+
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	fs.String("url", "", "set the url")
+	fs.String("token", "", "api token")
+	fs.Int("workers", 4, "number of workers")
+	fs.Bool("once", false, "only once")
+
+	var got [][]envy.Overridden
+	e := envy.New(envy.WithPrefix("MYAPP"), envy.WithFlagSet(fs), envy.WithYAMLFile(path), envy.WithTelemetry(func(o []envy.Overridden) {
+		got = append(got, o)
+	}))
+	assert.NoError(t, e.ParseE())
+	assert.Equal(t, [][]envy.Overridden{{
+		{Flag: "token", Origin: envy.FromEnv},
+		{Flag: "url", Origin: envy.FromEnv},
+		{Flag: "workers", Origin: envy.FromFile},
+	}}, got)
+}
+
+func TestSetTelemetry(t *testing.T) {
+	defer envy.SetTelemetry(nil)
+
+	os.Clearenv()
+	pflag.CommandLine = pflag.NewFlagSet("test", pflag.PanicOnError)
+	pflag.String("url", "", "set the url")
+
+	var got []envy.Overridden
+	envy.SetTelemetry(func(o []envy.Overridden) { got = o })
+	envy.Parse("MYAPP")
+	assert.Equal(t, []envy.Overridden{}, got)
+}
@@ -0,0 +1,55 @@
+package envy
+
+import (
+	"text/template"
+
+	"github.com/spf13/pflag"
+)
+
+// templateValue compiles its value into a text/template.
+type templateValue struct {
+	p     **template.Template
+	name  string
+	raw   string
+	funcs []template.FuncMap
+}
+
+func (v *templateValue) Set(val string) error {
+	t := template.New(v.name)
+	for _, funcs := range v.funcs {
+		t = t.Funcs(funcs)
+	}
+	t, err := t.Parse(val)
+	if err != nil {
+		return err
+	}
+	*v.p = t
+	v.raw = val
+	return nil
+}
+
+func (v *templateValue) String() string {
+	return v.raw
+}
+
+func (v *templateValue) Type() string {
+	return "template"
+}
+
+// TemplateVar defines a template flag on the default pflag.CommandLine, see
+// TemplateVarOnFlagSet.
+func TemplateVar(p **template.Template, name, value, usage string, funcs ...template.FuncMap) {
+	TemplateVarOnFlagSet(p, name, value, usage, pflag.CommandLine, funcs...)
+}
+
+// TemplateVarOnFlagSet defines a flag, like --output-format, whose value is
+// compiled as a text/template with any given funcs. Templates from the
+// environment are compiled when envy parses the FlagSet so syntax errors are
+// reported against the variable. It panics if the default doesn't compile.
+func TemplateVarOnFlagSet(p **template.Template, name, value, usage string, fs *pflag.FlagSet, funcs ...template.FuncMap) {
+	v := &templateValue{p: p, name: name, funcs: funcs}
+	if err := v.Set(value); err != nil {
+		panic(err)
+	}
+	fs.Var(v, name, usage)
+}
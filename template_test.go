@@ -0,0 +1,51 @@
+package envy_test
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+	"text/template"
+
+	"github.com/fernferret/envy"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTemplateVar(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("FOO_OUTPUT_FORMAT", "{{ upper .Name }}")
+	pflag.CommandLine = pflag.NewFlagSet("test", pflag.PanicOnError)
+
+	var tmpl *template.Template
+	envy.TemplateVar(&tmpl, "output-format", "{{ .Name }}", "output template", template.FuncMap{"upper": strings.ToUpper})
+	envy.Parse("FOO")
+
+	buf := &bytes.Buffer{}
+	assert.NoError(t, tmpl.Execute(buf, struct{ Name string }{"envy"}))
+	assert.Equal(t, "ENVY", buf.String())
+	assert.Equal(t, "{{ .Name }}", pflag.Lookup("output-format").DefValue)
+	assert.Equal(t, "{{ upper .Name }}", pflag.Lookup("output-format").Value.String())
+}
+
+func TestTemplateVarInvalidEnv(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("FOO_OUTPUT_FORMAT", "{{ .Name ")
+	pflag.CommandLine = pflag.NewFlagSet("test", pflag.PanicOnError)
+
+	var tmpl *template.Template
+	envy.TemplateVar(&tmpl, "output-format", "{{ .Name }}", "output template")
+
+	defer func() {
+		err, _ := recover().(error)
+		assert.ErrorContains(t, err, "FOO_OUTPUT_FORMAT: template: output-format:1")
+	}()
+	envy.Parse("FOO")
+}
+
+func TestTemplateVarInvalidDefault(t *testing.T) {
+	var tmpl *template.Template
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+
+	assert.Panics(t, func() { envy.TemplateVarOnFlagSet(&tmpl, "output-format", "{{ nope }}", "output template", fs) })
+}
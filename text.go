@@ -0,0 +1,54 @@
+package envy
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+// textValue adapts any encoding.TextUnmarshaler, like netip.Addr, to a
+// pflag.Value.
+type textValue struct {
+	p encoding.TextUnmarshaler
+}
+
+func (v *textValue) Set(val string) error {
+	return v.p.UnmarshalText([]byte(val))
+}
+
+func (v *textValue) UnmarshalText(text []byte) error {
+	return v.p.UnmarshalText(text)
+}
+
+func (v *textValue) String() string {
+	if m, ok := v.p.(encoding.TextMarshaler); ok {
+		if text, err := m.MarshalText(); err == nil {
+			return string(text)
+		}
+	}
+	return fmt.Sprint(reflect.ValueOf(v.p).Elem().Interface())
+}
+
+func (v *textValue) Type() string {
+	return strings.ToLower(reflect.TypeOf(v.p).Elem().Name())
+}
+
+// setValue sets the flag from an environment variable or config file. Values
+// implementing encoding.TextUnmarshaler are decoded through it, so types that
+// validate their text, like netip.Addr or enums, report why a value was
+// rejected. Slices are replaced rather than appended to, see MergeSlice.
+func setValue(f *pflag.Flag, val string) error {
+	v := valueOf(f)
+	if s, ok := v.(pflag.SliceValue); ok {
+		return setSlice(f, s, val)
+	}
+	return guarded(f.Value, []string{val}, func() error {
+		if u, ok := v.(encoding.TextUnmarshaler); ok {
+			return u.UnmarshalText([]byte(val))
+		}
+		return v.Set(val)
+	})
+}
@@ -0,0 +1,83 @@
+package envy_test
+
+import (
+	"errors"
+	"net/netip"
+	"strings"
+	"testing"
+
+	"github.com/fernferret/envy"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+)
+
+// color is a pflag.Value whose Set is lenient but UnmarshalText validates.
+type color string
+
+func (c *color) Set(val string) error {
+	*c = color(val)
+	return nil
+}
+
+func (c *color) UnmarshalText(text []byte) error {
+	switch val := strings.ToLower(string(text)); val {
+	case "red", "green", "blue":
+		*c = color(val)
+		return nil
+	}
+	return errors.New("must be red, green or blue")
+}
+
+func (c *color) String() string {
+	return string(*c)
+}
+
+func (c *color) Type() string {
+	return "color"
+}
+
+func TestTextUnmarshaler(t *testing.T) {
+	tests := []struct {
+		name     string
+		env      envy.MapLookuper
+		expected string
+		errMsg   string
+	}{
+		{"valid", envy.MapLookuper{"FOO_COLOR": "RED"}, "red", ""},
+		{"invalid", envy.MapLookuper{"FOO_COLOR": "purple"}, "green", "--color from FOO_COLOR: must be red, green or blue"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c := color("green")
+			fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+			fs.Var(&c, "color", "color")
+			err := envy.New(envy.WithFlagSet(fs), envy.WithPrefix("FOO"), envy.WithLookuper(test.env)).ParseE()
+			if test.errMsg != "" {
+				assert.EqualError(t, err, test.errMsg)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, color(test.expected), c)
+		})
+	}
+}
+
+func TestBindTextUnmarshaler(t *testing.T) {
+	var opts struct {
+		Addr netip.Addr `default:"127.0.0.1" usage:"address"`
+		Peer netip.AddrPort
+	}
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	assert.NoError(t, envy.BindOnFlagSet(&opts, fs))
+	assert.Equal(t, "127.0.0.1", fs.Lookup("addr").DefValue)
+	assert.Equal(t, "addr", fs.Lookup("addr").Value.Type())
+	assert.Equal(t, "", fs.Lookup("peer").DefValue)
+
+	env := envy.MapLookuper{"FOO_ADDR": "::1", "FOO_PEER": "10.0.0.1"}
+	err := envy.New(envy.WithFlagSet(fs), envy.WithPrefix("FOO"), envy.WithLookuper(env)).ParseE()
+	assert.EqualError(t, err, "--peer from FOO_PEER: not an ip:port")
+	assert.Equal(t, netip.MustParseAddr("::1"), opts.Addr)
+
+	assert.NoError(t, fs.Parse([]string{"--peer=10.0.0.1:80"}))
+	assert.Equal(t, netip.MustParseAddrPort("10.0.0.1:80"), opts.Peer)
+}
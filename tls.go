@@ -0,0 +1,76 @@
+package envy
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/spf13/pflag"
+)
+
+var (
+	ErrTLSCertKeyPair = errors.New("--tls-cert and --tls-key must be set together")
+	ErrTLSInvalidCA   = errors.New("no certificates found in --tls-ca")
+)
+
+// TLSFiles holds the paths set by the flags from TLSFlags.
+type TLSFiles struct {
+	CertFile string
+	KeyFile  string
+	CAFile   string
+}
+
+// TLSFlags defines --tls-cert, --tls-key and --tls-ca on the default
+// pflag.CommandLine, see TLSFlagsOnFlagSet.
+func TLSFlags() *TLSFiles {
+	return TLSFlagsOnFlagSet(pflag.CommandLine)
+}
+
+// TLSFlagsOnFlagSet defines --tls-cert, --tls-key and --tls-ca on the given
+// FlagSet, which envy binds like any other flag. Use BuildTLSConfig once the
+// flags have been parsed.
+func TLSFlagsOnFlagSet(fs *pflag.FlagSet) *TLSFiles {
+	t := &TLSFiles{}
+	fs.StringVar(&t.CertFile, "tls-cert", "", "path to a PEM encoded TLS certificate, requires --tls-key")
+	fs.StringVar(&t.KeyFile, "tls-key", "", "path to the PEM encoded key for --tls-cert")
+	fs.StringVar(&t.CAFile, "tls-ca", "", "path to PEM encoded CA certificates to trust")
+	return t
+}
+
+// BuildTLSConfig loads the configured files into a tls.Config. If no files
+// were given it returns nil, so callers can use plain connections. The CA
+// bundle, if any, is used for both RootCAs and ClientCAs, callers acting as a
+// server should set ClientAuth themselves to require client certificates.
+func (t *TLSFiles) BuildTLSConfig() (*tls.Config, error) {
+	if (t.CertFile == "") != (t.KeyFile == "") {
+		return nil, ErrTLSCertKeyPair
+	}
+	if t.CertFile == "" && t.CAFile == "" {
+		return nil, nil
+	}
+
+	cfg := &tls.Config{MinVersion: tls.VersionTLS12}
+	if t.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(t.CertFile, t.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+
+	if t.CAFile != "" {
+		data, err := os.ReadFile(t.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(data) {
+			return nil, fmt.Errorf("%w: %s", ErrTLSInvalidCA, t.CAFile)
+		}
+		cfg.RootCAs = pool
+		cfg.ClientCAs = pool
+	}
+	return cfg, nil
+}
@@ -0,0 +1,128 @@
+package envy_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/fernferret/envy"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+)
+
+// writeTestCert writes a self signed certificate and key into dir.
+func writeTestCert(t *testing.T, dir string) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "envy"},
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	certFile := filepath.Join(dir, "tls.crt")
+	keyFile := filepath.Join(dir, "tls.key")
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return certFile, keyFile
+}
+
+func TestTLSFlags(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := writeTestCert(t, dir)
+
+	os.Clearenv()
+	os.Setenv("FOO_TLS_CERT", certFile)
+	os.Setenv("FOO_TLS_KEY", keyFile)
+	os.Setenv("FOO_TLS_CA", certFile)
+	pflag.CommandLine = pflag.NewFlagSet("test", pflag.PanicOnError)
+
+	files := envy.TLSFlags()
+	envy.Parse("FOO")
+	pflag.Parse()
+
+	cfg, err := files.BuildTLSConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Len(t, cfg.Certificates, 1)
+	assert.NotNil(t, cfg.RootCAs)
+	assert.NotNil(t, cfg.ClientCAs)
+}
+
+func TestTLSFilesBuildTLSConfig(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := writeTestCert(t, dir)
+
+	tests := []struct {
+		name  string
+		files envy.TLSFiles
+		empty bool
+		err   error
+	}{
+		{
+			name:  "test nothing set",
+			empty: true,
+		},
+		{
+			name:  "test cert and key",
+			files: envy.TLSFiles{CertFile: certFile, KeyFile: keyFile},
+		},
+		{
+			name:  "test only ca",
+			files: envy.TLSFiles{CAFile: certFile},
+		},
+		{
+			name:  "test cert without key",
+			files: envy.TLSFiles{CertFile: certFile},
+			err:   envy.ErrTLSCertKeyPair,
+		},
+		{
+			name:  "test key without cert",
+			files: envy.TLSFiles{KeyFile: keyFile},
+			err:   envy.ErrTLSCertKeyPair,
+		},
+		{
+			name:  "test key is not a ca",
+			files: envy.TLSFiles{CAFile: keyFile},
+			err:   envy.ErrTLSInvalidCA,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := tt.files.BuildTLSConfig()
+			if tt.err != nil {
+				assert.ErrorIs(t, err, tt.err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.empty, cfg == nil)
+		})
+	}
+}
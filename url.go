@@ -0,0 +1,98 @@
+package envy
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+var ErrInvalidURL = errors.New("invalid url")
+
+// URLOption restricts the values accepted by URLVar.
+type URLOption func(*urlValue)
+
+// Schemes restricts the URL to the given schemes, compared case insensitively.
+func Schemes(schemes ...string) URLOption {
+	return func(v *urlValue) {
+		v.schemes = schemes
+	}
+}
+
+// urlValue parses into a url.URL, requiring a scheme and host.
+type urlValue struct {
+	u       *url.URL
+	schemes []string
+}
+
+func (v *urlValue) Set(val string) error {
+	u, err := url.Parse(strings.TrimSpace(val))
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidURL, err)
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return fmt.Errorf("%w: %q must include a scheme and host", ErrInvalidURL, val)
+	}
+	if len(v.schemes) > 0 && !containsFold(v.schemes, u.Scheme) {
+		return fmt.Errorf("%w: scheme %q must be one of %s", ErrInvalidURL, u.Scheme, strings.Join(v.schemes, ", "))
+	}
+
+	// Trailing slashes make joining paths later error prone.
+	u.Path = strings.TrimRight(u.Path, "/")
+	u.RawPath = strings.TrimRight(u.RawPath, "/")
+	*v.u = *u
+	return nil
+}
+
+func (v *urlValue) String() string {
+	if v.u == nil {
+		return ""
+	}
+	return v.u.String()
+}
+
+func (v *urlValue) Type() string {
+	return "url"
+}
+
+// Redacted hides any password in the URL.
+func (v *urlValue) Redacted() string {
+	if v.u == nil {
+		return ""
+	}
+	return v.u.Redacted()
+}
+
+// URLVar defines a URL flag on the default pflag.CommandLine, see
+// URLVarOnFlagSet.
+func URLVar(p *url.URL, name, value, usage string, opts ...URLOption) {
+	URLVarOnFlagSet(p, name, value, usage, pflag.CommandLine, opts...)
+}
+
+// URLVarOnFlagSet defines a flag that parses into the given url.URL. Values
+// must include a scheme and host and any trailing slashes are removed from the
+// path. It panics if the default value isn't valid.
+func URLVarOnFlagSet(p *url.URL, name, value, usage string, fs *pflag.FlagSet, opts ...URLOption) {
+	v := &urlValue{u: p}
+	for _, opt := range opts {
+		opt(v)
+	}
+	if value != "" {
+		if err := v.Set(value); err != nil {
+			panic(err)
+		}
+	}
+	fs.Var(v, name, usage)
+}
+
+// containsFold reports whether the list contains the item, ignoring case.
+func containsFold(list []string, item string) bool {
+	for _, v := range list {
+		if strings.EqualFold(v, item) {
+			return true
+		}
+	}
+	return false
+}
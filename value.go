@@ -0,0 +1,59 @@
+package envy
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/spf13/pflag"
+)
+
+// genericValue adapts a parse function to a pflag.Value.
+type genericValue[T any] struct {
+	p     *T
+	parse func(string) (T, error)
+}
+
+func (v *genericValue[T]) Set(val string) error {
+	parsed, err := v.parse(val)
+	if err != nil {
+		return err
+	}
+	*v.p = parsed
+	return nil
+}
+
+func (v *genericValue[T]) String() string {
+	return fmt.Sprint(*v.p)
+}
+
+// Type names the type with its package, like netip.Addr. Predeclared types
+// like int would clash with pflag's own type names, which envy relies on to
+// tell how to read a value, so they're named after this flag kind instead.
+func (v *genericValue[T]) Type() string {
+	t := reflect.TypeOf(v.p).Elem()
+	if t.PkgPath() == "" {
+		return "envy.Value[" + t.String() + "]"
+	}
+	return t.String()
+}
+
+// Value defines a flag of any type on the default pflag.CommandLine, see
+// ValueOnFlagSet.
+func Value[T any](name string, def T, usage string, parse func(string) (T, error)) *T {
+	return ValueOnFlagSet(name, def, usage, parse, pflag.CommandLine)
+}
+
+// ValueOnFlagSet defines a flag of any type on the given FlagSet and returns a
+// pointer to its value. Values from the command line and, once envy parses the
+// FlagSet, the environment are converted with parse, so validation lives in
+// the same place as the flag definition:
+//
+//	port := envy.Value("port", 8080, "port to listen on", func(s string) (int, error) {
+//		return strconv.Atoi(s)
+//	})
+func ValueOnFlagSet[T any](name string, def T, usage string, parse func(string) (T, error), fs *pflag.FlagSet) *T {
+	p := new(T)
+	*p = def
+	fs.Var(&genericValue[T]{p: p, parse: parse}, name, usage)
+	return p
+}
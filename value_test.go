@@ -0,0 +1,57 @@
+package envy_test
+
+import (
+	"errors"
+	"net/netip"
+	"os"
+	"strconv"
+	"testing"
+
+	"github.com/fernferret/envy"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValue(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("FOO_GATEWAY", "10.0.0.1")
+	pflag.CommandLine = pflag.NewFlagSet("test", pflag.PanicOnError)
+
+	gateway := envy.Value("gateway", netip.MustParseAddr("192.168.0.1"), "default gateway", netip.ParseAddr)
+	port := envy.Value("port", 8080, "port to listen on", func(s string) (int, error) {
+		port, err := strconv.Atoi(s)
+		if err == nil && (port < 1 || port > 65535) {
+			err = errors.New("port out of range")
+		}
+		return port, err
+	})
+
+	flag := pflag.Lookup("gateway")
+	assert.Equal(t, "192.168.0.1", flag.DefValue)
+	assert.Equal(t, "netip.Addr", flag.Value.Type())
+
+	envy.Parse("FOO")
+
+	assert.Equal(t, netip.MustParseAddr("10.0.0.1"), *gateway)
+	assert.Equal(t, "default gateway [FOO_GATEWAY 10.0.0.1]", flag.Usage)
+
+	assert.Error(t, pflag.Set("port", "70000"))
+	assert.Equal(t, 8080, *port)
+	assert.NoError(t, pflag.Set("port", "9090"))
+	assert.Equal(t, 9090, *port)
+}
+
+func TestValuePredeclaredType(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("FOO_DEBUG", "yes")
+	pflag.CommandLine = pflag.NewFlagSet("test", pflag.PanicOnError)
+
+	// The parse function decides what a bool looks like, not envy
+	debug := envy.Value("debug", false, "debug output", func(s string) (bool, error) {
+		return s == "yes", nil
+	})
+	assert.Equal(t, "envy.Value[bool]", pflag.Lookup("debug").Value.Type())
+
+	envy.Parse("FOO")
+	assert.True(t, *debug)
+}
@@ -0,0 +1,199 @@
+// Package vault reads envy flags from HashiCorp Vault's KV version 2 secrets
+// engine. It talks to Vault's HTTP API directly so using it doesn't pull in
+// Vault's client library:
+//
+//	client, err := vault.LoginAppRole(ctx, "https://vault:8200", roleID, secretID)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	secrets := vault.NewLookuper(client, "secret/data/myapp")
+//	envy.SetLookuper(envy.Chain{envy.EnvLookuper{}, secrets})
+//	envy.SetSecretPath("db-password", "secret/data/db#password")
+//	envy.Parse("MYAPP")
+//	if err := secrets.Err(); err != nil {
+//		log.Fatal(err)
+//	}
+package vault
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	ErrNotFound   = errors.New("secret not found")
+	ErrPermission = errors.New("permission denied")
+)
+
+// Client is a minimal Vault API client authenticated with a token.
+type Client struct {
+	// Addr is Vault's address, like https://vault:8200.
+	Addr  string
+	Token string
+
+	// Namespace is sent as X-Vault-Namespace if set, for Vault Enterprise.
+	Namespace string
+
+	// HTTPClient defaults to a client with a 10 second timeout.
+	HTTPClient *http.Client
+}
+
+// NewClient returns a Client using the given token.
+func NewClient(addr, token string) *Client {
+	return &Client{Addr: addr, Token: token}
+}
+
+// LoginAppRole logs in with AppRole auth mounted at approle/ and returns a
+// Client using the issued token.
+func LoginAppRole(ctx context.Context, addr, roleID, secretID string) (*Client, error) {
+	c := NewClient(addr, "")
+	body, err := json.Marshal(map[string]string{"role_id": roleID, "secret_id": secretID})
+	if err != nil {
+		return nil, err
+	}
+	var resp struct {
+		Auth struct {
+			ClientToken string `json:"client_token"`
+		} `json:"auth"`
+	}
+	if err := c.do(ctx, http.MethodPost, "auth/approle/login", body, &resp); err != nil {
+		return nil, fmt.Errorf("approle login: %w", err)
+	}
+	c.Token = resp.Auth.ClientToken
+	return c, nil
+}
+
+// Read returns the fields of the latest version of a KV version 2 secret, the
+// path includes the mount and data/, like secret/data/myapp.
+func (c *Client) Read(ctx context.Context, path string) (map[string]interface{}, error) {
+	var resp struct {
+		Data struct {
+			Data map[string]interface{} `json:"data"`
+		} `json:"data"`
+	}
+	if err := c.do(ctx, http.MethodGet, strings.Trim(path, "/"), nil, &resp); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return resp.Data.Data, nil
+}
+
+// do sends a request to the Vault API and decodes the JSON response.
+func (c *Client) do(ctx context.Context, method, path string, body []byte, out interface{}) error {
+	var r io.Reader
+	if body != nil {
+		r = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimSuffix(c.Addr, "/")+"/v1/"+path, r)
+	if err != nil {
+		return err
+	}
+	if c.Token != "" {
+		req.Header.Set("X-Vault-Token", c.Token)
+	}
+	if c.Namespace != "" {
+		req.Header.Set("X-Vault-Namespace", c.Namespace)
+	}
+
+	hc := c.HTTPClient
+	if hc == nil {
+		hc = &http.Client{Timeout: 10 * time.Second}
+	}
+	resp, err := hc.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return ErrNotFound
+	case resp.StatusCode == http.StatusForbidden:
+		return ErrPermission
+	case resp.StatusCode >= 300:
+		var e struct {
+			Errors []string `json:"errors"`
+		}
+		json.NewDecoder(resp.Body).Decode(&e)
+		return fmt.Errorf("vault returned %s: %s", resp.Status, strings.Join(e.Errors, "; "))
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// Lookuper is an envy.Lookuper reading from Vault. Keys of the form
+// path#field, as given to envy.SetSecretPath, read that field of that secret.
+// Other keys, like MYAPP_DB_PASSWORD, are read as fields of the default secret
+// if one was given. Each secret is read once and cached.
+type Lookuper struct {
+	client *Client
+	path   string
+
+	mu      sync.Mutex
+	secrets map[string]map[string]interface{}
+	err     error
+}
+
+// NewLookuper returns a Lookuper reading from the client. The default secret
+// may be empty, leaving only path#field keys.
+func NewLookuper(client *Client, path string) *Lookuper {
+	return &Lookuper{client: client, path: path, secrets: map[string]map[string]interface{}{}}
+}
+
+func (l *Lookuper) Lookup(key string) (string, bool) {
+	path, field := l.path, key
+	if i := strings.LastIndexByte(key, '#'); i >= 0 {
+		path, field = key[:i], key[i+1:]
+	}
+	if path == "" {
+		return "", false
+	}
+
+	secret, ok := l.read(path)
+	if !ok {
+		return "", false
+	}
+	val, ok := secret[field]
+	if !ok || val == nil {
+		return "", false
+	}
+	if s, ok := val.(string); ok {
+		return s, true
+	}
+	// Numbers, bools and nested values come back as JSON.
+	data, err := json.Marshal(val)
+	if err != nil {
+		return "", false
+	}
+	return string(data), true
+}
+
+// read returns the cached secret, reading it from Vault the first time.
+func (l *Lookuper) read(path string) (map[string]interface{}, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if secret, ok := l.secrets[path]; ok {
+		return secret, secret != nil
+	}
+	secret, err := l.client.Read(context.Background(), path)
+	if err != nil && !errors.Is(err, ErrNotFound) && l.err == nil {
+		l.err = err
+	}
+	l.secrets[path] = secret
+	return secret, secret != nil
+}
+
+// Err returns the first error reading from Vault other than a missing secret.
+// Since envy treats a failed lookup as unset, check it after envy.Parse() to
+// avoid silently running with defaults.
+func (l *Lookuper) Err() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.err
+}
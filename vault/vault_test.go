@@ -0,0 +1,100 @@
+package vault_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/fernferret/envy"
+	"github.com/fernferret/envy/vault"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+)
+
+func newServer(t *testing.T, reads *int) *httptest.Server {
+	secrets := map[string]map[string]interface{}{
+		"/v1/secret/data/myapp": {"MYAPP_URL": "http://from-vault", "MYAPP_WORKERS": 3},
+		"/v1/secret/data/db":    {"password": "hunter2"},
+	}
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v1/auth/approle/login" {
+			var body map[string]string
+			json.NewDecoder(r.Body).Decode(&body)
+			if body["role_id"] != "role" || body["secret_id"] != "secret" {
+				w.WriteHeader(http.StatusBadRequest)
+				json.NewEncoder(w).Encode(map[string][]string{"errors": {"invalid role or secret ID"}})
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"auth": map[string]string{"client_token": "token"}})
+			return
+		}
+		if r.Header.Get("X-Vault-Token") != "token" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		*reads++
+		secret, ok := secrets[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"data": map[string]interface{}{"data": secret}})
+	}))
+}
+
+func TestLookuper(t *testing.T) {
+	reads := 0
+	srv := newServer(t, &reads)
+	defer srv.Close()
+
+	client, err := vault.LoginAppRole(context.Background(), srv.URL, "role", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	secrets := vault.NewLookuper(client, "secret/data/myapp")
+
+	os.Clearenv()
+	os.Setenv("MYAPP_NAME", "from-env")
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	url := fs.String("url", "", "set the url")
+	workers := fs.Int("workers", 1, "number of workers")
+	name := fs.String("name", "", "the name")
+	password := fs.String("db-password", "", "database password")
+	missing := fs.String("missing", "default", "missing")
+
+	e := envy.New(envy.WithPrefix("MYAPP"), envy.WithFlagSet(fs), envy.WithLookuper(envy.Chain{envy.EnvLookuper{}, secrets}))
+	e.SetSecretPath("db-password", "secret/data/db#password")
+	e.SetSecretPath("missing", "secret/data/nope#missing")
+	assert.NoError(t, e.ParseE())
+	assert.NoError(t, secrets.Err())
+
+	assert.Equal(t, "http://from-vault", *url)
+	assert.Equal(t, 3, *workers)
+	assert.Equal(t, "from-env", *name)
+	assert.Equal(t, "hunter2", *password)
+	assert.Equal(t, "default", *missing)
+	assert.Equal(t, 3, reads)
+
+	x := envy.ExplainFlagSet("db-password", fs)
+	assert.Equal(t, envy.Layer{Source: "secret secret/data/db#password", Value: "<redacted>", Set: true}, x.Layers[x.Winner])
+}
+
+func TestLookuperErrors(t *testing.T) {
+	reads := 0
+	srv := newServer(t, &reads)
+	defer srv.Close()
+
+	_, err := vault.LoginAppRole(context.Background(), srv.URL, "role", "wrong")
+	assert.EqualError(t, err, "approle login: vault returned 400 Bad Request: invalid role or secret ID")
+
+	secrets := vault.NewLookuper(vault.NewClient(srv.URL, "expired"), "secret/data/myapp")
+	_, ok := secrets.Lookup("MYAPP_URL")
+	assert.False(t, ok)
+	assert.ErrorIs(t, secrets.Err(), vault.ErrPermission)
+
+	_, err = vault.NewClient(srv.URL, "token").Read(context.Background(), "secret/data/nope")
+	assert.ErrorIs(t, err, vault.ErrNotFound)
+}
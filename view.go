@@ -0,0 +1,72 @@
+package envy
+
+import "github.com/spf13/pflag"
+
+// View is a read-only handle on a FlagSet's values that can be handed to
+// plugins or templates without giving them the ability to change anything.
+// Secrets are redacted unless the view was created with ShowSecrets.
+type View struct {
+	fs      *pflag.FlagSet
+	secrets bool
+}
+
+// ViewOption configures a View created with NewView.
+type ViewOption func(*View)
+
+// ShowSecrets makes the view show the values of secrets. Only the owner of the
+// FlagSet can choose this when creating the view, whoever it's handed to
+// can't.
+func ShowSecrets() ViewOption {
+	return func(v *View) {
+		v.secrets = true
+	}
+}
+
+// NewView returns a View of the default pflag.CommandLine.
+func NewView(opts ...ViewOption) View {
+	return NewViewFlagSet(pflag.CommandLine, opts...)
+}
+
+// NewViewFlagSet returns a View of the given FlagSet.
+func NewViewFlagSet(fs *pflag.FlagSet, opts ...ViewOption) View {
+	v := View{fs: fs}
+	for _, opt := range opts {
+		opt(&v)
+	}
+	return v
+}
+
+// Get returns the current value of the named flag.
+func (v View) Get(name string) (string, bool) {
+	f := v.fs.Lookup(name)
+	if f == nil {
+		return "", false
+	}
+	return v.value(f), true
+}
+
+// Names returns the names of every flag, sorted.
+func (v View) Names() []string {
+	var names []string
+	visitAll(v.fs, func(f *pflag.Flag) {
+		names = append(names, f.Name)
+	})
+	return names
+}
+
+// Map returns the value of every flag keyed by name, which is convenient for
+// use in templates.
+func (v View) Map() map[string]string {
+	values := map[string]string{}
+	visitAll(v.fs, func(f *pflag.Flag) {
+		values[f.Name] = v.value(f)
+	})
+	return values
+}
+
+func (v View) value(f *pflag.Flag) string {
+	if v.secrets {
+		return f.Value.String()
+	}
+	return displayValue(f)
+}
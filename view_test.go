@@ -0,0 +1,43 @@
+package envy_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/fernferret/envy"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestView(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("FOO_TOKEN", "hunter2")
+	pflag.CommandLine = pflag.NewFlagSet("test", pflag.PanicOnError)
+
+	pflag.String("url", "http://localhost", "set the url")
+	pflag.String("token", "", "api token")
+	envy.Secret("token")
+	envy.Parse("FOO")
+
+	view := envy.NewView()
+
+	val, ok := view.Get("url")
+	assert.True(t, ok)
+	assert.Equal(t, "http://localhost", val)
+
+	val, _ = view.Get("token")
+	assert.Equal(t, "<redacted>", val)
+
+	_, ok = view.Get("missing")
+	assert.False(t, ok)
+
+	assert.Equal(t, []string{"token", "url"}, view.Names())
+	assert.Equal(t, map[string]string{"token": "<redacted>", "url": "http://localhost"}, view.Map())
+
+	val, _ = envy.NewView(envy.ShowSecrets()).Get("token")
+	assert.Equal(t, "hunter2", val)
+
+	// The original view is unaffected
+	val, _ = view.Get("token")
+	assert.Equal(t, "<redacted>", val)
+}
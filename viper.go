@@ -0,0 +1,90 @@
+package envy
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ViperMigration describes how one viper key, bound to a flag of the same name,
+// is read from the environment by viper's AutomaticEnv and by envy.
+type ViperMigration struct {
+	// The viper key, which is also the flag name.
+	Key string
+
+	// The environment variable viper reads for the key.
+	ViperEnv string
+
+	// The environment variable envy reads for the flag by default.
+	EnvyEnv string
+
+	// The envy call needed to keep reading ViperEnv, empty if the names
+	// already match or ViperEnv can't be expressed in envy.
+	Call string
+
+	// Any semantic differences worth reviewing before switching.
+	Notes []string
+}
+
+// ViperReport is the result of MigrateViper, one entry per key.
+type ViperReport []ViperMigration
+
+// MigrateViper works out the envy equivalent of a viper configuration using
+// SetEnvPrefix(pfx), an optional SetEnvKeyReplacer(replacer) and AutomaticEnv
+// for the given keys. Keys are assumed to be bound to flags of the same name
+// with BindPFlags. Note that viper reads the environment every time a key is
+// fetched while envy only reads it once in Parse.
+func MigrateViper(pfx string, replacer *strings.Replacer, keys ...string) ViperReport {
+	report := make(ViperReport, 0, len(keys))
+	for _, key := range keys {
+		m := ViperMigration{
+			Key:     key,
+			EnvyEnv: std.nameFunc(normalizePrefix(pfx), key),
+		}
+
+		// This mirrors viper's mergeWithEnvPrefix followed by getEnv, note
+		// the replacer runs over the prefix as well.
+		m.ViperEnv = strings.ToUpper(key)
+		if pfx != "" {
+			m.ViperEnv = strings.ToUpper(pfx + "_" + key)
+		}
+		if replacer != nil {
+			m.ViperEnv = replacer.Replace(m.ViperEnv)
+		}
+
+		if strings.HasSuffix(pfx, "_") {
+			m.Notes = append(m.Notes, fmt.Sprintf("viper doesn't trim the trailing _ from prefix %q, envy does", pfx))
+		}
+		if strings.Contains(key, ".") && replacer == nil {
+			m.Notes = append(m.Notes, fmt.Sprintf("nested key %q is read by viper as %s, which most shells can't set", key, m.ViperEnv))
+		}
+
+		if m.ViperEnv != m.EnvyEnv {
+			// SetEnvName uppercases and replaces dashes, so only names that
+			// survive that untouched can be carried over.
+			if m.ViperEnv == strings.ToUpper(strings.ReplaceAll(m.ViperEnv, "-", "_")) {
+				m.Call = fmt.Sprintf("envy.SetEnvName(%q, %q)", key, m.ViperEnv)
+			} else {
+				m.Notes = append(m.Notes, fmt.Sprintf("%s can't be used by envy, rename it to %s", m.ViperEnv, m.EnvyEnv))
+			}
+		}
+
+		report = append(report, m)
+	}
+	return report
+}
+
+// Print writes the report as Go code followed by any notes, suitable for
+// pasting into a migration doc or pull request.
+func (r ViperReport) Print(w io.Writer) {
+	for _, m := range r {
+		if m.Call != "" {
+			fmt.Fprintln(w, m.Call)
+		}
+	}
+	for _, m := range r {
+		for _, note := range m.Notes {
+			fmt.Fprintf(w, "// %s: %s\n", m.Key, note)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package envy_test
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/fernferret/envy"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMigrateViper(t *testing.T) {
+	tests := []struct {
+		name     string
+		pfx      string
+		replacer *strings.Replacer
+		key      string
+		exp      envy.ViperMigration
+	}{
+		{
+			name: "test matching names",
+			pfx:  "app",
+			key:  "url",
+			exp: envy.ViperMigration{
+				Key:      "url",
+				ViperEnv: "APP_URL",
+				EnvyEnv:  "APP_URL",
+			},
+		},
+		{
+			name: "test dashed key without replacer",
+			pfx:  "app",
+			key:  "kube-config",
+			exp: envy.ViperMigration{
+				Key:      "kube-config",
+				ViperEnv: "APP_KUBE-CONFIG",
+				EnvyEnv:  "APP_KUBE_CONFIG",
+				Notes:    []string{"APP_KUBE-CONFIG can't be used by envy, rename it to APP_KUBE_CONFIG"},
+			},
+		},
+		{
+			name:     "test dashed key with replacer",
+			pfx:      "app",
+			replacer: strings.NewReplacer("-", "_"),
+			key:      "kube-config",
+			exp: envy.ViperMigration{
+				Key:      "kube-config",
+				ViperEnv: "APP_KUBE_CONFIG",
+				EnvyEnv:  "APP_KUBE_CONFIG",
+			},
+		},
+		{
+			name:     "test nested key with replacer",
+			pfx:      "app",
+			replacer: strings.NewReplacer(".", "__"),
+			key:      "server.port",
+			exp: envy.ViperMigration{
+				Key:      "server.port",
+				ViperEnv: "APP_SERVER__PORT",
+				EnvyEnv:  "APP_SERVER.PORT",
+				Call:     `envy.SetEnvName("server.port", "APP_SERVER__PORT")`,
+			},
+		},
+		{
+			name: "test trailing underscore prefix",
+			pfx:  "APP_",
+			key:  "url",
+			exp: envy.ViperMigration{
+				Key:      "url",
+				ViperEnv: "APP__URL",
+				EnvyEnv:  "APP_URL",
+				Call:     `envy.SetEnvName("url", "APP__URL")`,
+				Notes:    []string{`viper doesn't trim the trailing _ from prefix "APP_", envy does`},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := envy.MigrateViper(tt.pfx, tt.replacer, tt.key)
+			assert.Equal(t, envy.ViperReport{tt.exp}, report)
+		})
+	}
+}
+
+func ExampleMigrateViper() {
+	// viper.SetEnvPrefix("MYAPP")
+	// viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_", "-", "_"))
+	replacer := strings.NewReplacer(".", "_", "-", "_")
+
+	report := envy.MigrateViper("MYAPP", replacer, "url", "kube-config", "server.port")
+	report.Print(os.Stdout)
+	// Output: envy.SetEnvName("server.port", "MYAPP_SERVER_PORT")
+}
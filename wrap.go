@@ -0,0 +1,50 @@
+package envy
+
+import (
+	"fmt"
+
+	"github.com/spf13/pflag"
+)
+
+// WrapFlagError adds where a flag in the default pflag.CommandLine got its
+// value to an error, see WrapFlagErrorOnFlagSet.
+func WrapFlagError(name string, err error) error {
+	return WrapFlagErrorOnFlagSet(name, err, pflag.CommandLine)
+}
+
+// WrapFlagErrorOnFlagSet adds where a flag got its value to an application
+// error about that setting, so the message points at the knob to change:
+//
+//	--interval from MYAPP_INTERVAL=10m: interval must be under 5m
+//
+// The value of secrets is redacted. A nil error stays nil, and errors about
+// flags that don't exist are returned unchanged. It must be called after
+// pflag.Parse().
+func WrapFlagErrorOnFlagSet(name string, err error, fs *pflag.FlagSet) error {
+	if err == nil {
+		return nil
+	}
+	f := fs.Lookup(name)
+	if f == nil {
+		return err
+	}
+	return fmt.Errorf("%s: %w", provenance(f), err)
+}
+
+// provenance describes the flag's value and how it was provided.
+func provenance(f *pflag.Flag) string {
+	val := displayValue(f)
+	if f.Changed {
+		return fmt.Sprintf("--%s=%s", f.Name, val)
+	}
+	if src, ok := f.Annotations[AnnotationSource]; ok {
+		return fmt.Sprintf("--%s from %s=%s", f.Name, src[0], val)
+	}
+	if src, ok := f.Annotations[AnnotationFile]; ok {
+		return fmt.Sprintf("--%s=%s from %s", f.Name, val, src[0])
+	}
+	if f.Value.String() == f.DefValue {
+		return fmt.Sprintf("--%s=%s (default)", f.Name, val)
+	}
+	return fmt.Sprintf("--%s=%s", f.Name, val)
+}
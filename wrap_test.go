@@ -0,0 +1,46 @@
+package envy_test
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/fernferret/envy"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWrapFlagError(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("MYAPP_INTERVAL", "10m")
+	os.Setenv("MYAPP_TOKEN", "hunter2")
+	pflag.CommandLine = pflag.NewFlagSet("test", pflag.PanicOnError)
+
+	pflag.Duration("interval", 0, "interval")
+	pflag.String("token", "", "token")
+	pflag.Int("workers", 4, "workers")
+	pflag.String("url", "", "url")
+	envy.Secret("token")
+	envy.Parse("MYAPP")
+	pflag.CommandLine.Parse([]string{"--url=http://flag"})
+
+	errTooLong := errors.New("too long")
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"interval", "--interval from MYAPP_INTERVAL=10m0s: too long"},
+		{"token", "--token from MYAPP_TOKEN=<redacted>: too long"},
+		{"workers", "--workers=4 (default): too long"},
+		{"url", "--url=http://flag: too long"},
+		{"missing", "too long"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := envy.WrapFlagError(tt.name, errTooLong)
+			assert.EqualError(t, err, tt.want)
+			assert.ErrorIs(t, err, errTooLong)
+		})
+	}
+	assert.NoError(t, envy.WrapFlagError("interval", nil))
+}
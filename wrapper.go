@@ -0,0 +1,124 @@
+package envy
+
+import "github.com/spf13/pflag"
+
+// wrapper is implemented by the values envy puts in front of a flag's own
+// value. unwrap returns the value it's in front of, and guard runs a change
+// made to that value other than through Set, like a slice's Replace, given
+// the values it receives.
+type wrapper interface {
+	pflag.Value
+	unwrap() pflag.Value
+	guard(vals []string, change func() error) error
+}
+
+// boolFlag is implemented by bool values, which can be given without one.
+type boolFlag interface {
+	IsBoolFlag() bool
+}
+
+// wrap returns the wrapper with the optional interfaces of the value it's in
+// front of, so the flag can still be used as a pflag.SliceValue or a bool.
+func wrap(w wrapper) pflag.Value {
+	_, slice := w.unwrap().(pflag.SliceValue)
+	_, isBool := w.unwrap().(boolFlag)
+	switch {
+	case slice && isBool:
+		return &boolSliceForwarder{sliceForwarder{forwarder{w}}}
+	case slice:
+		return &sliceForwarder{forwarder{w}}
+	case isBool:
+		return &boolForwarder{forwarder{w}}
+	}
+	return w
+}
+
+// forwarder passes everything on to the wrapper it holds, the types embedding
+// it add the optional interfaces.
+type forwarder struct {
+	w wrapper
+}
+
+func (v forwarder) Set(val string) error { return v.w.Set(val) }
+func (v forwarder) String() string       { return v.w.String() }
+func (v forwarder) Type() string         { return v.w.Type() }
+func (v forwarder) unwrap() pflag.Value  { return v.w }
+
+func (v forwarder) guard(_ []string, change func() error) error {
+	return change()
+}
+
+type sliceForwarder struct {
+	forwarder
+}
+
+func (v *sliceForwarder) Append(val string) error {
+	return guarded(v.w, []string{val}, func() error {
+		return innermost(v.w).(pflag.SliceValue).Append(val)
+	})
+}
+
+func (v *sliceForwarder) Replace(vals []string) error {
+	return guarded(v.w, vals, func() error {
+		return innermost(v.w).(pflag.SliceValue).Replace(vals)
+	})
+}
+
+func (v *sliceForwarder) GetSlice() []string {
+	return innermost(v.w).(pflag.SliceValue).GetSlice()
+}
+
+type boolForwarder struct {
+	forwarder
+}
+
+func (v *boolForwarder) IsBoolFlag() bool {
+	return innermost(v.w).(boolFlag).IsBoolFlag()
+}
+
+type boolSliceForwarder struct {
+	sliceForwarder
+}
+
+func (v *boolSliceForwarder) IsBoolFlag() bool {
+	return innermost(v.w).(boolFlag).IsBoolFlag()
+}
+
+// guarded runs a change to the innermost value through the guard of every
+// wrapper in front of it.
+func guarded(v pflag.Value, vals []string, change func() error) error {
+	w, ok := v.(wrapper)
+	if !ok {
+		return change()
+	}
+	return w.guard(vals, func() error {
+		return guarded(w.unwrap(), vals, change)
+	})
+}
+
+// innermost returns the value behind every wrapper.
+func innermost(v pflag.Value) pflag.Value {
+	for {
+		w, ok := v.(wrapper)
+		if !ok {
+			return v
+		}
+		v = w.unwrap()
+	}
+}
+
+// wrapped returns the wrapper of type T in front of the flag's value, if any.
+func wrapped[T wrapper](f *pflag.Flag) (T, bool) {
+	v := f.Value
+	for {
+		if t, ok := v.(T); ok {
+			return t, true
+		}
+		w, ok := v.(wrapper)
+		if !ok {
+			var zero T
+			return zero, false
+		}
+		v = w.unwrap()
+	}
+}
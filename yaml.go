@@ -0,0 +1,25 @@
+package envy
+
+// SetYAMLFiles replaces the YAML config files of the default Envy, see
+// WithYAMLFile. Passing none removes them. Files set with SetJSONFiles are
+// kept, and the files set by the latest call win. If a file can't be read
+// nothing is changed. It must be called before the call to envy.Parse().
+func SetYAMLFiles(paths ...string) error {
+	return setFiles(formatYAML, paths)
+}
+
+// WithYAMLFile reads flag values from a YAML file whose keys are flag names,
+// so flags get their defaults from the file unless an environment variable or
+// the command line overrides them. Nested sections are joined with dots, or
+// dashes if no flag has the dotted name, and lists are joined with commas:
+//
+//	count-fancy: 3
+//	server:
+//	  port: 8080        # --server.port or --server-port
+//	tags: [a, b]        # --tags=a,b
+//
+// When several files are given the later ones win. A file that can't be read
+// is reported by Parse.
+func WithYAMLFile(path string) Option {
+	return withFile(path, formatYAML)
+}
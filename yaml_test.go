@@ -0,0 +1,135 @@
+package envy_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/fernferret/envy"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+)
+
+func writeFile(t *testing.T, name, data string) string {
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestWithYAMLFile(t *testing.T) {
+	os.Clearenv()
+	os.Setenv("MYAPP_NAME", "from-env")
+	path := writeFile(t, "config.yaml", `
+count-fancy: 3
+name: from-file
+url: http://file
+server:
+  port: 8080
+  host: example.com
+tags: [a, b]
+`)
+
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	count := fs.Int("count-fancy", 1, "count")
+	name := fs.String("name", "", "name")
+	url := fs.String("url", "", "url")
+	port := fs.Int("server.port", 80, "port")
+	host := fs.String("server-host", "", "host")
+	tags := fs.StringSlice("tags", nil, "tags")
+	other := fs.String("other", "default", "other")
+
+	e := envy.New(envy.WithPrefix("MYAPP"), envy.WithFlagSet(fs), envy.WithYAMLFile(path))
+	assert.NoError(t, e.ParseE())
+	assert.NoError(t, fs.Parse([]string{"--url=http://flag"}))
+
+	assert.Equal(t, 3, *count)
+	assert.Equal(t, "from-env", *name)
+	assert.Equal(t, "http://flag", *url)
+	assert.Equal(t, 8080, *port)
+	assert.Equal(t, "example.com", *host)
+	assert.Equal(t, []string{"a", "b"}, *tags)
+	assert.Equal(t, "default", *other)
+
+	sources := envy.SourcesFlagSet(fs)
+	assert.Equal(t, envy.FlagSource{Flag: "count-fancy", Origin: envy.FromFile, EnvName: "MYAPP_COUNT_FANCY", Value: "3", File: path}, sources[0])
+	assert.Equal(t, "file", envy.FromFile.String())
+
+	x := envy.ExplainFlagSet("count-fancy", fs)
+	assert.Equal(t, []envy.Layer{
+		{Source: "flag", Value: "3"},
+		{Source: "env MYAPP_COUNT_FANCY"},
+		{Source: "file " + path, Value: "3", Set: true},
+		{Source: "default", Value: "1", Set: true},
+	}, x.Layers)
+	assert.Equal(t, 2, x.Winner)
+}
+
+func TestWithYAMLFileOrder(t *testing.T) {
+	os.Clearenv()
+	base := writeFile(t, "base.yaml", "name: base\nurl: http://base\n")
+	local := writeFile(t, "local.yaml", "name: local\n")
+
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	name := fs.String("name", "", "name")
+	url := fs.String("url", "", "url")
+
+	e := envy.New(envy.WithFlagSet(fs), envy.WithYAMLFile(base), envy.WithYAMLFile(local))
+	assert.NoError(t, e.ParseE())
+	assert.Equal(t, "local", *name)
+	assert.Equal(t, "http://base", *url)
+}
+
+func TestWithYAMLFileErrors(t *testing.T) {
+	os.Clearenv()
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	fs.Int("count", 1, "count")
+
+	missing := filepath.Join(t.TempDir(), "missing.yaml")
+	e := envy.New(envy.WithFlagSet(fs), envy.WithYAMLFile(missing))
+	err := e.ParseE()
+	assert.ErrorIs(t, err, os.ErrNotExist)
+
+	path := writeFile(t, "bad.yaml", "count: lots\n")
+	e = envy.New(envy.WithFlagSet(fs), envy.WithYAMLFile(path))
+	err = e.ParseE()
+	var setErr *envy.SetError
+	if !assert.ErrorAs(t, err, &setErr) {
+		return
+	}
+	assert.Equal(t, "count", setErr.Flag)
+	assert.Equal(t, path, setErr.EnvName)
+}
+
+func TestWithYAMLFileEveryError(t *testing.T) {
+	os.Clearenv()
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	fs.String("a-b-c", "", "a setting")
+
+	missing := filepath.Join(t.TempDir(), "missing.yaml")
+	conflict := writeFile(t, "conflict.yaml", "a:\n  b-c: one\na-b:\n  c: two\n")
+	err := envy.New(envy.WithFlagSet(fs), envy.WithYAMLFile(missing), envy.WithYAMLFile(conflict)).ParseE()
+
+	var errs envy.ParseErrors
+	if !assert.ErrorAs(t, err, &errs) || !assert.Len(t, errs, 2) {
+		return
+	}
+	assert.ErrorIs(t, errs[0], os.ErrNotExist)
+	assert.ErrorIs(t, errs[1], envy.ErrConfigKeyConflict)
+	assert.EqualError(t, errs[1], conflict+": "+envy.ErrConfigKeyConflict.Error()+": a-b.c, a.b-c")
+	assert.Equal(t, "", fs.Lookup("a-b-c").Value.String())
+}
+
+func TestSetYAMLFiles(t *testing.T) {
+	os.Clearenv()
+	pflag.CommandLine = pflag.NewFlagSet("test", pflag.PanicOnError)
+	defer envy.SetYAMLFiles()
+
+	path := writeFile(t, "config.yaml", "name: from-file\n")
+	name := pflag.String("name", "", "name")
+	assert.NoError(t, envy.SetYAMLFiles(path))
+	assert.Error(t, envy.SetYAMLFiles(path, filepath.Join(t.TempDir(), "missing.yaml")))
+	envy.Parse("FOO")
+	assert.Equal(t, "from-file", *name)
+}